@@ -2,11 +2,14 @@ package blades
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/go-kratos/blades/skills"
 	"github.com/go-kratos/blades/tools"
@@ -120,6 +123,46 @@ func WithContext(enabled bool) AgentOption {
 	}
 }
 
+// WithToolConcurrency limits how many tool calls of a single model turn run at
+// the same time. By default (n <= 0), all calls run concurrently.
+func WithToolConcurrency(n int) AgentOption {
+	return func(a *agent) {
+		a.toolConcurrency = n
+	}
+}
+
+// WithToolTimeout bounds each tool call with the given timeout. A call that
+// times out fails with ErrToolTimeout, which is handled like any other tool
+// error. Tools implementing tools.TimeoutTool override this value.
+func WithToolTimeout(timeout time.Duration) AgentOption {
+	return func(a *agent) {
+		a.toolTimeout = timeout
+	}
+}
+
+// WithToolErrorPolicy makes the Agent report failed tool calls back to the model
+// as the tool response instead of aborting the run, so the model can self-correct.
+// The classifier decides per error whether it is retryable, fatal or an interrupt;
+// if it is nil, DefaultToolErrorClassifier is used. ErrInterrupted always aborts.
+func WithToolErrorPolicy(classifier ToolErrorClassifier) AgentOption {
+	return func(a *agent) {
+		if classifier == nil {
+			classifier = DefaultToolErrorClassifier
+		}
+		a.toolErrorClassifier = classifier
+	}
+}
+
+// WithToolApproval pauses the Agent before running tool calls that need human
+// approval. The pending calls are recorded in the session and the run returns
+// ErrInterrupted; after deciding them with ResolveToolCall, run the agent again
+// with WithResume(true) to continue from the same tool calls.
+func WithToolApproval(approval ToolApprovalFunc) AgentOption {
+	return func(a *agent) {
+		a.toolApproval = approval
+	}
+}
+
 // agent is a struct that represents an AI agent.
 type agent struct {
 	name                string
@@ -135,8 +178,14 @@ type agent struct {
 	tools               []tools.Tool
 	skills              []skills.Skill
 	skillToolset        *skills.Toolset
-	toolsResolver       tools.Resolver // Optional resolver for dynamic tools (e.g., MCP servers)
-	useContext          bool           // Whether to load session history into each model call
+	toolsResolver       tools.Resolver      // Optional resolver for dynamic tools (e.g., MCP servers)
+	useContext          bool                // Whether to load session history into each model call
+	toolErrorClassifier ToolErrorClassifier // Optional policy for failed tool calls; nil aborts the run
+	toolConcurrency     int                 // Max concurrent tool calls per turn; <= 0 means unlimited
+	toolTimeout         time.Duration       // Per-call tool timeout; 0 means none
+	toolApproval        ToolApprovalFunc    // Optional human approval of tool calls
+	generationConfig    *GenerationConfig   // Optional generation parameters of model requests
+	toolChoice          *ToolChoice         // Optional tool-calling policy of model requests
 }
 
 // NewAgent creates a new Agent with the given name and options.
@@ -259,19 +308,28 @@ func (a *agent) Run(ctx context.Context, invocation *Invocation) Generator[*Mess
 				return
 			}
 		}
+		var parent string
+		if caller, ok := FromAgentContext(ctx); ok {
+			parent = caller.Name()
+		}
 		ctx = NewAgentContext(ctx, a)
 		handler := Handler(HandleFunc(func(ctx context.Context, invocation *Invocation) Generator[*Message, error] {
 			req := &ModelRequest{
-				Tools:        invocation.Tools,
-				Instruction:  invocation.Instruction,
-				InputSchema:  a.inputSchema,
-				OutputSchema: a.outputSchema,
+				Tools:            invocation.Tools,
+				Instruction:      invocation.Instruction,
+				InputSchema:      a.inputSchema,
+				OutputSchema:     a.outputSchema,
+				GenerationConfig: a.requestGenerationConfig(invocation),
 			}
 			return a.handle(ctx, session, invocation, req)
 		}))
 		if len(a.middlewares) > 0 {
 			handler = ChainMiddlewares(a.middlewares...)(handler)
 		}
+		if ms := globalMiddlewares(ctx); len(ms) > 0 {
+			handler = ChainMiddlewares(ms...)(handler)
+		}
+		handler = agentEvents(parent)(stateDeltas(session)(artifactRefs()(handler)))
 		stream := handler.Handle(ctx, invocation)
 		for m, err := range stream {
 			if !yield(m, err) {
@@ -292,52 +350,238 @@ func (a *agent) saveOutputState(ctx context.Context, invocation *Invocation, mes
 
 func (a *agent) handleTools(ctx context.Context, invocation *Invocation, part ToolPart) (ToolPart, error) {
 	// Search through all available tools (static + resolved)
-	for _, tool := range invocation.Tools {
-		if tool.Name() == part.Name {
-			response, err := tool.Handle(ctx, part.Request)
-			if err != nil {
-				return part, err
+	tool, ok := findTool(invocation.Tools, part.Name)
+	if !ok {
+		return part, fmt.Errorf("agent: %w: %s", ErrToolNotFound, part.Name)
+	}
+	response, err := a.callTool(ctx, tool, part)
+	if err != nil {
+		return part, err
+	}
+	part.Response = response
+	return part, nil
+}
+
+// findTool returns the tool with the given name.
+func findTool(tools []tools.Tool, name string) (tools.Tool, bool) {
+	for _, tool := range tools {
+		if tool.Name() == name {
+			return tool, true
+		}
+	}
+	return nil, false
+}
+
+// callTool invokes the tool, bounded by its per-call timeout. The call is
+// abandoned once the timeout elapses, even if the tool ignores its context.
+func (a *agent) callTool(ctx context.Context, tool tools.Tool, part ToolPart) (string, error) {
+	timeout := a.toolTimeout
+	if t, ok := tool.(tools.TimeoutTool); ok && t.Timeout() > 0 {
+		timeout = t.Timeout()
+	}
+	if timeout <= 0 {
+		return handleTool(ctx, tool, part)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type result struct {
+		response string
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		response, err := handleTool(callCtx, tool, part)
+		done <- result{response: response, err: err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("agent: tool %s: %w after %s", part.Name, ErrToolTimeout, timeout)
+		}
+		return r.response, r.err
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("agent: tool %s: %w after %s", part.Name, ErrToolTimeout, timeout)
+	}
+}
+
+// handleTool calls the tool, turning a panic into an error wrapping ErrPanic
+// when the Runner recovers panics. Streaming tools are streamed when ctx
+// carries a tool update reporter.
+func handleTool(ctx context.Context, tool tools.Tool, part ToolPart) (response string, err error) {
+	if recoverPanics(ctx) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("agent: tool %s: %w", tool.Name(), &PanicError{Value: v, Stack: debug.Stack()})
+			}
+		}()
+	}
+	streaming, ok := tool.(tools.StreamingTool)
+	report, reporting := ctx.Value(ctxToolUpdatesKey{}).(func(context.Context, ToolPart, *tools.Update))
+	if !ok || !reporting {
+		return tool.Handle(ctx, part.Request)
+	}
+	for update, err := range streaming.HandleStream(ctx, part.Request) {
+		if err != nil {
+			return "", err
+		}
+		if update == nil {
+			continue
+		}
+		response = update.Output
+		report(ctx, part, update)
+	}
+	return response, nil
+}
+
+type ctxToolUpdatesKey struct{}
+
+// executeToolsStream runs executeTools, yielding the updates of streaming tools
+// as in-progress tool messages while the calls run. It reports false if the
+// consumer stopped iterating.
+func (a *agent) executeToolsStream(ctx context.Context, invocation *Invocation, message *Message, yield func(*Message, error) bool) (*Message, bool, error) {
+	if !invocation.Stream {
+		toolMessage, err := a.executeTools(ctx, invocation, message)
+		return toolMessage, true, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates := make(chan *Message)
+	ctx = context.WithValue(ctx, ctxToolUpdatesKey{}, func(callCtx context.Context, part ToolPart, update *tools.Update) {
+		// A call that timed out or was canceled reports nothing more.
+		if callCtx.Err() != nil {
+			return
+		}
+		part.Response = update.Output
+		metadata := map[string]any{"progress": update.Progress, "message": update.Message}
+		EmitEvent(ctx, &Event{Type: EventToolProgress, Tool: &part, Metadata: metadata})
+		progress := &Message{
+			ID:           NewMessageID(),
+			Role:         RoleTool,
+			Parts:        []Part{part},
+			Author:       a.name,
+			InvocationID: invocation.ID,
+			Status:       StatusInProgress,
+			Metadata:     metadata,
+		}
+		select {
+		case updates <- progress:
+		case <-ctx.Done():
+		case <-callCtx.Done():
+		}
+	})
+	var (
+		toolMessage *Message
+		err         error
+		done        = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		defer RecoverPanic(ctx, &err)
+		toolMessage, err = a.executeTools(ctx, invocation, message)
+	}()
+	for {
+		select {
+		case progress := <-updates:
+			if !yield(progress, nil) {
+				cancel()
+				<-done
+				return nil, false, nil
 			}
-			part.Response = response
-			return part, nil
+		case <-done:
+			return toolMessage, true, err
 		}
 	}
-	return part, fmt.Errorf("agent: tool %s not found", part.Name)
 }
 
-// executeTools executes the tools specified in the tool parts.
+// handleToolError applies the tool-error policy to a failed tool call. It returns
+// the completed part carrying an error payload when the failure should be reported
+// to the model, or the error that must abort the run.
+func (a *agent) handleToolError(ctx context.Context, part ToolPart, err error) (ToolPart, error) {
+	if a.toolErrorClassifier == nil || errors.Is(err, ErrInterrupted) {
+		return part, err
+	}
+	switch a.toolErrorClassifier(ctx, part, err) {
+	case ToolErrorRetryable:
+		part.Response = toolErrorResponse(err)
+		return part, nil
+	case ToolErrorInterrupt:
+		return part, fmt.Errorf("%w: %w", ErrInterrupted, err)
+	default:
+		return part, err
+	}
+}
+
+// executeTools executes the tools specified in the tool parts. Calls run
+// concurrently (up to the tool concurrency limit), except serial tools, which
+// wait for all earlier calls and run alone, preserving the model's call order.
 func (a *agent) executeTools(ctx context.Context, invocation *Invocation, message *Message) (*Message, error) {
+	var (
+		batch   []int
+		actions = maps.New(message.Actions)
+	)
+	for i, part := range message.Parts {
+		v, ok := part.(ToolPart)
+		if !ok || v.Completed {
+			continue
+		}
+		tool, found := findTool(invocation.Tools, v.Name)
+		if !found || !tools.IsSerial(tool) {
+			batch = append(batch, i)
+			continue
+		}
+		if err := a.runTools(ctx, invocation, message, actions, batch); err != nil {
+			return message, err
+		}
+		batch = nil
+		if err := a.runTools(ctx, invocation, message, actions, []int{i}); err != nil {
+			return message, err
+		}
+	}
+	return message, a.runTools(ctx, invocation, message, actions, batch)
+}
+
+// runTools concurrently executes the tool parts at the given indexes of message.
+func (a *agent) runTools(ctx context.Context, invocation *Invocation, message *Message, actions *maps.Map[string, any], indexes []int) error {
 	var (
 		m sync.Mutex
 	)
-	actions := maps.New(message.Actions)
 	eg, ctx := errgroup.WithContext(ctx)
-	for i, part := range message.Parts {
-		switch v := any(part).(type) {
-		case ToolPart:
-			eg.Go(func() error {
-				if v.Completed {
-					return nil
-				}
-				toolCtx := tools.NewContext(ctx, &toolContext{
-					id:      v.ID,
-					name:    v.Name,
-					actions: actions,
-				})
-				part, err := a.handleTools(toolCtx, invocation, v)
-				if err != nil {
+	if a.toolConcurrency > 0 {
+		eg.SetLimit(a.toolConcurrency)
+	}
+	for _, i := range indexes {
+		v := message.Parts[i].(ToolPart)
+		eg.Go(func() (err error) {
+			defer RecoverPanic(ctx, &err)
+			tc := &toolContext{
+				id:               v.ID,
+				name:             v.Name,
+				actions:          actions,
+				generationConfig: invocation.GenerationConfig,
+			}
+			toolCtx := tools.NewContext(ctx, tc)
+			start := time.Now()
+			EmitEvent(ctx, &Event{Type: EventToolStarted, Tool: &v, Time: start})
+			part, err := a.handleTools(toolCtx, invocation, v)
+			EmitEvent(ctx, &Event{Type: EventToolFinished, Tool: &part, Duration: time.Since(start), Err: err})
+			if err != nil {
+				if part, err = a.handleToolError(ctx, part, err); err != nil {
 					return err
 				}
-				part.Completed = true
-				m.Lock()
-				message.Parts[i] = part
-				message.Actions = MergeActions(message.Actions, actions.ToMap())
-				m.Unlock()
-				return nil
-			})
-		}
+			}
+			part.Completed = true
+			part.Content = append(part.Content, tc.content.ToSlice()...)
+			m.Lock()
+			message.Parts[i] = part
+			message.Actions = MergeActions(message.Actions, actions.ToMap())
+			m.Unlock()
+			return nil
+		})
 	}
-	return message, eg.Wait()
+	return eg.Wait()
 }
 
 func messageFromResponse(response *ModelResponse) (*Message, error) {
@@ -354,6 +598,32 @@ func (a *agent) handle(ctx context.Context, session Session, invocation *Invocat
 			loadHistory   = a.useContext || invocation.Resume
 			localMessages = []*Message{invocation.Message}
 		)
+		if invocation.Resume {
+			// Continue the tool calls that were paused for human approval.
+			pending, err := a.resumeToolApprovals(session)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if pending != nil {
+				toolMessage, ok, err := a.executeToolsStream(ctx, invocation, pending, yield)
+				if !ok {
+					return
+				}
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(toolMessage, nil) {
+					return
+				}
+				if err := session.Append(ctx, toolMessage); err != nil {
+					yield(nil, err)
+					return
+				}
+				req.GenerationConfig = releaseToolChoice(req.GenerationConfig)
+			}
+		}
 		for i := 0; i < a.maxIterations; i++ {
 			// Rebuild req.Messages each iteration.
 			if loadHistory {
@@ -368,6 +638,11 @@ func (a *agent) handle(ctx context.Context, session Session, invocation *Invocat
 				// Stateless mode: only include messages from this invocation.
 				req.Messages = localMessages
 			}
+			req.Messages = artifactPlaceholders(req.Messages)
+			if err := checkBudget(ctx); err != nil {
+				yield(nil, err)
+				return
+			}
 			var finalMessage *Message
 			if !invocation.Stream {
 				finalResponse, err := a.model.Generate(ctx, req)
@@ -383,6 +658,9 @@ func (a *agent) handle(ctx context.Context, session Session, invocation *Invocat
 				if finalMessage.Author == "" {
 					finalMessage.Author = a.name
 				}
+				if finalMessage.Model == "" {
+					finalMessage.Model = a.model.Name()
+				}
 				finalMessage.InvocationID = invocation.ID
 				// Skip saving tool intermediate states
 				if finalMessage.Role == RoleAssistant {
@@ -406,6 +684,9 @@ func (a *agent) handle(ctx context.Context, session Session, invocation *Invocat
 					if finalMessage.Author == "" {
 						finalMessage.Author = a.name
 					}
+					if finalMessage.Model == "" {
+						finalMessage.Model = a.model.Name()
+					}
 					finalMessage.InvocationID = invocation.ID
 					// Skip saving tool intermediate states
 					if finalMessage.Role == RoleTool && finalMessage.Status == StatusCompleted {
@@ -425,8 +706,26 @@ func (a *agent) handle(ctx context.Context, session Session, invocation *Invocat
 				yield(nil, ErrNoFinalResponse)
 				return
 			}
+			if err := recordUsage(ctx, finalMessage.Model, finalMessage.TokenUsage); err != nil {
+				yield(nil, err)
+				return
+			}
 			if finalMessage.Role == RoleTool {
-				toolMessage, err := a.executeTools(ctx, invocation, finalMessage)
+				if a.toolApproval != nil {
+					interrupted, err := a.requestToolApprovals(ctx, session, finalMessage)
+					if err != nil {
+						yield(nil, err)
+						return
+					}
+					if interrupted {
+						yield(nil, fmt.Errorf("%w: tool calls are awaiting approval", ErrInterrupted))
+						return
+					}
+				}
+				toolMessage, ok, err := a.executeToolsStream(ctx, invocation, finalMessage, yield)
+				if !ok {
+					return
+				}
 				if err != nil {
 					yield(nil, err)
 					return
@@ -446,6 +745,7 @@ func (a *agent) handle(ctx context.Context, session Session, invocation *Invocat
 				if !loadHistory {
 					localMessages = append(localMessages, toolMessage)
 				}
+				req.GenerationConfig = releaseToolChoice(req.GenerationConfig)
 				continue // continue to the next iteration
 			}
 			// Persist the final assistant message so future invocations can
@@ -0,0 +1,173 @@
+package blades
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+// approvalModel requests two tool calls and then answers with the tool responses it received.
+type approvalModel struct {
+	calls       int
+	secondInput []*Message
+}
+
+func (m *approvalModel) Name() string { return "approval" }
+
+func (m *approvalModel) Generate(_ context.Context, req *ModelRequest) (*ModelResponse, error) {
+	m.calls++
+	msg := NewAssistantMessage(StatusCompleted)
+	if m.calls == 1 {
+		msg.Role = RoleTool
+		msg.Parts = append(msg.Parts,
+			NewToolPart("call_1", "deploy", `{"env":"prod"}`),
+			NewToolPart("call_2", "lookup", `{}`),
+		)
+		return &ModelResponse{Message: msg}, nil
+	}
+	m.secondInput = append(m.secondInput[:0], req.Messages...)
+	msg.Parts = append(msg.Parts, TextPart{Text: "done"})
+	return &ModelResponse{Message: msg}, nil
+}
+
+func (m *approvalModel) NewStreaming(context.Context, *ModelRequest) Generator[*ModelResponse, error] {
+	return nil
+}
+
+func newApprovalAgent(t *testing.T, model ModelProvider, deployed *[]string) Agent {
+	t.Helper()
+	deploy := bladestools.NewTool("deploy", "deploy", bladestools.HandleFunc(func(_ context.Context, input string) (string, error) {
+		*deployed = append(*deployed, input)
+		return `{"deployed":true}`, nil
+	}))
+	lookup := bladestools.NewTool("lookup", "lookup", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return `{"found":true}`, nil
+	}))
+	a, err := NewAgent("approval-agent",
+		WithModel(model),
+		WithTools(deploy, lookup),
+		WithToolApproval(RequireToolApproval("deploy")),
+	)
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	return a
+}
+
+func lastToolResponses(t *testing.T, messages []*Message) map[string]string {
+	t.Helper()
+	responses := map[string]string{}
+	for _, part := range messages[len(messages)-1].Parts {
+		if v, ok := part.(ToolPart); ok {
+			if !v.Completed {
+				t.Fatalf("tool part %s not completed", v.ID)
+			}
+			responses[v.Name] = v.Response
+		}
+	}
+	return responses
+}
+
+func TestAgentToolApprovalEditAndResume(t *testing.T) {
+	t.Parallel()
+
+	var deployed []string
+	model := &approvalModel{}
+	runner := NewRunner(newApprovalAgent(t, model, &deployed))
+	session := NewSession()
+	ctx := context.Background()
+
+	_, err := runner.Run(ctx, UserMessage("ship it"), WithSession(session))
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("run error = %v, want ErrInterrupted", err)
+	}
+	if len(deployed) != 0 {
+		t.Fatalf("deploy ran before approval")
+	}
+	pending := PendingToolCalls(session)
+	if got, want := len(pending), 1; got != want {
+		t.Fatalf("pending calls = %d, want %d", got, want)
+	}
+	if got, want := pending[0].Name, "deploy"; got != want {
+		t.Fatalf("pending call name = %q, want %q", got, want)
+	}
+
+	// Resuming without a decision interrupts again.
+	if _, err := runner.Run(ctx, nil, WithSession(session), WithResume(true)); !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("resume without decision error = %v, want ErrInterrupted", err)
+	}
+
+	if err := ResolveToolCall(session, pending[0].ID, ToolDecision{Action: ToolEdit, Request: `{"env":"staging"}`}); err != nil {
+		t.Fatalf("resolve tool call: %v", err)
+	}
+	output, err := runner.Run(ctx, nil, WithSession(session), WithResume(true))
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if got, want := output.Text(), "done"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(deployed, ","), `{"env":"staging"}`; got != want {
+		t.Fatalf("deploy inputs = %s, want %s", got, want)
+	}
+	if got, want := model.calls, 2; got != want {
+		t.Fatalf("model calls = %d, want %d", got, want)
+	}
+	responses := lastToolResponses(t, model.secondInput)
+	if got, want := responses["deploy"], `{"deployed":true}`; got != want {
+		t.Fatalf("deploy response = %q, want %q", got, want)
+	}
+	if got, want := responses["lookup"], `{"found":true}`; got != want {
+		t.Fatalf("lookup response = %q, want %q", got, want)
+	}
+	if got := PendingToolCalls(session); len(got) != 0 {
+		t.Fatalf("pending calls after resume = %d, want 0", len(got))
+	}
+}
+
+func TestAgentToolApprovalRejectAfterStateRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var deployed []string
+	model := &approvalModel{}
+	runner := NewRunner(newApprovalAgent(t, model, &deployed))
+	session := NewSession()
+	ctx := context.Background()
+
+	if _, err := runner.Run(ctx, UserMessage("ship it"), WithSession(session)); !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("run error = %v, want ErrInterrupted", err)
+	}
+	// Simulate a persistent SessionStore, which keeps state as JSON.
+	for key, value := range session.State() {
+		b, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("marshal state %s: %v", key, err)
+		}
+		var decoded any
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("unmarshal state %s: %v", key, err)
+		}
+		session.SetState(key, decoded)
+	}
+
+	if err := ResolveToolCall(session, "missing", ToolDecision{Action: ToolApprove}); !errors.Is(err, ErrToolCallNotPending) {
+		t.Fatalf("resolve unknown call error = %v, want ErrToolCallNotPending", err)
+	}
+	if err := ResolveToolCall(session, "call_1", ToolDecision{Action: ToolReject, Reason: "freeze"}); err != nil {
+		t.Fatalf("resolve tool call: %v", err)
+	}
+	if _, err := runner.Run(ctx, nil, WithSession(session), WithResume(true)); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if len(deployed) != 0 {
+		t.Fatalf("rejected deploy was executed")
+	}
+	responses := lastToolResponses(t, model.secondInput)
+	if got, want := responses["deploy"], `{"error":"tool call rejected by user: freeze"}`; got != want {
+		t.Fatalf("deploy response = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package blades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+func newToolCallMessage(names ...string) *Message {
+	message := NewAssistantMessage(StatusCompleted)
+	message.Role = RoleTool
+	for i, name := range names {
+		message.Parts = append(message.Parts, NewToolPart(fmt.Sprintf("call_%d", i), name, `{}`))
+	}
+	return message
+}
+
+func TestAgentExecuteToolsRespectsConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	tool := bladestools.NewTool("slow", "slow", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return "ok", nil
+	}))
+	a := &agent{toolConcurrency: 2}
+	invocation := &Invocation{Tools: []bladestools.Tool{tool}}
+	message := newToolCallMessage("slow", "slow", "slow", "slow", "slow")
+
+	if _, err := a.executeTools(context.Background(), invocation, message); err != nil {
+		t.Fatalf("executeTools returned error: %v", err)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("peak concurrency = %d, want <= 2", got)
+	}
+	for i, part := range message.Parts {
+		if !part.(ToolPart).Completed {
+			t.Fatalf("part %d not completed", i)
+		}
+	}
+}
+
+func TestAgentExecuteToolsRunsSerialToolsInOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string, delay time.Duration) bladestools.Handler {
+		return bladestools.HandleFunc(func(context.Context, string) (string, error) {
+			time.Sleep(delay)
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return name, nil
+		})
+	}
+	invocation := &Invocation{Tools: []bladestools.Tool{
+		bladestools.NewTool("slow", "slow", record("slow", 20*time.Millisecond)),
+		bladestools.NewTool("write", "write", record("write", 0), bladestools.WithSerial()),
+		bladestools.NewTool("fast", "fast", record("fast", 0)),
+	}}
+	message := newToolCallMessage("slow", "write", "fast")
+
+	if _, err := (&agent{}).executeTools(context.Background(), invocation, message); err != nil {
+		t.Fatalf("executeTools returned error: %v", err)
+	}
+	if got, want := fmt.Sprint(order), "[slow write fast]"; got != want {
+		t.Fatalf("execution order = %s, want %s", got, want)
+	}
+}
+
+func TestAgentExecuteToolsTimeout(t *testing.T) {
+	t.Parallel()
+
+	block := make(chan struct{})
+	defer close(block)
+	tool := bladestools.NewTool("hang", "hang", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		<-block // ignores its context on purpose
+		return "", nil
+	}))
+	invocation := &Invocation{Tools: []bladestools.Tool{tool}}
+
+	_, err := (&agent{toolTimeout: 10 * time.Millisecond}).executeTools(context.Background(), invocation, newToolCallMessage("hang"))
+	if !errors.Is(err, ErrToolTimeout) {
+		t.Fatalf("executeTools error = %v, want ErrToolTimeout", err)
+	}
+
+	// With a tool error policy the timeout is reported to the model instead.
+	a := &agent{toolTimeout: 10 * time.Millisecond, toolErrorClassifier: DefaultToolErrorClassifier}
+	message := newToolCallMessage("hang")
+	if _, err := a.executeTools(context.Background(), invocation, message); err != nil {
+		t.Fatalf("executeTools returned error: %v", err)
+	}
+	if got := message.Parts[0].(ToolPart).Response; got == "" {
+		t.Fatalf("expected timeout error payload in tool response")
+	}
+}
+
+func TestAgentExecuteToolsPerToolTimeoutOverridesAgent(t *testing.T) {
+	t.Parallel()
+
+	tool := bladestools.NewTool("wait", "wait", bladestools.HandleFunc(func(ctx context.Context, _ string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}), bladestools.WithTimeout(5*time.Millisecond))
+	invocation := &Invocation{Tools: []bladestools.Tool{tool}}
+
+	_, err := (&agent{toolTimeout: time.Hour}).executeTools(context.Background(), invocation, newToolCallMessage("wait"))
+	if !errors.Is(err, ErrToolTimeout) {
+		t.Fatalf("executeTools error = %v, want ErrToolTimeout", err)
+	}
+}
@@ -0,0 +1,124 @@
+package blades
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+func newFailingToolMessage(name string) *Message {
+	message := NewAssistantMessage(StatusCompleted)
+	message.Role = RoleTool
+	message.Parts = append(message.Parts, NewToolPart("call_1", name, `{}`))
+	return message
+}
+
+func TestAgentExecuteToolsAbortsWithoutToolErrorPolicy(t *testing.T) {
+	t.Parallel()
+
+	invocation := &Invocation{}
+	_, err := (&agent{}).executeTools(context.Background(), invocation, newFailingToolMessage("missing"))
+	if !errors.Is(err, ErrToolNotFound) {
+		t.Fatalf("executeTools error = %v, want ErrToolNotFound", err)
+	}
+}
+
+func TestAgentExecuteToolsReportsToolErrorToModel(t *testing.T) {
+	t.Parallel()
+
+	tool := bladestools.NewTool("fail", "fail", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "", errors.New("bad arguments")
+	}))
+	a, err := NewAgent("tool-error", WithModel(&captureModel{}), WithToolErrorPolicy(nil))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	invocation := &Invocation{Tools: []bladestools.Tool{tool}}
+	message := NewAssistantMessage(StatusCompleted)
+	message.Role = RoleTool
+	message.Parts = append(message.Parts,
+		NewToolPart("call_1", "fail", `{}`),
+		NewToolPart("call_2", "missing", `{}`),
+	)
+
+	got, err := a.(*agent).executeTools(context.Background(), invocation, message)
+	if err != nil {
+		t.Fatalf("executeTools returned error: %v", err)
+	}
+	for i, want := range []string{
+		`{"error":"bad arguments"}`,
+		`{"error":"agent: tool not found: missing"}`,
+	} {
+		part := got.Parts[i].(ToolPart)
+		if !part.Completed {
+			t.Fatalf("part %d completed = false, want true", i)
+		}
+		if part.Response != want {
+			t.Fatalf("part %d response = %q, want %q", i, part.Response, want)
+		}
+	}
+}
+
+func TestAgentExecuteToolsClassifiesToolErrors(t *testing.T) {
+	t.Parallel()
+
+	errFatal := errors.New("fatal")
+	errPaused := errors.New("needs human input")
+	tests := []struct {
+		name    string
+		toolErr error
+		want    error
+	}{
+		{name: "fatal", toolErr: errFatal, want: errFatal},
+		{name: "interrupt", toolErr: errPaused, want: ErrInterrupted},
+		{name: "interrupted always propagates", toolErr: ErrInterrupted, want: ErrInterrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := bladestools.NewTool("fail", "fail", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+				return "", tt.toolErr
+			}))
+			a := &agent{toolErrorClassifier: func(_ context.Context, part ToolPart, err error) ToolErrorKind {
+				switch {
+				case errors.Is(err, errFatal):
+					return ToolErrorFatal
+				case errors.Is(err, errPaused):
+					return ToolErrorInterrupt
+				default:
+					return ToolErrorRetryable
+				}
+			}}
+			invocation := &Invocation{Tools: []bladestools.Tool{tool}}
+			_, err := a.executeTools(context.Background(), invocation, newFailingToolMessage("fail"))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("executeTools error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentRunFeedsToolErrorBackToModel(t *testing.T) {
+	t.Parallel()
+
+	model := &toolLoopSessionModel{}
+	tool := bladestools.NewTool("echo", "echo", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "", errors.New("echo is offline")
+	}))
+	a, err := NewAgent("tool-agent", WithModel(model), WithTools(tool), WithToolErrorPolicy(nil))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	output, err := NewRunner(a).Run(context.Background(), UserMessage("hello"))
+	if err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+	if got, want := output.Text(), "done"; got != want {
+		t.Fatalf("output text = %q, want %q", got, want)
+	}
+	part := model.secondInput[1].Parts[0].(ToolPart)
+	if got, want := part.Response, `{"error":"echo is offline"}`; got != want {
+		t.Fatalf("tool response = %q, want %q", got, want)
+	}
+}
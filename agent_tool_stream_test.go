@@ -0,0 +1,225 @@
+package blades
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	bladestools "github.com/go-kratos/blades/tools"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// toolCallStreamingModel streams a call to tool on its first call and a
+// completed answer afterwards.
+type toolCallStreamingModel struct {
+	tool      string
+	calls     int
+	responses []string
+}
+
+func (m *toolCallStreamingModel) Name() string { return "tool-call-stream" }
+
+func (m *toolCallStreamingModel) Generate(context.Context, *ModelRequest) (*ModelResponse, error) {
+	return nil, ErrNoFinalResponse
+}
+
+func (m *toolCallStreamingModel) NewStreaming(_ context.Context, req *ModelRequest) Generator[*ModelResponse, error] {
+	return func(yield func(*ModelResponse, error) bool) {
+		m.calls++
+		msg := NewAssistantMessage(StatusCompleted)
+		if m.calls == 1 {
+			msg.Role = RoleTool
+			msg.Parts = append(msg.Parts, NewToolPart("call_1", m.tool, `{}`))
+			yield(&ModelResponse{Message: msg}, nil)
+			return
+		}
+		for _, part := range req.Messages[len(req.Messages)-1].Parts {
+			if v, ok := part.(ToolPart); ok {
+				m.responses = append(m.responses, v.Response)
+			}
+		}
+		msg.Parts = append(msg.Parts, TextPart{Text: "done"})
+		yield(&ModelResponse{Message: msg}, nil)
+	}
+}
+
+// progressTool reports two updates when streamed and a plain result otherwise.
+type progressTool struct{}
+
+func (progressTool) Name() string                     { return "crawl" }
+func (progressTool) Description() string              { return "crawl pages" }
+func (progressTool) InputSchema() *jsonschema.Schema  { return nil }
+func (progressTool) OutputSchema() *jsonschema.Schema { return nil }
+
+func (progressTool) Handle(context.Context, string) (string, error) {
+	return "all pages", nil
+}
+
+func (progressTool) HandleStream(context.Context, string) iter.Seq2[*bladestools.Update, error] {
+	return func(yield func(*bladestools.Update, error) bool) {
+		if !yield(&bladestools.Update{Output: "page 1", Progress: 0.5, Message: "crawling"}, nil) {
+			return
+		}
+		yield(&bladestools.Update{Output: "page 1, page 2", Progress: 1}, nil)
+	}
+}
+
+func TestRunStreamForwardsToolUpdates(t *testing.T) {
+	t.Parallel()
+
+	model := &toolCallStreamingModel{tool: "crawl"}
+	agent, err := NewAgent("agent", WithModel(model), WithTools(progressTool{}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	var (
+		mu       sync.Mutex
+		progress []float64
+	)
+	runner := NewRunner(agent, WithEventHandler(func(_ context.Context, event *Event) {
+		if event.Type == EventToolProgress {
+			mu.Lock()
+			progress = append(progress, event.Metadata["progress"].(float64))
+			mu.Unlock()
+		}
+	}))
+	var outputs []string
+	for m, err := range runner.RunStream(context.Background(), UserMessage("crawl")) {
+		if err != nil {
+			t.Fatalf("run stream: %v", err)
+		}
+		if m.Role == RoleTool && m.Status == StatusInProgress {
+			outputs = append(outputs, m.Parts[0].(ToolPart).Response)
+		}
+	}
+	if want := []string{"page 1", "page 1, page 2"}; !slices.Equal(outputs, want) {
+		t.Fatalf("in-progress outputs = %v, want %v", outputs, want)
+	}
+	if want := []float64{0.5, 1}; !slices.Equal(progress, want) {
+		t.Fatalf("progress events = %v, want %v", progress, want)
+	}
+	if want := []string{"page 1, page 2"}; !slices.Equal(model.responses, want) {
+		t.Fatalf("model saw tool responses %v, want %v", model.responses, want)
+	}
+}
+
+func TestRunUsesPlainHandlerOfStreamingTool(t *testing.T) {
+	t.Parallel()
+
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m", tool: "crawl"}), WithTools(progressTool{}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	session := NewSession()
+	if _, err := NewRunner(agent).Run(context.Background(), UserMessage("crawl"), WithSession(session)); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if got := history[1].Parts[0].(ToolPart).Response; got != "all pages" {
+		t.Fatalf("tool response = %q, want all pages", got)
+	}
+}
+
+func TestAgentToolHandleStream(t *testing.T) {
+	t.Parallel()
+
+	sub, err := NewAgent("sub", WithModel(&scriptedStreamingModel{
+		streamResponses: []*ModelResponse{
+			streamingResponse(StatusIncomplete, "hel"),
+			streamingResponse(StatusIncomplete, "lo"),
+			streamingResponse(StatusCompleted, "hello"),
+		},
+	}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	tool := NewAgentTool(sub).(bladestools.StreamingTool)
+	var outputs []string
+	for update, err := range tool.HandleStream(context.Background(), "greet") {
+		if err != nil {
+			t.Fatalf("handle stream: %v", err)
+		}
+		outputs = append(outputs, update.Output)
+	}
+	if want := []string{"hel", "hello", "hello"}; !slices.Equal(outputs, want) {
+		t.Fatalf("outputs = %v, want %v", outputs, want)
+	}
+}
+
+func TestRunStreamSkipsNilToolUpdates(t *testing.T) {
+	t.Parallel()
+
+	tool := bladestools.NewTool("crawl", "crawl pages", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "", nil
+	}), bladestools.WithStream(func(context.Context, string) iter.Seq2[*bladestools.Update, error] {
+		return func(yield func(*bladestools.Update, error) bool) {
+			if !yield(nil, nil) {
+				return
+			}
+			yield(&bladestools.Update{Output: "page 1", Progress: 1}, nil)
+		}
+	}))
+	model := &toolCallStreamingModel{tool: "crawl"}
+	agent, err := NewAgent("agent", WithModel(model), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	for _, err := range NewRunner(agent).RunStream(context.Background(), UserMessage("crawl")) {
+		if err != nil {
+			t.Fatalf("run stream: %v", err)
+		}
+	}
+	if want := []string{"page 1"}; !slices.Equal(model.responses, want) {
+		t.Fatalf("model saw tool responses %v, want %v", model.responses, want)
+	}
+}
+
+func TestRunStreamDropsUpdatesOfTimedOutTool(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	tool := bladestools.NewTool("crawl", "crawl pages", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "", nil
+	}), bladestools.WithTimeout(20*time.Millisecond), bladestools.WithStream(func(context.Context, string) iter.Seq2[*bladestools.Update, error] {
+		return func(yield func(*bladestools.Update, error) bool) {
+			defer close(finished)
+			if !yield(&bladestools.Update{Output: "early", Progress: 0.5}, nil) {
+				return
+			}
+			<-release
+			yield(&bladestools.Update{Output: "late", Progress: 1}, nil)
+		}
+	}))
+	agent, err := NewAgent("agent", WithModel(&toolCallStreamingModel{tool: "crawl"}), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	var (
+		mu      sync.Mutex
+		outputs []string
+	)
+	runner := NewRunner(agent, WithEventHandler(func(_ context.Context, event *Event) {
+		if event.Type == EventToolProgress {
+			mu.Lock()
+			outputs = append(outputs, event.Tool.Response)
+			mu.Unlock()
+		}
+	}))
+	for range runner.RunStream(context.Background(), UserMessage("crawl")) {
+	}
+	close(release)
+	<-finished
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"early"}; !slices.Equal(outputs, want) {
+		t.Fatalf("progress outputs = %v, want %v", outputs, want)
+	}
+}
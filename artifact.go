@@ -0,0 +1,315 @@
+package blades
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// defaultArtifactThreshold is the size above which a DataPart yielded by an
+// agent is moved to the ArtifactStore.
+const defaultArtifactThreshold = 64 << 10
+
+// Artifact is a versioned binary output of an agent or tool, scoped to a session.
+type Artifact struct {
+	Name     string   `json:"name"`
+	Version  int      `json:"version"`
+	MIMEType MIMEType `json:"mimeType"`
+	Data     []byte   `json:"data"`
+}
+
+// ArtifactStore stores the artifacts of sessions. Every save of a name creates
+// a new version, starting at 1.
+type ArtifactStore interface {
+	// Save stores data as the next version of the named artifact and returns that version.
+	Save(ctx context.Context, sessionID, name string, data []byte, mimeType MIMEType) (int, error)
+	// Load returns a version of the named artifact; version 0 loads the latest.
+	// It returns ErrArtifactNotFound if the artifact or version does not exist.
+	Load(ctx context.Context, sessionID, name string, version int) (*Artifact, error)
+	// List returns the sorted names of the artifacts of the session.
+	List(ctx context.Context, sessionID string) ([]string, error)
+	// Versions returns the versions of the named artifact in ascending order.
+	Versions(ctx context.Context, sessionID, name string) ([]int, error)
+}
+
+// inMemoryArtifactStore is an ArtifactStore that keeps artifacts in process memory.
+type inMemoryArtifactStore struct {
+	mu        sync.RWMutex
+	artifacts map[string]map[string][]*Artifact
+}
+
+// NewInMemoryArtifactStore creates an ArtifactStore backed by process memory.
+func NewInMemoryArtifactStore() ArtifactStore {
+	return &inMemoryArtifactStore{artifacts: make(map[string]map[string][]*Artifact)}
+}
+
+func (s *inMemoryArtifactStore) Save(ctx context.Context, sessionID, name string, data []byte, mimeType MIMEType) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names, ok := s.artifacts[sessionID]
+	if !ok {
+		names = make(map[string][]*Artifact)
+		s.artifacts[sessionID] = names
+	}
+	version := len(names[name]) + 1
+	names[name] = append(names[name], &Artifact{
+		Name:     name,
+		Version:  version,
+		MIMEType: mimeType,
+		Data:     slices.Clone(data),
+	})
+	return version, nil
+}
+
+func (s *inMemoryArtifactStore) Load(ctx context.Context, sessionID, name string, version int) (*Artifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := s.artifacts[sessionID][name]
+	if version == 0 {
+		version = len(versions)
+	}
+	if version < 1 || version > len(versions) {
+		return nil, fmt.Errorf("%w: %s version %d", ErrArtifactNotFound, name, version)
+	}
+	artifact := *versions[version-1]
+	artifact.Data = slices.Clone(artifact.Data)
+	return &artifact, nil
+}
+
+func (s *inMemoryArtifactStore) List(ctx context.Context, sessionID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.artifacts[sessionID]))
+	for name := range s.artifacts[sessionID] {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s *inMemoryArtifactStore) Versions(ctx context.Context, sessionID, name string) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := make([]int, len(s.artifacts[sessionID][name]))
+	for i := range versions {
+		versions[i] = i + 1
+	}
+	return versions, nil
+}
+
+// WithArtifactStore sets the ArtifactStore that tools save artifacts to and
+// that large DataParts yielded by agents are moved to.
+func WithArtifactStore(store ArtifactStore) RunnerOption {
+	return func(r *Runner) {
+		r.artifactStore = store
+	}
+}
+
+// WithArtifactThreshold sets the size in bytes above which a DataPart yielded
+// by an agent is saved as an artifact and replaced with a FilePart reference in
+// the session history. By default, it is 64 KiB; a negative value disables it.
+func WithArtifactThreshold(n int) RunnerOption {
+	return func(r *Runner) {
+		r.artifactThreshold = n
+	}
+}
+
+// ArtifactURI returns the URI of a FilePart referencing a version of an artifact.
+func ArtifactURI(name string, version int) string {
+	return "artifact:" + url.PathEscape(name) + "?version=" + strconv.Itoa(version)
+}
+
+// ParseArtifactURI returns the artifact name and version referenced by a URI
+// created by ArtifactURI. ok is false for other URIs.
+func ParseArtifactURI(uri string) (name string, version int, ok bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "artifact" || u.Opaque == "" {
+		return "", 0, false
+	}
+	name, err = url.PathUnescape(u.Opaque)
+	if err != nil {
+		return "", 0, false
+	}
+	version, err = strconv.Atoi(u.Query().Get("version"))
+	if err != nil {
+		return "", 0, false
+	}
+	return name, version, true
+}
+
+// artifactStoreFromContext returns the ArtifactStore of the Runner in ctx.
+func artifactStoreFromContext(ctx context.Context) (ArtifactStore, bool) {
+	r, ok := runnerFromContext(ctx)
+	if !ok || r.artifactStore == nil {
+		return nil, false
+	}
+	return r.artifactStore, true
+}
+
+// saveArtifact saves data as an artifact of the session in ctx.
+func saveArtifact(ctx context.Context, name string, data []byte, mimeType MIMEType) (int, error) {
+	store, ok := artifactStoreFromContext(ctx)
+	if !ok {
+		return 0, ErrArtifactStoreRequired
+	}
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return 0, ErrNoSessionContext
+	}
+	return store.Save(ctx, session.ID(), name, data, mimeType)
+}
+
+// loadArtifact loads an artifact of the session in ctx.
+func loadArtifact(ctx context.Context, name string, version int) (*Artifact, error) {
+	store, ok := artifactStoreFromContext(ctx)
+	if !ok {
+		return nil, ErrArtifactStoreRequired
+	}
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, ErrNoSessionContext
+	}
+	return store.Load(ctx, session.ID(), name, version)
+}
+
+// artifactRefs is a Middleware that saves the DataParts of completed messages
+// that exceed the Runner's artifact threshold to its ArtifactStore, replacing
+// them with FilePart references before the messages reach the session history.
+func artifactRefs() Middleware {
+	return func(next Handler) Handler {
+		return HandleFunc(func(ctx context.Context, invocation *Invocation) Generator[*Message, error] {
+			return func(yield func(*Message, error) bool) {
+				r, ok := runnerFromContext(ctx)
+				if !ok || r.artifactStore == nil || r.artifactThreshold < 0 {
+					for m, err := range next.Handle(ctx, invocation) {
+						if !yield(m, err) {
+							return
+						}
+					}
+					return
+				}
+				for m, err := range next.Handle(ctx, invocation) {
+					if err == nil && m != nil && m.Status == StatusCompleted {
+						err = replaceDataParts(ctx, m, r.artifactThreshold)
+					}
+					if !yield(m, err) {
+						return
+					}
+				}
+			}
+		})
+	}
+}
+
+// replaceDataParts moves the DataParts of message larger than threshold bytes
+// to the ArtifactStore, including those in the content of tool results.
+// Unnamed parts are named after the message and part index.
+func replaceDataParts(ctx context.Context, message *Message, threshold int) error {
+	if threshold == 0 {
+		threshold = defaultArtifactThreshold
+	}
+	for i, part := range message.Parts {
+		name := fmt.Sprintf("%s-%d", message.ID, i)
+		switch v := part.(type) {
+		case DataPart:
+			ref, err := replaceDataPart(ctx, v, name, threshold)
+			if err != nil {
+				return err
+			}
+			message.Parts[i] = ref
+		case ToolPart:
+			if len(v.Content) == 0 {
+				continue
+			}
+			content := slices.Clone(v.Content)
+			for j, part := range content {
+				data, ok := part.(DataPart)
+				if !ok {
+					continue
+				}
+				ref, err := replaceDataPart(ctx, data, fmt.Sprintf("%s-%d", name, j), threshold)
+				if err != nil {
+					return err
+				}
+				content[j] = ref
+			}
+			v.Content = content
+			message.Parts[i] = v
+		}
+	}
+	return nil
+}
+
+// replaceDataPart saves part as an artifact named name, unless it has a name,
+// and returns a FilePart referencing it. Parts of at most threshold bytes are
+// returned as-is.
+func replaceDataPart(ctx context.Context, part DataPart, name string, threshold int) (Part, error) {
+	if len(part.Bytes) <= threshold {
+		return part, nil
+	}
+	if part.Name != "" {
+		name = part.Name
+	}
+	version, err := saveArtifact(ctx, name, part.Bytes, part.MIMEType)
+	if err != nil {
+		return nil, err
+	}
+	return FilePart{Name: name, URI: ArtifactURI(name, version), MIMEType: part.MIMEType}, nil
+}
+
+// artifactPlaceholders returns messages with FilePart artifact references
+// replaced by text placeholders, since model providers cannot fetch them.
+// Messages without references are returned as-is.
+func artifactPlaceholders(messages []*Message) []*Message {
+	var out []*Message
+	for i, m := range messages {
+		parts := placeholderParts(m.Parts)
+		if parts == nil {
+			continue
+		}
+		if out == nil {
+			out = slices.Clone(messages)
+		}
+		clone := *m
+		clone.Parts = parts
+		out[i] = &clone
+	}
+	if out == nil {
+		return messages
+	}
+	return out
+}
+
+// placeholderParts returns a copy of parts with artifact references replaced
+// by text placeholders, including those in the content of tool results. It
+// returns nil if parts has no reference.
+func placeholderParts(parts []Part) []Part {
+	var out []Part
+	for i, part := range parts {
+		switch v := part.(type) {
+		case FilePart:
+			name, version, ok := ParseArtifactURI(v.URI)
+			if !ok {
+				continue
+			}
+			part = TextPart{Text: fmt.Sprintf("[artifact %q version %d (%s)]", name, version, v.MIMEType)}
+		case ToolPart:
+			content := placeholderParts(v.Content)
+			if content == nil {
+				continue
+			}
+			v.Content = content
+			part = v
+		default:
+			continue
+		}
+		if out == nil {
+			out = slices.Clone(parts)
+		}
+		out[i] = part
+	}
+	return out
+}
@@ -0,0 +1,202 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/blades"
+)
+
+const metaExt = ".json"
+
+// artifactMeta is stored next to the data of every artifact version.
+type artifactMeta struct {
+	MIMEType blades.MIMEType `json:"mimeType"`
+}
+
+// artifactStore is an ArtifactStore that keeps one file per artifact version.
+type artifactStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewArtifactStore returns an ArtifactStore that persists artifacts under dir
+// as <session>/<name>/<version>, with the MIME type in <version>.json. The
+// directory is created if it does not exist. Versions are allocated under a
+// process-local lock, so a directory must not be shared by several replicas.
+func NewArtifactStore(dir string) (blades.ArtifactStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &artifactStore{dir: dir}, nil
+}
+
+// path returns the directory holding the versions of an artifact, rejecting
+// session IDs and names that would escape dir.
+func (s *artifactStore) path(sessionID, name string) (string, error) {
+	if sessionID == "" || sessionID == "." || sessionID == ".." || strings.ContainsAny(sessionID, `/\`) {
+		return "", fmt.Errorf("file: invalid session id %q", sessionID)
+	}
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("file: invalid artifact name %q", name)
+	}
+	return filepath.Join(s.dir, sessionID, url.PathEscape(name)), nil
+}
+
+// versions returns the stored versions in dir in ascending order.
+func (s *artifactStore) versions(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, entry := range entries {
+		version, err := strconv.Atoi(entry.Name())
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+func (s *artifactStore) Save(ctx context.Context, sessionID, name string, data []byte, mimeType blades.MIMEType) (int, error) {
+	dir, err := s.path(sessionID, name)
+	if err != nil {
+		return 0, err
+	}
+	meta, err := json.Marshal(artifactMeta{MIMEType: mimeType})
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	versions, err := s.versions(dir)
+	if err != nil {
+		return 0, err
+	}
+	version := 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1] + 1
+	}
+	path := filepath.Join(dir, strconv.Itoa(version))
+	// The metadata is written first so a visible version always has a MIME type.
+	if err := writeFile(dir, path+metaExt, meta); err != nil {
+		return 0, err
+	}
+	if err := writeFile(dir, path, data); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (s *artifactStore) Load(ctx context.Context, sessionID, name string, version int) (*blades.Artifact, error) {
+	dir, err := s.path(sessionID, name)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		s.mu.Lock()
+		versions, err := s.versions(dir)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, fmt.Errorf("%w: %s", blades.ErrArtifactNotFound, name)
+		}
+		version = versions[len(versions)-1]
+	}
+	path := filepath.Join(dir, strconv.Itoa(version))
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s version %d", blades.ErrArtifactNotFound, name, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var meta artifactMeta
+	if b, err := os.ReadFile(path + metaExt); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("file: decode artifact %s version %d: %w", name, version, err)
+	}
+	return &blades.Artifact{Name: name, Version: version, MIMEType: meta.MIMEType, Data: data}, nil
+}
+
+func (s *artifactStore) List(ctx context.Context, sessionID string) ([]string, error) {
+	if _, err := s.path(sessionID, "_"); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(s.dir, sessionID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s *artifactStore) Versions(ctx context.Context, sessionID, name string) ([]int, error) {
+	dir, err := s.path(sessionID, name)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions, err := s.versions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []int{}
+	}
+	return versions, nil
+}
+
+// writeFile atomically writes data to path through a temporary file in dir.
+func writeFile(dir, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, ".artifact-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
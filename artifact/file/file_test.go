@@ -0,0 +1,87 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/go-kratos/blades"
+	"github.com/go-kratos/blades/artifact/file"
+)
+
+func TestArtifactStore_Versions(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := file.NewArtifactStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{"first", "second"} {
+		if _, err := store.Save(ctx, "s1", "notes/today.md", []byte(data), blades.MIMEMarkdown); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new store over the same directory sees the saved versions.
+	store, err = file.NewArtifactStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := store.Load(ctx, "s1", "notes/today.md", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(latest.Data) != "second" || latest.Version != 2 || latest.MIMEType != blades.MIMEMarkdown {
+		t.Errorf("latest = %+v", latest)
+	}
+	first, err := store.Load(ctx, "s1", "notes/today.md", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first.Data) != "first" {
+		t.Errorf("v1 = %q, want first", first.Data)
+	}
+	versions, err := store.Versions(ctx, "s1", "notes/today.md")
+	if err != nil || !slices.Equal(versions, []int{1, 2}) {
+		t.Errorf("versions = %v, %v", versions, err)
+	}
+	names, err := store.List(ctx, "s1")
+	if err != nil || !slices.Equal(names, []string{"notes/today.md"}) {
+		t.Errorf("names = %v, %v", names, err)
+	}
+}
+
+func TestArtifactStore_NotFound(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.NewArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(ctx, "s1", "missing", 0); !errors.Is(err, blades.ErrArtifactNotFound) {
+		t.Errorf("Load latest err = %v, want ErrArtifactNotFound", err)
+	}
+	if _, err := store.Save(ctx, "s1", "report", []byte("x"), blades.MIMEText); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(ctx, "s1", "report", 2); !errors.Is(err, blades.ErrArtifactNotFound) {
+		t.Errorf("Load v2 err = %v, want ErrArtifactNotFound", err)
+	}
+	names, err := store.List(ctx, "s2")
+	if err != nil || len(names) != 0 {
+		t.Errorf("List empty session = %v, %v", names, err)
+	}
+}
+
+func TestArtifactStore_InvalidSessionID(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.NewArtifactStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"", "..", "a/b"} {
+		if _, err := store.Save(ctx, id, "report", []byte("x"), blades.MIMEText); err == nil {
+			t.Errorf("Save(%q) succeeded, want error", id)
+		}
+	}
+}
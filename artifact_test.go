@@ -0,0 +1,246 @@
+package blades
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+// imageModel answers every call with an image and records the requests.
+type imageModel struct {
+	image    []byte
+	captured [][]*Message
+}
+
+func (m *imageModel) Name() string { return "image" }
+
+func (m *imageModel) Generate(_ context.Context, req *ModelRequest) (*ModelResponse, error) {
+	m.captured = append(m.captured, slices.Clone(req.Messages))
+	msg := NewAssistantMessage(StatusCompleted)
+	msg.Parts = append(msg.Parts, DataPart{Name: "cat.png", Bytes: m.image, MIMEType: MIMEImagePNG})
+	return &ModelResponse{Message: msg}, nil
+}
+
+func (m *imageModel) NewStreaming(context.Context, *ModelRequest) Generator[*ModelResponse, error] {
+	return nil
+}
+
+func TestInMemoryArtifactStoreVersions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewInMemoryArtifactStore()
+	for i, data := range []string{"v1", "v2"} {
+		version, err := store.Save(ctx, "s1", "report", []byte(data), MIMEText)
+		if err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if version != i+1 {
+			t.Fatalf("version = %d, want %d", version, i+1)
+		}
+	}
+	latest, err := store.Load(ctx, "s1", "report", 0)
+	if err != nil {
+		t.Fatalf("load latest: %v", err)
+	}
+	if string(latest.Data) != "v2" || latest.Version != 2 {
+		t.Fatalf("latest = %+v, want v2", latest)
+	}
+	first, err := store.Load(ctx, "s1", "report", 1)
+	if err != nil {
+		t.Fatalf("load v1: %v", err)
+	}
+	if string(first.Data) != "v1" {
+		t.Fatalf("v1 data = %q", first.Data)
+	}
+	if _, err := store.Load(ctx, "s2", "report", 0); !errors.Is(err, ErrArtifactNotFound) {
+		t.Fatalf("load from other session err = %v, want ErrArtifactNotFound", err)
+	}
+	versions, err := store.Versions(ctx, "s1", "report")
+	if err != nil || !slices.Equal(versions, []int{1, 2}) {
+		t.Fatalf("versions = %v, %v", versions, err)
+	}
+	names, err := store.List(ctx, "s1")
+	if err != nil || !slices.Equal(names, []string{"report"}) {
+		t.Fatalf("names = %v, %v", names, err)
+	}
+}
+
+func TestToolContextSavesArtifact(t *testing.T) {
+	t.Parallel()
+
+	tool := bladestools.NewTool("render", "render a chart", bladestools.HandleFunc(func(ctx context.Context, _ string) (string, error) {
+		toolCtx, _ := bladestools.FromContext(ctx)
+		artifacts, ok := toolCtx.(bladestools.ArtifactContext)
+		if !ok {
+			return "", errors.New("missing artifact context")
+		}
+		version, err := artifacts.SaveArtifact(ctx, "chart.svg", []byte("<svg/>"), "image/svg+xml")
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(version), nil
+	}))
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m", tool: "render"}), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	store := NewInMemoryArtifactStore()
+	session := NewSession()
+	if _, err := NewRunner(agent, WithArtifactStore(store)).Run(context.Background(), UserMessage("chart"), WithSession(session)); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	artifact, err := store.Load(context.Background(), session.ID(), "chart.svg", 0)
+	if err != nil {
+		t.Fatalf("load artifact: %v", err)
+	}
+	if string(artifact.Data) != "<svg/>" || artifact.MIMEType != "image/svg+xml" {
+		t.Fatalf("artifact = %+v", artifact)
+	}
+}
+
+func TestToolContextArtifactStoreRequired(t *testing.T) {
+	t.Parallel()
+
+	var saveErr error
+	tool := bladestools.NewTool("render", "render a chart", bladestools.HandleFunc(func(ctx context.Context, _ string) (string, error) {
+		toolCtx, _ := bladestools.FromContext(ctx)
+		_, saveErr = toolCtx.(bladestools.ArtifactContext).SaveArtifact(ctx, "chart.svg", []byte("<svg/>"), "image/svg+xml")
+		return "", nil
+	}))
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m", tool: "render"}), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	if _, err := NewRunner(agent).Run(context.Background(), UserMessage("chart")); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !errors.Is(saveErr, ErrArtifactStoreRequired) {
+		t.Fatalf("save err = %v, want ErrArtifactStoreRequired", saveErr)
+	}
+}
+
+func TestLargeDataPartsMovedToArtifactStore(t *testing.T) {
+	t.Parallel()
+
+	model := &imageModel{image: bytes.Repeat([]byte{0xff}, 128)}
+	agent, err := NewAgent("agent", WithModel(model), WithContext(true))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	store := NewInMemoryArtifactStore()
+	runner := NewRunner(agent, WithArtifactStore(store), WithArtifactThreshold(64))
+	session := NewSession()
+	for _, text := range []string{"draw a cat", "again"} {
+		if _, err := runner.Run(context.Background(), UserMessage(text), WithSession(session)); err != nil {
+			t.Fatalf("run %q: %v", text, err)
+		}
+	}
+
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	file, ok := history[1].Parts[0].(FilePart)
+	if !ok {
+		t.Fatalf("history part = %T, want FilePart", history[1].Parts[0])
+	}
+	name, version, ok := ParseArtifactURI(file.URI)
+	if !ok || name != "cat.png" || version != 1 {
+		t.Fatalf("artifact uri = %q", file.URI)
+	}
+	artifact, err := store.Load(context.Background(), session.ID(), name, version)
+	if err != nil {
+		t.Fatalf("load artifact: %v", err)
+	}
+	if !bytes.Equal(artifact.Data, model.image) || artifact.MIMEType != MIMEImagePNG {
+		t.Fatalf("artifact = %+v", artifact)
+	}
+
+	// The second call sees a placeholder instead of the reference or the bytes.
+	placeholder, ok := model.captured[1][1].Parts[0].(TextPart)
+	if !ok || !strings.Contains(placeholder.Text, `"cat.png" version 1`) {
+		t.Fatalf("model saw %#v, want artifact placeholder", model.captured[1][1].Parts[0])
+	}
+	if _, ok := history[1].Parts[0].(FilePart); !ok {
+		t.Fatalf("placeholder leaked into the session history")
+	}
+}
+
+func TestLargeToolContentMovedToArtifactStore(t *testing.T) {
+	t.Parallel()
+
+	image := bytes.Repeat([]byte{0xff}, 128)
+	tool := bladestools.NewTool("render", "render a chart", bladestools.HandleFunc(func(ctx context.Context, _ string) (string, error) {
+		return "rendered", AddToolContent(ctx, DataPart{Name: "chart.png", Bytes: image, MIMEType: MIMEImagePNG})
+	}))
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m", tool: "render"}), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	store := NewInMemoryArtifactStore()
+	session := NewSession()
+	if _, err := NewRunner(agent, WithArtifactStore(store), WithArtifactThreshold(64)).Run(context.Background(), UserMessage("chart"), WithSession(session)); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	var part ToolPart
+	for _, m := range history {
+		for _, p := range m.Parts {
+			if v, ok := p.(ToolPart); ok {
+				part = v
+			}
+		}
+	}
+	if len(part.Content) != 1 {
+		t.Fatalf("tool part = %#v", part)
+	}
+	file, ok := part.Content[0].(FilePart)
+	if !ok {
+		t.Fatalf("tool content = %T, want FilePart", part.Content[0])
+	}
+	name, version, ok := ParseArtifactURI(file.URI)
+	if !ok || name != "chart.png" || version != 1 {
+		t.Fatalf("artifact uri = %q", file.URI)
+	}
+	artifact, err := store.Load(context.Background(), session.ID(), name, version)
+	if err != nil {
+		t.Fatalf("load artifact: %v", err)
+	}
+	if !bytes.Equal(artifact.Data, image) {
+		t.Fatalf("artifact = %+v", artifact)
+	}
+
+	// Models see a placeholder for the reference.
+	messages := artifactPlaceholders([]*Message{{Role: RoleTool, Parts: []Part{part}}})
+	placeholder, ok := messages[0].Parts[0].(ToolPart).Content[0].(TextPart)
+	if !ok || !strings.Contains(placeholder.Text, `"chart.png" version 1`) {
+		t.Fatalf("model sees %#v, want artifact placeholder", messages[0].Parts[0])
+	}
+	if _, ok := part.Content[0].(FilePart); !ok {
+		t.Fatalf("placeholder replaced the tool content of the history")
+	}
+}
+
+func TestArtifactURIRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	uri := ArtifactURI("reports/q1 summary.pdf", 3)
+	name, version, ok := ParseArtifactURI(uri)
+	if !ok || name != "reports/q1 summary.pdf" || version != 3 {
+		t.Fatalf("ParseArtifactURI(%q) = %q, %d, %v", uri, name, version, ok)
+	}
+	if _, _, ok := ParseArtifactURI("https://example.com/cat.png"); ok {
+		t.Fatalf("ParseArtifactURI accepted a non-artifact URI")
+	}
+}
@@ -0,0 +1,189 @@
+package blades
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// usageStateKey is the session state key holding the session's accumulated Usage.
+const usageStateKey = "blades:usage"
+
+// Usage is the accumulated token usage and cost of model calls.
+type Usage struct {
+	TokenUsage
+	Cost float64 `json:"cost"`
+}
+
+// add accumulates the usage of one model call.
+func (u *Usage) add(usage TokenUsage, cost float64) {
+	u.InputTokens += usage.InputTokens
+	u.OutputTokens += usage.OutputTokens
+	u.TotalTokens += usage.TotalTokens
+	u.Cost += cost
+}
+
+// Pricer computes the cost of the token usage of a model call.
+type Pricer interface {
+	Cost(model string, usage TokenUsage) float64
+}
+
+// ModelPrice is the price of a model per million tokens.
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// PriceTable is a Pricer that looks up prices by model name. Models missing
+// from the table are free.
+type PriceTable map[string]ModelPrice
+
+// Cost returns the cost of the usage for the given model.
+func (p PriceTable) Cost(model string, usage TokenUsage) float64 {
+	price, ok := p[model]
+	if !ok {
+		return 0
+	}
+	return (float64(usage.InputTokens)*price.Input + float64(usage.OutputTokens)*price.Output) / 1e6
+}
+
+// Budget limits the token usage and cost of runs. Zero limits are unlimited.
+type Budget struct {
+	// MaxInvocationTokens limits the total tokens of a single run.
+	MaxInvocationTokens int64
+	// MaxInvocationCost limits the cost of a single run.
+	MaxInvocationCost float64
+	// MaxSessionTokens limits the total tokens accumulated by a session across runs.
+	MaxSessionTokens int64
+	// MaxSessionCost limits the cost accumulated by a session across runs.
+	MaxSessionCost float64
+	// Prices computes costs; without it every call is free.
+	Prices Pricer
+}
+
+// WithBudget sets the token and cost budget enforced on every run. A run that
+// crosses a limit aborts with an error wrapping ErrBudgetExceeded.
+func WithBudget(budget Budget) RunnerOption {
+	return func(r *Runner) {
+		r.budget = &budget
+	}
+}
+
+// SessionUsage returns the token usage and cost accumulated by the finished
+// runs of the session. Use InvocationUsage for the run in progress.
+func SessionUsage(session Session) Usage {
+	switch v := session.State()[usageStateKey].(type) {
+	case Usage:
+		return v
+	case nil:
+		return Usage{}
+	default:
+		// Sessions loaded by a persistent SessionStore hold JSON-shaped state.
+		var usage Usage
+		if b, err := json.Marshal(v); err == nil {
+			json.Unmarshal(b, &usage)
+		}
+		return usage
+	}
+}
+
+type ctxUsageKey struct{}
+
+// usageTracker accumulates the usage of all model calls of an invocation,
+// including those of sub-agents, and enforces the budget.
+type usageTracker struct {
+	mu         sync.Mutex
+	session    Session
+	budget     *Budget
+	invocation Usage
+}
+
+// newUsageContext returns a context that tracks the usage of model calls in session.
+func newUsageContext(ctx context.Context, session Session, budget *Budget) context.Context {
+	return context.WithValue(ctx, ctxUsageKey{}, &usageTracker{session: session, budget: budget})
+}
+
+// InvocationUsage returns the usage accumulated so far by the invocation running in ctx.
+func InvocationUsage(ctx context.Context) Usage {
+	tracker, ok := ctx.Value(ctxUsageKey{}).(*usageTracker)
+	if !ok {
+		return Usage{}
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.invocation
+}
+
+// sessionUsage returns the usage of the session including the invocation.
+func (t *usageTracker) sessionUsage() Usage {
+	usage := SessionUsage(t.session)
+	usage.add(t.invocation.TokenUsage, t.invocation.Cost)
+	return usage
+}
+
+// recordUsage adds the usage of a model call to the invocation total,
+// returning an error wrapping ErrBudgetExceeded once a limit is crossed.
+func recordUsage(ctx context.Context, model string, usage TokenUsage) error {
+	tracker, ok := ctx.Value(ctxUsageKey{}).(*usageTracker)
+	if !ok || usage == (TokenUsage{}) {
+		return nil
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	var cost float64
+	if tracker.budget != nil && tracker.budget.Prices != nil {
+		cost = tracker.budget.Prices.Cost(model, usage)
+	}
+	tracker.invocation.add(usage, cost)
+	if tracker.budget == nil {
+		return nil
+	}
+	return tracker.budget.check(tracker.invocation, tracker.sessionUsage())
+}
+
+// saveUsage adds the usage of the invocation running in ctx to the session
+// total. It runs once at the end of the run, so the state changes of the
+// messages of the run do not carry the usage.
+func saveUsage(ctx context.Context) {
+	tracker, ok := ctx.Value(ctxUsageKey{}).(*usageTracker)
+	if !ok {
+		return
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if tracker.invocation == (Usage{}) {
+		return
+	}
+	tracker.session.SetState(usageStateKey, tracker.sessionUsage())
+}
+
+// checkBudget returns an error wrapping ErrBudgetExceeded if the invocation
+// running in ctx, or its session, has already crossed a limit.
+func checkBudget(ctx context.Context) error {
+	tracker, ok := ctx.Value(ctxUsageKey{}).(*usageTracker)
+	if !ok || tracker.budget == nil {
+		return nil
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.budget.check(tracker.invocation, tracker.sessionUsage())
+}
+
+// check reports the first limit crossed by the invocation or session usage.
+func (b *Budget) check(invocation, session Usage) error {
+	switch {
+	case b.MaxInvocationTokens > 0 && invocation.TotalTokens > b.MaxInvocationTokens:
+		return fmt.Errorf("%w: invocation used %d tokens, limit %d", ErrBudgetExceeded, invocation.TotalTokens, b.MaxInvocationTokens)
+	case b.MaxInvocationCost > 0 && invocation.Cost > b.MaxInvocationCost:
+		return fmt.Errorf("%w: invocation cost %.6f, limit %.6f", ErrBudgetExceeded, invocation.Cost, b.MaxInvocationCost)
+	case b.MaxSessionTokens > 0 && session.TotalTokens > b.MaxSessionTokens:
+		return fmt.Errorf("%w: session used %d tokens, limit %d", ErrBudgetExceeded, session.TotalTokens, b.MaxSessionTokens)
+	case b.MaxSessionCost > 0 && session.Cost > b.MaxSessionCost:
+		return fmt.Errorf("%w: session cost %.6f, limit %.6f", ErrBudgetExceeded, session.Cost, b.MaxSessionCost)
+	}
+	return nil
+}
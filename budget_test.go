@@ -0,0 +1,175 @@
+package blades
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+// usageModel reports fixed token usage and calls the named tool on its first turn.
+type usageModel struct {
+	name  string
+	tool  string
+	calls int
+}
+
+func (m *usageModel) Name() string { return m.name }
+
+func (m *usageModel) Generate(context.Context, *ModelRequest) (*ModelResponse, error) {
+	m.calls++
+	msg := NewAssistantMessage(StatusCompleted)
+	msg.TokenUsage = TokenUsage{InputTokens: 80, OutputTokens: 20, TotalTokens: 100}
+	if m.tool != "" && m.calls == 1 {
+		msg.Role = RoleTool
+		msg.Parts = append(msg.Parts, NewToolPart("call_1", m.tool, `{}`))
+		return &ModelResponse{Message: msg}, nil
+	}
+	msg.Parts = append(msg.Parts, TextPart{Text: "done"})
+	return &ModelResponse{Message: msg}, nil
+}
+
+func (m *usageModel) NewStreaming(context.Context, *ModelRequest) Generator[*ModelResponse, error] {
+	return nil
+}
+
+func TestRunnerAggregatesUsageAcrossSubAgents(t *testing.T) {
+	t.Parallel()
+
+	sub, err := NewAgent("sub", WithModel(&usageModel{name: "small"}))
+	if err != nil {
+		t.Fatalf("new sub agent: %v", err)
+	}
+	root, err := NewAgent("root", WithModel(&usageModel{name: "large", tool: "sub"}), WithTools(NewAgentTool(sub)))
+	if err != nil {
+		t.Fatalf("new root agent: %v", err)
+	}
+	runner := NewRunner(root, WithBudget(Budget{
+		Prices: PriceTable{
+			"large": {Input: 10, Output: 50},
+			"small": {Input: 1, Output: 5},
+		},
+	}))
+	session := NewSession()
+	if _, err := runner.Run(context.Background(), UserMessage("hi"), WithSession(session)); err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+
+	usage := SessionUsage(session)
+	// Two root calls and one sub-agent call of 100 tokens each.
+	if got, want := usage.TotalTokens, int64(300); got != want {
+		t.Fatalf("total tokens = %d, want %d", got, want)
+	}
+	// large: 2 * (80*10 + 20*50) / 1e6, small: (80*1 + 20*5) / 1e6
+	if got, want := usage.Cost, 0.00378; math.Abs(got-want) > 1e-12 {
+		t.Fatalf("cost = %v, want %v", got, want)
+	}
+}
+
+// servingModel wraps a model and reports the wrapped model as the one that
+// served each response, as a router does.
+type servingModel struct {
+	*usageModel
+}
+
+func (m servingModel) Name() string { return "router" }
+
+func (m servingModel) Generate(ctx context.Context, req *ModelRequest) (*ModelResponse, error) {
+	resp, err := m.usageModel.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Message.Model = m.usageModel.Name()
+	return resp, nil
+}
+
+func TestRunnerPricesServingModel(t *testing.T) {
+	t.Parallel()
+
+	agent, err := NewAgent("agent", WithModel(servingModel{&usageModel{name: "small"}}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	runner := NewRunner(agent, WithBudget(Budget{
+		Prices: PriceTable{
+			"router": {Input: 10, Output: 50},
+			"small":  {Input: 1, Output: 5},
+		},
+	}))
+	session := NewSession()
+	output, err := runner.Run(context.Background(), UserMessage("hi"), WithSession(session))
+	if err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+	if got, want := output.Model, "small"; got != want {
+		t.Fatalf("output model = %q, want %q", got, want)
+	}
+	// small: (80*1 + 20*5) / 1e6
+	if got, want := SessionUsage(session).Cost, 0.00018; math.Abs(got-want) > 1e-12 {
+		t.Fatalf("cost = %v, want %v", got, want)
+	}
+}
+
+func TestRunnerBudgetExceeded(t *testing.T) {
+	t.Parallel()
+
+	tool := bladestools.NewTool("echo", "echo", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "ok", nil
+	}))
+	newRunner := func(budget Budget) *Runner {
+		agent, err := NewAgent("agent", WithModel(&usageModel{name: "m", tool: "echo"}), WithTools(tool))
+		if err != nil {
+			t.Fatalf("new agent: %v", err)
+		}
+		return NewRunner(agent, WithBudget(budget))
+	}
+
+	// The tool-driven second model call crosses the invocation limit.
+	_, err := newRunner(Budget{MaxInvocationTokens: 150}).Run(context.Background(), UserMessage("hi"))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("run error = %v, want ErrBudgetExceeded", err)
+	}
+
+	// A session over its limit refuses further runs before calling the model.
+	session := NewSession()
+	runner := newRunner(Budget{MaxSessionTokens: 250})
+	if _, err := runner.Run(context.Background(), UserMessage("hi"), WithSession(session)); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if _, err := runner.Run(context.Background(), UserMessage("again"), WithSession(session)); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("second run error = %v, want ErrBudgetExceeded", err)
+	}
+	if got, want := SessionUsage(session).TotalTokens, int64(300); got != want {
+		t.Fatalf("session tokens = %d, want %d", got, want)
+	}
+}
+
+func TestRunnerWritesUsageOncePerRun(t *testing.T) {
+	t.Parallel()
+
+	tool := bladestools.NewTool("echo", "echo", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "ok", nil
+	}))
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m", tool: "echo"}), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	session := NewSession()
+	if _, err := NewRunner(agent).Run(context.Background(), UserMessage("hi"), WithSession(session)); err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	for _, m := range history {
+		if _, ok := m.StateDelta[usageStateKey]; ok {
+			t.Fatalf("message %s carries the session usage in its state delta", m.ID)
+		}
+	}
+	if got, want := SessionUsage(session).TotalTokens, int64(200); got != want {
+		t.Fatalf("session tokens = %d, want %d", got, want)
+	}
+}
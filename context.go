@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-kratos/blades/tools"
 	"github.com/go-kratos/kit/container/maps"
+	"github.com/go-kratos/kit/container/slices"
 )
 
 // AgentContext provides metadata about an agent.
@@ -35,6 +36,9 @@ type toolContext struct {
 	id      string
 	name    string
 	actions *maps.Map[string, any]
+	content slices.Slice[Part]
+	// generationConfig is the GenerationConfig of the calling invocation.
+	generationConfig *GenerationConfig
 }
 
 func (t *toolContext) ID() string {
@@ -49,3 +53,31 @@ func (t *toolContext) Actions() map[string]any {
 func (t *toolContext) SetAction(key string, value any) {
 	t.actions.Store(key, value)
 }
+func (t *toolContext) SaveArtifact(ctx context.Context, name string, data []byte, mimeType string) (int, error) {
+	return saveArtifact(ctx, name, data, MIMEType(mimeType))
+}
+func (t *toolContext) LoadArtifact(ctx context.Context, name string, version int) ([]byte, string, error) {
+	artifact, err := loadArtifact(ctx, name, version)
+	if err != nil {
+		return nil, "", err
+	}
+	return artifact.Data, string(artifact.MIMEType), nil
+}
+
+// AddToolContent attaches multimodal results, e.g. a DataPart with a rendered
+// image, to the tool call running in ctx. They are sent to the model with the
+// tool response. It returns ErrNoToolContext outside a tool call.
+func AddToolContent(ctx context.Context, parts ...Part) error {
+	tool, ok := tools.FromContext(ctx)
+	if !ok {
+		return ErrNoToolContext
+	}
+	t, ok := tool.(*toolContext)
+	if !ok {
+		return ErrNoToolContext
+	}
+	for _, part := range parts {
+		t.content.Append(part)
+	}
+	return nil
+}
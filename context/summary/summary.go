@@ -2,20 +2,25 @@ package summary
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/go-kratos/blades"
 	"github.com/go-kratos/blades/internal/counter"
 )
 
-// Session state keys used to persist compression state across runs.
+// Session state keys used to persist compression state across runs. They are
+// session scoped and reserved by the "blades:" prefix.
 // Values stored are primitive types (string and int) only.
 const (
 	// stateSummaryOffsetKey holds the number of messages from session.History()
 	// that have been folded into the rolling summary (int).
-	stateSummaryOffsetKey = "__summary_offset__"
+	stateSummaryOffsetKey = "blades:summary_offset"
 	// stateSummaryContentKey holds the rolling summary text (string).
-	stateSummaryContentKey = "__summary_content__"
+	stateSummaryContentKey = "blades:summary_content"
+	// stateSummaryMessageKey holds the ID of the last message folded into the
+	// rolling summary (string).
+	stateSummaryMessageKey = "blades:summary_message"
 )
 
 const (
@@ -122,7 +127,7 @@ func (s *contextCompressor) ensureSession(ctx context.Context) blades.Session {
 }
 
 // Compress compresses old messages if the total token count exceeds MaxTokens.
-// When a session is present in ctx it reads and writes two primitive-typed state
+// When a session is present in ctx it reads and writes three primitive-typed state
 // keys to persist incremental compression state across runs.
 func (s *contextCompressor) Compress(ctx context.Context, messages []*blades.Message) ([]*blades.Message, error) {
 	if len(messages) == 0 || s.maxTokens == 0 {
@@ -137,8 +142,9 @@ func (s *contextCompressor) Compress(ctx context.Context, messages []*blades.Mes
 	// Read persisted compression state from session (primitive types only).
 	offset := 0
 	summaryContent := ""
+	summaryMessage := ""
 	if v, ok := session.State()[stateSummaryOffsetKey]; ok {
-		if n, ok := v.(int); ok {
+		if n, ok := toInt(v); ok {
 			offset = n
 		}
 	}
@@ -147,8 +153,15 @@ func (s *contextCompressor) Compress(ctx context.Context, messages []*blades.Mes
 			summaryContent = c
 		}
 	}
-	// Guard against a stale offset if the session history was reset externally.
-	if offset > len(messages) {
+	if v, ok := session.State()[stateSummaryMessageKey]; ok {
+		if id, ok := v.(string); ok {
+			summaryMessage = id
+		}
+	}
+	// Guard against a stale summary if the session history was reset, rewound or
+	// truncated: the summary only applies while the last message it folded is
+	// still at its offset.
+	if offset > len(messages) || (offset > 0 && messages[offset-1].ID != summaryMessage) {
 		offset = 0
 		summaryContent = ""
 	}
@@ -179,10 +192,30 @@ func (s *contextCompressor) Compress(ctx context.Context, messages []*blades.Mes
 	// Persist updated state (primitive types: int and string).
 	session.SetState(stateSummaryOffsetKey, offset)
 	session.SetState(stateSummaryContentKey, summaryContent)
+	if offset > 0 {
+		session.SetState(stateSummaryMessageKey, messages[offset-1].ID)
+	}
 
 	return workingView, nil
 }
 
+// toInt converts a persisted offset to an int. Session stores that round-trip
+// state through JSON return numbers as float64 or json.Number.
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	}
+	return 0, false
+}
+
 // extendSummary calls the summarizer LLM to produce a new summary that covers
 // both the existing summary text and the provided message batch.
 func (s *contextCompressor) extendSummary(ctx context.Context, existing string, batch []*blades.Message) (string, error) {
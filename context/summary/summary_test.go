@@ -1,7 +1,9 @@
 package summary_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -93,14 +95,14 @@ func TestContextCompressor_SessionPersistsOffset(t *testing.T) {
 
 	// Offset and summary content must be persisted in session state.
 	state := session.State()
-	offsetVal, hasOffset := state["__summary_offset__"]
+	offsetVal, hasOffset := state["blades:summary_offset"]
 	if !hasOffset {
 		t.Fatal("offset key not set in session state after first Compress")
 	}
 	if offset, ok := offsetVal.(int); !ok || offset == 0 {
 		t.Errorf("offset = %v, want non-zero int", offsetVal)
 	}
-	if _, hasContent := state["__summary_content__"]; !hasContent {
+	if _, hasContent := state["blades:summary_content"]; !hasContent {
 		t.Fatal("summary content key not set in session state after first Compress")
 	}
 
@@ -115,13 +117,103 @@ func TestContextCompressor_SessionPersistsOffset(t *testing.T) {
 	_ = calls2 // may still compress if still over budget; key assertion is offset is reused
 
 	// The offset must not have regressed (it should only grow or stay the same).
-	newOffset, _ := session.State()["__summary_offset__"].(int)
+	newOffset, _ := session.State()["blades:summary_offset"].(int)
 	firstOffset, _ := offsetVal.(int)
 	if newOffset < firstOffset {
 		t.Errorf("offset regressed: %d < %d", newOffset, firstOffset)
 	}
 }
 
+func TestContextCompressor_RewoundSessionDropsStaleSummary(t *testing.T) {
+	s := &mockSummarizer{}
+	c := summary.NewContextCompressor(
+		s,
+		summary.WithMaxTokens(10),
+		summary.WithKeepRecent(2),
+		summary.WithBatchSize(3),
+		summary.WithTokenCounter(counter.NewCharBasedCounter()),
+	)
+
+	session := blades.NewSession()
+	ctx := blades.NewSessionContext(context.Background(), session)
+	for _, m := range makeMessages(8) {
+		if err := session.Append(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, _ := session.History(ctx)
+	if _, err := c.Compress(ctx, history); err != nil {
+		t.Fatal(err)
+	}
+	if offset, _ := session.State()["blades:summary_offset"].(int); offset < 4 {
+		t.Fatalf("offset = %d, want at least 4", offset)
+	}
+
+	// Rewind before the summarized messages and continue the conversation.
+	if err := blades.RewindSession(session, history[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 6 {
+		if err := session.Append(ctx, blades.UserMessage("new message "+string(rune('a'+i)))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, _ = session.History(ctx)
+	got, err := c.Compress(ctx, history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range got {
+		if strings.Contains(m.Text(), "number 3") {
+			t.Fatalf("working view holds a dropped message: %q", m.Text())
+		}
+	}
+}
+
+func TestContextCompressor_OffsetSurvivesJSONRoundTrip(t *testing.T) {
+	for _, useNumber := range []bool{false, true} {
+		s := &mockSummarizer{}
+		c := summary.NewContextCompressor(
+			s,
+			summary.WithMaxTokens(10),
+			summary.WithKeepRecent(2),
+			summary.WithBatchSize(3),
+			summary.WithTokenCounter(counter.NewCharBasedCounter()),
+		)
+		session := blades.NewSession()
+		msgs := makeMessages(8)
+		if _, err := c.Compress(blades.NewSessionContext(context.Background(), session), msgs); err != nil {
+			t.Fatal(err)
+		}
+		offset := session.State()["blades:summary_offset"].(int)
+
+		// Round-trip the state through JSON, as the file and SQLite stores do.
+		b, err := json.Marshal(session.State())
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(b))
+		if useNumber {
+			decoder.UseNumber()
+		}
+		var state blades.State
+		if err := decoder.Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		restored := blades.RestoreSession(&blades.SessionSnapshot{ID: session.ID(), State: state})
+		calls := s.calls
+		if _, err := c.Compress(blades.NewSessionContext(context.Background(), restored), msgs); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := restored.State()["blades:summary_offset"].(int); got != offset {
+			t.Errorf("useNumber=%v: offset = %v, want %d", useNumber, restored.State()["blades:summary_offset"], offset)
+		}
+		if s.calls != calls {
+			t.Errorf("useNumber=%v: summarizer called %d more times, want 0", useNumber, s.calls-calls)
+		}
+	}
+}
+
 // TestCompressor_NoSession_Stateless verifies that without a session the
 // compressor behaves statelessly (no state keys are set, no panic).
 func TestContextCompressor_NoSession_Stateless(t *testing.T) {
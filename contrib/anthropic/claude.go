@@ -3,10 +3,12 @@ package anthropic
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
 	"github.com/go-kratos/blades"
 )
 
@@ -67,7 +69,7 @@ func (m *Claude) Generate(ctx context.Context, req *blades.ModelRequest) (*blade
 	}
 	message, err := m.client.Messages.New(ctx, *params)
 	if err != nil {
-		return nil, fmt.Errorf("generating content: %w", err)
+		return nil, fmt.Errorf("generating content: %w", modelError(err))
 	}
 	return convertClaudeToBlades(message, blades.StatusCompleted)
 }
@@ -101,7 +103,7 @@ func (m *Claude) NewStreaming(ctx context.Context, req *blades.ModelRequest) bla
 			}
 		}
 		if err := streaming.Err(); err != nil {
-			yield(nil, err)
+			yield(nil, modelError(err))
 			return
 		}
 		finalResponse, err := convertClaudeToBlades(message, blades.StatusCompleted)
@@ -154,10 +156,12 @@ func (m *Claude) toClaudeParams(req *blades.ModelRequest) (*anthropic.MessageNew
 			)
 			for _, part := range msg.Parts {
 				switch v := any(part).(type) {
+				case blades.ReasoningPart:
+					assistantContent = append(assistantContent, convertReasoningToContent(v))
 				case blades.TextPart:
 					assistantContent = append(assistantContent, anthropic.NewTextBlock(v.Text))
 				case blades.ToolPart:
-					toolResults = append(toolResults, anthropic.NewToolResultBlock(v.ID, v.Response, false))
+					toolResults = append(toolResults, convertToolResultToContent(v))
 					assistantContent = append(assistantContent, anthropic.NewToolUseBlock(v.ID, decodeToolRequest(v.Request), v.Name))
 				}
 			}
@@ -176,12 +180,54 @@ func (m *Claude) toClaudeParams(req *blades.ModelRequest) (*anthropic.MessageNew
 		}
 		params.Tools = tools
 	}
+	applyGenerationConfig(params, req.GenerationConfig)
 	if m.config.CacheControl {
 		applyEphemeralCache(params)
 	}
 	return params, nil
 }
 
+// applyGenerationConfig overrides the configured parameters with the set
+// fields of the request's generation config. Claude has no seed parameter.
+func applyGenerationConfig(params *anthropic.MessageNewParams, config *blades.GenerationConfig) {
+	if config == nil {
+		return
+	}
+	if config.Temperature != nil {
+		params.Temperature = anthropic.Float(*config.Temperature)
+	}
+	if config.TopP != nil {
+		params.TopP = anthropic.Float(*config.TopP)
+	}
+	if config.MaxOutputTokens > 0 {
+		params.MaxTokens = config.MaxOutputTokens
+	}
+	if len(config.StopSequences) > 0 {
+		params.StopSequences = config.StopSequences
+	}
+	if len(params.Tools) == 0 || (config.ToolChoice == nil && config.ParallelToolCalls == nil) {
+		return
+	}
+	var disableParallel param.Opt[bool]
+	if config.ParallelToolCalls != nil {
+		disableParallel = anthropic.Bool(!*config.ParallelToolCalls)
+	}
+	mode := blades.ToolChoiceAuto
+	if config.ToolChoice != nil {
+		mode = config.ToolChoice.Mode
+	}
+	switch mode {
+	case blades.ToolChoiceAuto:
+		params.ToolChoice = anthropic.ToolChoiceUnionParam{OfAuto: &anthropic.ToolChoiceAutoParam{DisableParallelToolUse: disableParallel}}
+	case blades.ToolChoiceNone:
+		params.ToolChoice = anthropic.ToolChoiceUnionParam{OfNone: &anthropic.ToolChoiceNoneParam{}}
+	case blades.ToolChoiceRequired:
+		params.ToolChoice = anthropic.ToolChoiceUnionParam{OfAny: &anthropic.ToolChoiceAnyParam{DisableParallelToolUse: disableParallel}}
+	case blades.ToolChoiceTool:
+		params.ToolChoice = anthropic.ToolChoiceUnionParam{OfTool: &anthropic.ToolChoiceToolParam{Name: config.ToolChoice.Name, DisableParallelToolUse: disableParallel}}
+	}
+}
+
 // applyEphemeralCache stamps an ephemeral cache_control breakpoint on the last
 // block of each cacheable section: system, tools, and messages.
 func applyEphemeralCache(params *anthropic.MessageNewParams) {
@@ -210,3 +256,12 @@ func decodeToolRequest(request string) any {
 	}
 	return request
 }
+
+// modelError wraps an API error of the Anthropic SDK in a blades.ModelError.
+func modelError(err error) error {
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		return &blades.ModelError{StatusCode: apiErr.StatusCode, Err: err}
+	}
+	return err
+}
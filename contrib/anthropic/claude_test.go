@@ -229,3 +229,74 @@ func TestToClaudeParamsToolRole(t *testing.T) {
 		t.Fatalf("tool_result text block malformed: %v", resultContent[0])
 	}
 }
+
+func TestToClaudeParamsReplaysThinking(t *testing.T) {
+	t.Parallel()
+
+	model := &Claude{model: "claude-test"}
+	params, err := model.toClaudeParams(&blades.ModelRequest{
+		Messages: []*blades.Message{
+			{
+				Role: blades.RoleTool,
+				Parts: []blades.Part{
+					blades.ReasoningPart{Text: "Need the weather tool.", Signature: "sig_1"},
+					blades.ReasoningPart{Signature: "opaque", Redacted: true},
+					blades.ToolPart{ID: "toolu_1", Name: "get_weather", Request: `{}`, Response: `{}`, Completed: true},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toClaudeParams returned error: %v", err)
+	}
+	payload, err := json.Marshal(params.Messages[0].Content)
+	if err != nil {
+		t.Fatalf("marshal content: %v", err)
+	}
+	var blocks []map[string]any
+	if err := json.Unmarshal(payload, &blocks); err != nil {
+		t.Fatalf("unmarshal content: %v", err)
+	}
+	if got, want := len(blocks), 3; got != want {
+		t.Fatalf("blocks len = %d, want %d", got, want)
+	}
+	if blocks[0]["type"] != "thinking" || blocks[0]["signature"] != "sig_1" || blocks[0]["thinking"] != "Need the weather tool." {
+		t.Fatalf("thinking block malformed: %v", blocks[0])
+	}
+	if blocks[1]["type"] != "redacted_thinking" || blocks[1]["data"] != "opaque" {
+		t.Fatalf("redacted thinking block malformed: %v", blocks[1])
+	}
+	if blocks[2]["type"] != "tool_use" {
+		t.Fatalf("tool_use block malformed: %v", blocks[2])
+	}
+}
+
+func TestApplyGenerationConfig(t *testing.T) {
+	t.Parallel()
+
+	var (
+		temperature = 0.3
+		parallel    = false
+	)
+	tool := anthropic.ToolParam{Name: "route", InputSchema: anthropic.ToolInputSchemaParam{}}
+	params := &anthropic.MessageNewParams{MaxTokens: 1024, Tools: []anthropic.ToolUnionParam{{OfTool: &tool}}}
+	applyGenerationConfig(params, &blades.GenerationConfig{
+		Temperature:       &temperature,
+		MaxOutputTokens:   256,
+		ParallelToolCalls: &parallel,
+		ToolChoice:        &blades.ToolChoice{Mode: blades.ToolChoiceTool, Name: "route"},
+	})
+
+	if params.MaxTokens != 256 || params.Temperature.Value != 0.3 {
+		t.Fatalf("max_tokens = %d, temperature = %v", params.MaxTokens, params.Temperature)
+	}
+	choice := params.ToolChoice.OfTool
+	if choice == nil || choice.Name != "route" || !choice.DisableParallelToolUse.Value {
+		t.Fatalf("tool_choice = %+v", params.ToolChoice)
+	}
+
+	applyGenerationConfig(params, &blades.GenerationConfig{ToolChoice: &blades.ToolChoice{Mode: blades.ToolChoiceNone}})
+	if params.ToolChoice.OfNone == nil {
+		t.Fatalf("tool_choice = %+v, want none", params.ToolChoice)
+	}
+}
@@ -1,6 +1,7 @@
 package anthropic
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
@@ -9,11 +10,16 @@ import (
 	"github.com/go-kratos/blades/tools"
 )
 
+// mimePDF is the only document type Claude accepts in tool results.
+const mimePDF blades.MIMEType = "application/pdf"
+
 // convertPartsToContent converts Blades Parts to Claude ContentBlockParamUnion.
 func convertPartsToContent(parts []blades.Part) []anthropic.ContentBlockParamUnion {
 	var content []anthropic.ContentBlockParamUnion
 	for _, part := range parts {
 		switch p := part.(type) {
+		case blades.ReasoningPart:
+			content = append(content, convertReasoningToContent(p))
 		case blades.TextPart:
 			content = append(content, anthropic.NewTextBlock(p.Text))
 		}
@@ -21,6 +27,66 @@ func convertPartsToContent(parts []blades.Part) []anthropic.ContentBlockParamUni
 	return content
 }
 
+// convertReasoningToContent converts a Blades ReasoningPart back into the signed
+// (or redacted) thinking block Claude produced, so it can be replayed verbatim.
+func convertReasoningToContent(part blades.ReasoningPart) anthropic.ContentBlockParamUnion {
+	if part.Redacted {
+		return anthropic.NewRedactedThinkingBlock(part.Signature)
+	}
+	return anthropic.NewThinkingBlock(part.Signature, part.Text)
+}
+
+// convertToolResultToContent converts a completed Blades ToolPart into a Claude
+// tool_result block, carrying its images and PDF documents as native blocks.
+func convertToolResultToContent(part blades.ToolPart) anthropic.ContentBlockParamUnion {
+	result := anthropic.ToolResultBlockParam{ToolUseID: part.ID}
+	if part.Response != "" || len(part.Content) == 0 {
+		result.Content = append(result.Content, anthropic.ToolResultBlockParamContentUnion{
+			OfText: &anthropic.TextBlockParam{Text: part.Response},
+		})
+	}
+	for _, content := range part.Content {
+		switch v := content.(type) {
+		case blades.TextPart:
+			result.Content = append(result.Content, anthropic.ToolResultBlockParamContentUnion{
+				OfText: &anthropic.TextBlockParam{Text: v.Text},
+			})
+		case blades.DataPart:
+			data := base64.StdEncoding.EncodeToString(v.Bytes)
+			switch {
+			case v.MIMEType.Type() == "image":
+				result.Content = append(result.Content, anthropic.ToolResultBlockParamContentUnion{
+					OfImage: &anthropic.ImageBlockParam{Source: anthropic.ImageBlockParamSourceUnion{
+						OfBase64: &anthropic.Base64ImageSourceParam{Data: data, MediaType: anthropic.Base64ImageSourceMediaType(v.MIMEType)},
+					}},
+				})
+			case v.MIMEType == mimePDF:
+				result.Content = append(result.Content, anthropic.ToolResultBlockParamContentUnion{
+					OfDocument: &anthropic.DocumentBlockParam{Source: anthropic.DocumentBlockParamSourceUnion{
+						OfBase64: &anthropic.Base64PDFSourceParam{Data: data},
+					}},
+				})
+			}
+		case blades.FilePart:
+			switch {
+			case v.MIMEType.Type() == "image":
+				result.Content = append(result.Content, anthropic.ToolResultBlockParamContentUnion{
+					OfImage: &anthropic.ImageBlockParam{Source: anthropic.ImageBlockParamSourceUnion{
+						OfURL: &anthropic.URLImageSourceParam{URL: v.URI},
+					}},
+				})
+			case v.MIMEType == mimePDF:
+				result.Content = append(result.Content, anthropic.ToolResultBlockParamContentUnion{
+					OfDocument: &anthropic.DocumentBlockParam{Source: anthropic.DocumentBlockParamSourceUnion{
+						OfURL: &anthropic.URLPDFSourceParam{URL: v.URI},
+					}},
+				})
+			}
+		}
+	}
+	return anthropic.ContentBlockParamUnion{OfToolResult: &result}
+}
+
 // convertBladesToolsToClaude converts Blades Tools to Claude ToolParams.
 func convertBladesToolsToClaude(tools []tools.Tool) ([]anthropic.ToolUnionParam, error) {
 	var claudeTools []anthropic.ToolUnionParam
@@ -55,6 +121,10 @@ func convertClaudeToBlades(message *anthropic.Message, status blades.Status) (*b
 		switch b := block.AsAny().(type) {
 		case anthropic.TextBlock:
 			msg.Parts = append(msg.Parts, blades.TextPart{Text: b.Text})
+		case anthropic.ThinkingBlock:
+			msg.Parts = append(msg.Parts, blades.ReasoningPart{Text: b.Thinking, Signature: b.Signature})
+		case anthropic.RedactedThinkingBlock:
+			msg.Parts = append(msg.Parts, blades.ReasoningPart{Signature: b.Data, Redacted: true})
 		case anthropic.ToolUseBlock:
 			hasToolUse = true
 			input, err := json.Marshal(b.Input)
@@ -78,6 +148,8 @@ func convertStreamDeltaToBlades(event anthropic.ContentBlockDeltaEvent) (*blades
 	switch delta := event.Delta.AsAny().(type) {
 	case anthropic.TextDelta:
 		message.Parts = append(message.Parts, blades.TextPart{Text: delta.Text})
+	case anthropic.ThinkingDelta:
+		message.Parts = append(message.Parts, blades.ReasoningPart{Text: delta.Thinking})
 	}
 	return &blades.ModelResponse{
 		Message: message,
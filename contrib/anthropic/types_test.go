@@ -120,6 +120,47 @@ func TestConvertClaudeToBladesTextAndToolUse(t *testing.T) {
 	}
 }
 
+func TestConvertClaudeToBladesThinking(t *testing.T) {
+	t.Parallel()
+
+	message := decodeAnthropicMessage(t, `{
+		"id": "msg_3",
+		"content": [
+			{"type":"thinking","thinking":"Need the weather tool.","signature":"sig_1"},
+			{"type":"redacted_thinking","data":"opaque"},
+			{"type":"tool_use","id":"toolu_3","name":"get_weather","input":{"city":"Oslo"}}
+		],
+		"model": "claude-sonnet-4-20250514",
+		"role": "assistant",
+		"stop_reason": "tool_use",
+		"stop_sequence": "",
+		"type": "message",
+		"usage": {"input_tokens": 1, "output_tokens": 1}
+	}`)
+
+	response, err := convertClaudeToBlades(message, blades.StatusCompleted)
+	if err != nil {
+		t.Fatalf("convertClaudeToBlades returned error: %v", err)
+	}
+	if got, want := len(response.Message.Parts), 3; got != want {
+		t.Fatalf("parts len = %d, want %d", got, want)
+	}
+	thinking, ok := response.Message.Parts[0].(blades.ReasoningPart)
+	if !ok {
+		t.Fatalf("first part type = %T, want blades.ReasoningPart", response.Message.Parts[0])
+	}
+	if thinking.Text != "Need the weather tool." || thinking.Signature != "sig_1" || thinking.Redacted {
+		t.Fatalf("thinking part = %+v", thinking)
+	}
+	redacted, ok := response.Message.Parts[1].(blades.ReasoningPart)
+	if !ok {
+		t.Fatalf("second part type = %T, want blades.ReasoningPart", response.Message.Parts[1])
+	}
+	if redacted.Signature != "opaque" || !redacted.Redacted {
+		t.Fatalf("redacted part = %+v", redacted)
+	}
+}
+
 func decodeAnthropicMessage(t *testing.T, data string) *anthropicSDK.Message {
 	t.Helper()
 
@@ -129,3 +170,44 @@ func decodeAnthropicMessage(t *testing.T, data string) *anthropicSDK.Message {
 	}
 	return &message
 }
+
+func TestConvertToolResultToContentMultimodal(t *testing.T) {
+	t.Parallel()
+
+	block := convertToolResultToContent(blades.ToolPart{
+		ID:       "toolu_1",
+		Name:     "screenshot",
+		Response: "captured",
+		Content: []blades.Part{
+			blades.DataPart{Bytes: []byte("png"), MIMEType: blades.MIMEImagePNG},
+			blades.FilePart{URI: "https://example.com/report.pdf", MIMEType: "application/pdf"},
+		},
+	})
+	payload, err := json.Marshal(block)
+	if err != nil {
+		t.Fatalf("marshal block: %v", err)
+	}
+	var result struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Type   string         `json:"type"`
+			Text   string         `json:"text"`
+			Source map[string]any `json:"source"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		t.Fatalf("unmarshal block: %v", err)
+	}
+	if result.Type != "tool_result" || len(result.Content) != 3 {
+		t.Fatalf("tool result = %s", payload)
+	}
+	if got := result.Content[0]; got.Type != "text" || got.Text != "captured" {
+		t.Fatalf("content[0] = %+v", got)
+	}
+	if got := result.Content[1]; got.Type != "image" || got.Source["media_type"] != "image/png" || got.Source["data"] != "cG5n" {
+		t.Fatalf("content[1] = %+v", got)
+	}
+	if got := result.Content[2]; got.Type != "document" || got.Source["url"] != "https://example.com/report.pdf" {
+		t.Fatalf("content[2] = %+v", got)
+	}
+}
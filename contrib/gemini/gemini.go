@@ -2,6 +2,7 @@ package gemini
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/go-kratos/blades"
@@ -59,7 +60,7 @@ func (m *Gemini) Generate(ctx context.Context, req *blades.ModelRequest) (*blade
 	config.SystemInstruction = system
 	resp, err := m.client.Models.GenerateContent(ctx, m.model, contents, config)
 	if err != nil {
-		return nil, err
+		return nil, modelError(err)
 	}
 	return convertGenAIToBlades(resp, blades.StatusCompleted)
 }
@@ -100,9 +101,50 @@ func (m *Gemini) toGenerateConfig(req *blades.ModelRequest) (*genai.GenerateCont
 		}
 		config.Tools = tools
 	}
+	applyGenerationConfig(&config, req.GenerationConfig)
 	return &config, nil
 }
 
+// applyGenerationConfig overrides the model defaults with the request's
+// generation parameters. Gemini has no switch for parallel tool calls, so
+// ParallelToolCalls is ignored.
+func applyGenerationConfig(config *genai.GenerateContentConfig, gc *blades.GenerationConfig) {
+	if gc == nil {
+		return
+	}
+	if gc.Temperature != nil {
+		config.Temperature = genai.Ptr(float32(*gc.Temperature))
+	}
+	if gc.TopP != nil {
+		config.TopP = genai.Ptr(float32(*gc.TopP))
+	}
+	if gc.MaxOutputTokens > 0 {
+		config.MaxOutputTokens = int32(gc.MaxOutputTokens)
+	}
+	if gc.StopSequences != nil {
+		config.StopSequences = gc.StopSequences
+	}
+	if gc.Seed != nil {
+		config.Seed = genai.Ptr(int32(*gc.Seed))
+	}
+	if gc.ToolChoice == nil {
+		return
+	}
+	calling := &genai.FunctionCallingConfig{}
+	switch gc.ToolChoice.Mode {
+	case blades.ToolChoiceNone:
+		calling.Mode = genai.FunctionCallingConfigModeNone
+	case blades.ToolChoiceRequired:
+		calling.Mode = genai.FunctionCallingConfigModeAny
+	case blades.ToolChoiceTool:
+		calling.Mode = genai.FunctionCallingConfigModeAny
+		calling.AllowedFunctionNames = []string{gc.ToolChoice.Name}
+	default:
+		calling.Mode = genai.FunctionCallingConfigModeAuto
+	}
+	config.ToolConfig = &genai.ToolConfig{FunctionCallingConfig: calling}
+}
+
 // NewStreaming is an alias for GenerateStream to implement the ModelProvider interface.
 func (m *Gemini) NewStreaming(ctx context.Context, req *blades.ModelRequest) blades.Generator[*blades.ModelResponse, error] {
 	return func(yield func(*blades.ModelResponse, error) bool) {
@@ -121,7 +163,7 @@ func (m *Gemini) NewStreaming(ctx context.Context, req *blades.ModelRequest) bla
 		var accumulatedResponse *genai.GenerateContentResponse
 		for chunk, err := range streaming {
 			if err != nil {
-				yield(nil, err)
+				yield(nil, modelError(err))
 				return
 			}
 			response, err := convertGenAIToBlades(chunk, blades.StatusIncomplete)
@@ -164,3 +206,12 @@ func (m *Gemini) NewStreaming(ctx context.Context, req *blades.ModelRequest) bla
 		}
 	}
 }
+
+// modelError wraps an API error of the genai SDK in a blades.ModelError.
+func modelError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return &blades.ModelError{StatusCode: apiErr.Code, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package gemini
+
+import (
+	"testing"
+
+	"github.com/go-kratos/blades"
+	"google.golang.org/genai"
+)
+
+func TestApplyGenerationConfig(t *testing.T) {
+	t.Parallel()
+
+	var (
+		temperature = 0.5
+		seed        = int64(7)
+	)
+	config := genai.GenerateContentConfig{Temperature: genai.Ptr(float32(1))}
+	applyGenerationConfig(&config, &blades.GenerationConfig{
+		Temperature:     &temperature,
+		MaxOutputTokens: 128,
+		Seed:            &seed,
+		ToolChoice:      &blades.ToolChoice{Mode: blades.ToolChoiceTool, Name: "route"},
+	})
+
+	if *config.Temperature != 0.5 || config.MaxOutputTokens != 128 || *config.Seed != 7 {
+		t.Fatalf("config = %+v", config)
+	}
+	calling := config.ToolConfig.FunctionCallingConfig
+	if calling.Mode != genai.FunctionCallingConfigModeAny || len(calling.AllowedFunctionNames) != 1 || calling.AllowedFunctionNames[0] != "route" {
+		t.Fatalf("function calling = %+v", calling)
+	}
+
+	applyGenerationConfig(&config, &blades.GenerationConfig{ToolChoice: &blades.ToolChoice{Mode: blades.ToolChoiceNone}})
+	if config.ToolConfig.FunctionCallingConfig.Mode != genai.FunctionCallingConfigModeNone {
+		t.Fatalf("mode = %v, want NONE", config.ToolConfig.FunctionCallingConfig.Mode)
+	}
+}
@@ -1,6 +1,7 @@
 package gemini
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
@@ -26,6 +27,9 @@ func convertMessageToGenAI(req *blades.ModelRequest) (*genai.Content, []*genai.C
 		case blades.RoleAssistant:
 			contents = append(contents, &genai.Content{Role: genai.RoleModel, Parts: convertMessagePartsToGenAI(msg.Parts)})
 		case blades.RoleTool:
+			// Replay the model turn (thoughts, text and function calls) before the
+			// function responses so thought signatures survive tool loops.
+			contents = append(contents, &genai.Content{Role: genai.RoleModel, Parts: convertMessagePartsToGenAI(msg.Parts)})
 			var parts []*genai.Part
 			for _, part := range msg.Parts {
 				switch v := any(part).(type) {
@@ -34,7 +38,9 @@ func convertMessageToGenAI(req *blades.ModelRequest) (*genai.Content, []*genai.C
 					if err := json.Unmarshal([]byte(v.Response), &response); err != nil {
 						response["output"] = v.Response
 					}
-					parts = append(parts, genai.NewPartFromFunctionResponse(v.Name, response))
+					part := genai.NewPartFromFunctionResponse(v.Name, response)
+					part.FunctionResponse.Parts = convertToolContentToGenAI(v.Content)
+					parts = append(parts, part)
 				}
 			}
 			contents = append(contents, &genai.Content{Role: genai.RoleUser, Parts: parts})
@@ -43,12 +49,59 @@ func convertMessageToGenAI(req *blades.ModelRequest) (*genai.Content, []*genai.C
 	return system, contents, nil
 }
 
+// convertToolContentToGenAI converts the multimodal content of a tool result
+// into function response parts. Other content types are dropped.
+func convertToolContentToGenAI(content []blades.Part) []*genai.FunctionResponsePart {
+	var res []*genai.FunctionResponsePart
+	for _, part := range content {
+		switch v := part.(type) {
+		case blades.DataPart:
+			res = append(res, &genai.FunctionResponsePart{
+				InlineData: &genai.FunctionResponseBlob{
+					Data:     v.Bytes,
+					MIMEType: string(v.MIMEType),
+				},
+			})
+		case blades.FilePart:
+			res = append(res, &genai.FunctionResponsePart{
+				FileData: &genai.FunctionResponseFileData{
+					FileURI:  v.URI,
+					MIMEType: string(v.MIMEType),
+				},
+			})
+		}
+	}
+	return res
+}
+
 func convertMessagePartsToGenAI(parts []blades.Part) []*genai.Part {
-	res := make([]*genai.Part, 0, len(parts))
+	var (
+		res       = make([]*genai.Part, 0, len(parts))
+		signature []byte // signature of a text-less ReasoningPart, owed to the next part
+	)
 	for _, part := range parts {
 		switch v := part.(type) {
+		case blades.ReasoningPart:
+			if v.Text == "" {
+				signature = decodeThoughtSignature(v.Signature)
+				continue
+			}
+			res = append(res, &genai.Part{
+				Text:             v.Text,
+				Thought:          true,
+				ThoughtSignature: decodeThoughtSignature(v.Signature),
+			})
+			continue
 		case blades.TextPart:
 			res = append(res, &genai.Part{Text: v.Text})
+		case blades.ToolPart:
+			args := map[string]any{}
+			if err := json.Unmarshal([]byte(v.Request), &args); err != nil {
+				args["input"] = v.Request
+			}
+			res = append(res, &genai.Part{
+				FunctionCall: &genai.FunctionCall{ID: v.ID, Name: v.Name, Args: args},
+			})
 		case blades.DataPart:
 			res = append(res, &genai.Part{
 				InlineData: &genai.Blob{
@@ -65,11 +118,39 @@ func convertMessagePartsToGenAI(parts []blades.Part) []*genai.Part {
 					MIMEType:    string(v.MIMEType),
 				},
 			})
+		default:
+			continue
+		}
+		if signature != nil {
+			res[len(res)-1].ThoughtSignature = signature
+			signature = nil
 		}
 	}
 	return res
 }
 
+// encodeThoughtSignature converts a Gemini thought signature to the string form
+// stored in blades.ReasoningPart.
+func encodeThoughtSignature(signature []byte) string {
+	if len(signature) == 0 {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// decodeThoughtSignature reverses encodeThoughtSignature. Signatures that are
+// not valid base64 (e.g. produced by another provider) are dropped.
+func decodeThoughtSignature(signature string) []byte {
+	if signature == "" {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil
+	}
+	return decoded
+}
+
 func convertBladesToolsToGenAI(tools []tools.Tool) ([]*genai.Tool, error) {
 	genaiTools := make([]*genai.Tool, 0, len(tools))
 	for _, tool := range tools {
@@ -105,6 +186,20 @@ func convertGenAIToBlades(resp *genai.GenerateContentResponse, status blades.Sta
 			continue
 		}
 		for _, part := range candidate.Content.Parts {
+			if part.Thought {
+				message.Parts = append(message.Parts, blades.ReasoningPart{
+					Text:      part.Text,
+					Signature: encodeThoughtSignature(part.ThoughtSignature),
+				})
+				continue
+			}
+			if len(part.ThoughtSignature) > 0 {
+				// Gemini attaches signatures to regular parts (typically function
+				// calls); keep them in a text-less ReasoningPart just before it.
+				message.Parts = append(message.Parts, blades.ReasoningPart{
+					Signature: encodeThoughtSignature(part.ThoughtSignature),
+				})
+			}
 			bladesPart, err := convertGenAIPartToBlades(part)
 			if err != nil {
 				return nil, err
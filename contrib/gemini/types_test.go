@@ -31,6 +31,66 @@ func TestConvertMessageToGenAI_AssistantRole(t *testing.T) {
 	}
 }
 
+func TestConvertMessageToGenAI_MultimodalToolResult(t *testing.T) {
+	t.Parallel()
+
+	_, contents, err := convertMessageToGenAI(&blades.ModelRequest{
+		Messages: []*blades.Message{{
+			Role: blades.RoleTool,
+			Parts: []blades.Part{blades.ToolPart{
+				ID:        "call_1",
+				Name:      "screenshot",
+				Response:  `{"ok":true}`,
+				Completed: true,
+				Content: []blades.Part{
+					blades.DataPart{Bytes: []byte("png"), MIMEType: blades.MIMEImagePNG},
+					blades.FilePart{URI: "gs://bucket/page.pdf", MIMEType: "application/pdf"},
+				},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("convertMessageToGenAI returned error: %v", err)
+	}
+	if got, want := len(contents), 2; got != want {
+		t.Fatalf("contents len = %d, want %d", got, want)
+	}
+	response := contents[1].Parts[0].FunctionResponse
+	if response == nil || len(response.Parts) != 2 {
+		t.Fatalf("function response = %+v, want 2 parts", response)
+	}
+	if blob := response.Parts[0].InlineData; blob == nil || string(blob.Data) != "png" || blob.MIMEType != "image/png" {
+		t.Fatalf("inline data = %+v", blob)
+	}
+	if file := response.Parts[1].FileData; file == nil || file.FileURI != "gs://bucket/page.pdf" {
+		t.Fatalf("file data = %+v", file)
+	}
+}
+
+func TestConvertMessageToGenAI_MediaPartsKeepDisplayName(t *testing.T) {
+	t.Parallel()
+
+	_, contents, err := convertMessageToGenAI(&blades.ModelRequest{
+		Messages: []*blades.Message{blades.UserMessage(
+			blades.DataPart{Name: "shot.png", Bytes: []byte("png"), MIMEType: blades.MIMEImagePNG},
+			blades.FilePart{Name: "page.pdf", URI: "gs://bucket/page.pdf", MIMEType: "application/pdf"},
+		)},
+	})
+	if err != nil {
+		t.Fatalf("convertMessageToGenAI returned error: %v", err)
+	}
+	parts := contents[0].Parts
+	if got, want := len(parts), 2; got != want {
+		t.Fatalf("parts len = %d, want %d", got, want)
+	}
+	if blob := parts[0].InlineData; blob == nil || blob.DisplayName != "shot.png" {
+		t.Fatalf("inline data = %+v", blob)
+	}
+	if file := parts[1].FileData; file == nil || file.DisplayName != "page.pdf" {
+		t.Fatalf("file data = %+v", file)
+	}
+}
+
 func TestConvertGenAIToBlades_FunctionCallMappedToToolPart(t *testing.T) {
 	t.Parallel()
 
@@ -137,3 +197,66 @@ func TestConvertGenAIToBlades_MixedTextAndFunctionCallUsesToolRole(t *testing.T)
 		t.Fatalf("tool completed = %t, want %t", got, want)
 	}
 }
+
+func TestConvertGenAIToBlades_ThoughtsAndSignatures(t *testing.T) {
+	t.Parallel()
+
+	resp := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			{
+				Content: &genai.Content{
+					Parts: []*genai.Part{
+						{Text: "Need the weather.", Thought: true},
+						{
+							FunctionCall:     &genai.FunctionCall{ID: "call_1", Name: "get_weather", Args: map[string]any{"city": "Oslo"}},
+							ThoughtSignature: []byte("sig"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	converted, err := convertGenAIToBlades(resp, blades.StatusCompleted)
+	if err != nil {
+		t.Fatalf("convertGenAIToBlades returned error: %v", err)
+	}
+	if got, want := len(converted.Message.Parts), 3; got != want {
+		t.Fatalf("parts len = %d, want %d", got, want)
+	}
+	thought, ok := converted.Message.Parts[0].(blades.ReasoningPart)
+	if !ok || thought.Text != "Need the weather." {
+		t.Fatalf("first part = %#v, want reasoning text", converted.Message.Parts[0])
+	}
+	signature, ok := converted.Message.Parts[1].(blades.ReasoningPart)
+	if !ok || signature.Text != "" || signature.Signature == "" {
+		t.Fatalf("second part = %#v, want text-less signed reasoning", converted.Message.Parts[1])
+	}
+
+	// Replaying the tool message must put the signature back on the function call.
+	toolMessage := converted.Message
+	toolMessage.Parts[2] = blades.ToolPart{ID: "call_1", Name: "get_weather", Request: `{"city":"Oslo"}`, Response: `{"temp":3}`, Completed: true}
+	_, contents, err := convertMessageToGenAI(&blades.ModelRequest{Messages: []*blades.Message{toolMessage}})
+	if err != nil {
+		t.Fatalf("convertMessageToGenAI returned error: %v", err)
+	}
+	if got, want := len(contents), 2; got != want {
+		t.Fatalf("contents len = %d, want %d", got, want)
+	}
+	model := contents[0]
+	if got, want := model.Role, genai.RoleModel; got != want {
+		t.Fatalf("first role = %q, want %q", got, want)
+	}
+	if got, want := len(model.Parts), 2; got != want {
+		t.Fatalf("model parts len = %d, want %d", got, want)
+	}
+	if !model.Parts[0].Thought || model.Parts[0].Text != "Need the weather." {
+		t.Fatalf("model thought part = %+v", model.Parts[0])
+	}
+	if model.Parts[1].FunctionCall == nil || string(model.Parts[1].ThoughtSignature) != "sig" {
+		t.Fatalf("model function call part = %+v", model.Parts[1])
+	}
+	if contents[1].Parts[0].FunctionResponse == nil {
+		t.Fatalf("expected function response in second content, got %+v", contents[1].Parts[0])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"net/http"
 	"os"
 	"os/exec"
@@ -28,6 +29,9 @@ type Client struct {
 	connectCtx    context.Context
 	connectCancel context.CancelFunc
 	reconnecting  atomic.Bool
+	// Progress listeners of the running tool calls, by progress token
+	progress      sync.Map
+	progressToken atomic.Int64
 }
 
 // NewClient creates a new MCP client.
@@ -38,14 +42,13 @@ func NewClient(config ClientConfig) (*Client, error) {
 	if err := config.validate(); err != nil {
 		return nil, err
 	}
-	client := mcp.NewClient(&mcp.Implementation{
+	c := &Client{config: config}
+	c.client = mcp.NewClient(&mcp.Implementation{
 		Name:    config.Name,
 		Version: blades.Version,
-	}, nil)
-	c := &Client{
-		config: config,
-		client: client,
-	}
+	}, &mcp.ClientOptions{
+		ProgressNotificationHandler: c.notifyProgress,
+	})
 	c.connectCtx, c.connectCancel = context.WithCancel(context.Background())
 	return c, nil
 }
@@ -145,7 +148,8 @@ func (c *Client) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 	return result.Tools, nil
 }
 
-// Resolve implements the tools.Resolver interface.
+// Resolve implements the tools.Resolver interface. The tools implement
+// tools.StreamingTool, reporting the progress notifications of the server.
 func (c *Client) Resolve(ctx context.Context) ([]tools.Tool, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
@@ -155,8 +159,7 @@ func (c *Client) Resolve(ctx context.Context) ([]tools.Tool, error) {
 	}
 	var res []tools.Tool
 	for _, mcpTool := range mcpTools {
-		handler := c.handler(mcpTool.Name)
-		tool, err := toBladesTool(mcpTool, handler)
+		tool, err := toBladesTool(mcpTool, c.handler(mcpTool.Name), tools.WithStream(c.streamHandler(mcpTool.Name)))
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert MCP tool [%s]: %w", mcpTool.Name, err)
 		}
@@ -168,24 +171,89 @@ func (c *Client) Resolve(ctx context.Context) ([]tools.Tool, error) {
 // handler returns a tool handler that calls the MCP tool.
 func (c *Client) handler(name string) tools.HandleFunc {
 	return func(ctx context.Context, input string) (string, error) {
-		var arguments map[string]any
-		if err := json.Unmarshal([]byte(input), &arguments); err != nil {
-			return "", fmt.Errorf("invalid input JSON: %w", err)
-		}
-		result, err := c.CallTool(ctx, name, arguments)
-		if err != nil {
-			return "", err
+		return c.handle(ctx, name, input, nil)
+	}
+}
+
+// streamHandler returns a tool stream handler that calls the MCP tool and
+// reports the progress notifications the server sends for the call.
+func (c *Client) streamHandler(name string) func(context.Context, string) iter.Seq2[*tools.Update, error] {
+	return func(ctx context.Context, input string) iter.Seq2[*tools.Update, error] {
+		return func(yield func(*tools.Update, error) bool) {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			var (
+				updates = make(chan *tools.Update)
+				done    = make(chan struct{})
+				output  string
+				err     error
+			)
+			go func() {
+				defer close(done)
+				output, err = c.handle(ctx, name, input, func(params *mcp.ProgressNotificationParams) {
+					update := &tools.Update{Message: params.Message}
+					if params.Total > 0 {
+						update.Progress = params.Progress / params.Total
+					}
+					select {
+					case updates <- update:
+					case <-ctx.Done():
+					}
+				})
+			}()
+			for {
+				select {
+				case update := <-updates:
+					if !yield(update, nil) {
+						cancel()
+						<-done
+						return
+					}
+				case <-done:
+					if err != nil {
+						yield(nil, err)
+						return
+					}
+					yield(&tools.Update{Output: output, Progress: 1}, nil)
+					return
+				}
+			}
 		}
-		output, err := formatToolResult(result)
-		if err != nil {
-			return "", fmt.Errorf("failed to format tool result: %w", err)
+	}
+}
+
+// handle calls the MCP tool with the JSON input and formats its result,
+// passing the progress notifications of the call to onProgress if set.
+func (c *Client) handle(ctx context.Context, name, input string, onProgress func(*mcp.ProgressNotificationParams)) (string, error) {
+	var arguments map[string]any
+	if err := json.Unmarshal([]byte(input), &arguments); err != nil {
+		return "", fmt.Errorf("invalid input JSON: %w", err)
+	}
+	result, err := c.callTool(ctx, name, arguments, onProgress)
+	if err != nil {
+		return "", err
+	}
+	// Hand images and audio to the model as tool content instead of JSON text.
+	if media, rest := splitMediaContent(result.Content); len(media) > 0 && !result.IsError {
+		if err := blades.AddToolContent(ctx, media...); err == nil {
+			stripped := *result
+			stripped.Content = rest
+			result = &stripped
 		}
-		return output, nil
 	}
+	output, err := formatToolResult(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to format tool result: %w", err)
+	}
+	return output, nil
 }
 
 // CallTool calls a tool on the server.
 func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	return c.callTool(ctx, name, arguments, nil)
+}
+
+func (c *Client) callTool(ctx context.Context, name string, arguments map[string]any, onProgress func(*mcp.ProgressNotificationParams)) (*mcp.CallToolResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
 	if !c.connected.Load() {
@@ -193,16 +261,33 @@ func (c *Client) CallTool(ctx context.Context, name string, arguments map[string
 			return nil, err
 		}
 	}
-	result, err := c.session.CallTool(ctx, &mcp.CallToolParams{
+	params := &mcp.CallToolParams{
 		Name:      name,
 		Arguments: arguments,
-	})
+	}
+	if onProgress != nil {
+		token := fmt.Sprintf("%s-%d", c.config.Name, c.progressToken.Add(1))
+		// SetProgressToken drops the token of params without metadata.
+		params.Meta = mcp.Meta{}
+		params.SetProgressToken(token)
+		c.progress.Store(token, onProgress)
+		defer c.progress.Delete(token)
+	}
+	result, err := c.session.CallTool(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("mcp [%s] call_tool: %w", c.config.Name, err)
 	}
 	return result, nil
 }
 
+// notifyProgress passes a progress notification of the server to the
+// listener of the tool call it belongs to.
+func (c *Client) notifyProgress(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+	if listener, ok := c.progress.Load(req.Params.ProgressToken); ok {
+		listener.(func(*mcp.ProgressNotificationParams))(req.Params)
+	}
+}
+
 // Close closes the client connection.
 func (c *Client) Close() error {
 	if c.connectCancel != nil {
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-kratos/blades/tools"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -68,3 +69,67 @@ func TestReconnectStopsOnContextCancel(t *testing.T) {
 		t.Fatal("reconnect did not stop after context cancellation")
 	}
 }
+
+func TestToolStreamsProgressNotifications(t *testing.T) {
+	t.Parallel()
+
+	// The server answers once the progress reached the consumer, since the
+	// client handles notifications concurrently with responses.
+	release := make(chan struct{})
+	server := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "server", Version: "v0.0.1"}, nil)
+	sdkmcp.AddTool(server, &sdkmcp.Tool{Name: "work"}, func(ctx context.Context, req *sdkmcp.CallToolRequest, _ any) (*sdkmcp.CallToolResult, any, error) {
+		if token := req.Params.GetProgressToken(); token != nil {
+			for i := range 2 {
+				req.Session.NotifyProgress(ctx, &sdkmcp.ProgressNotificationParams{
+					ProgressToken: token,
+					Message:       "working",
+					Progress:      float64(i + 1),
+					Total:         4,
+				})
+			}
+			<-release
+		}
+		return &sdkmcp.CallToolResult{Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "done"}}}, nil, nil
+	})
+	ctx := context.Background()
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client, err := NewClient(ClientConfig{Name: "test", Transport: TransportStdio, Command: "cat"})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	session, err := client.client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+	client.session = session
+	client.connected.Store(true)
+
+	resolved, err := client.Resolve(ctx)
+	if err != nil || len(resolved) != 1 {
+		t.Fatalf("resolve = %v, %v", resolved, err)
+	}
+	streaming, ok := resolved[0].(tools.StreamingTool)
+	if !ok {
+		t.Fatalf("tool %T does not stream", resolved[0])
+	}
+	var updates []*tools.Update
+	for update, err := range streaming.HandleStream(ctx, `{}`) {
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		updates = append(updates, update)
+		if len(updates) == 2 {
+			close(release)
+		}
+	}
+	if len(updates) != 3 || updates[0].Progress != 0.25 || updates[1].Message != "working" {
+		t.Fatalf("updates = %+v", updates)
+	}
+	if last := updates[2]; last.Progress != 1 || !strings.Contains(last.Output, "done") {
+		t.Fatalf("final update = %+v", last)
+	}
+}
@@ -73,7 +73,7 @@ func (r *ToolsResolver) Resolve(ctx context.Context) ([]tools.Tool, error) {
 		// Convert MCP tools to Blades tools using client's built-in conversion
 		for _, mcpTool := range mcpTools {
 			handler := client.handler(mcpTool.Name)
-			tool, err := toBladesTool(mcpTool, handler)
+			tool, err := toBladesTool(mcpTool, handler, tools.WithStream(client.streamHandler(mcpTool.Name)))
 			if err != nil {
 				errors = append(errors, fmt.Errorf("failed to convert MCP tool [%s]: %w", mcpTool.Name, err))
 				continue
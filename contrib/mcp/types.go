@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/go-kratos/blades"
 	"github.com/go-kratos/blades/tools"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -11,7 +12,7 @@ import (
 
 // toBladesTool converts an MCP tool to a Blades tool.
 // This method is used by Provider to convert tools without creating separate Adapter instances.
-func toBladesTool(mcpTool *mcp.Tool, handler tools.HandleFunc) (tools.Tool, error) {
+func toBladesTool(mcpTool *mcp.Tool, handler tools.HandleFunc, opts ...tools.Option) (tools.Tool, error) {
 	// Convert the input schema
 	inputSchema, err := convertSchema(mcpTool.InputSchema)
 	if err != nil {
@@ -25,13 +26,11 @@ func toBladesTool(mcpTool *mcp.Tool, handler tools.HandleFunc) (tools.Tool, erro
 			return nil, fmt.Errorf("failed to convert output schema: %w", err)
 		}
 	}
-	return tools.NewTool(
-		mcpTool.Name,
-		mcpTool.Description,
-		handler,
+	opts = append([]tools.Option{
 		tools.WithInputSchema(inputSchema),
 		tools.WithOutputSchema(outputSchema),
-	), nil
+	}, opts...)
+	return tools.NewTool(mcpTool.Name, mcpTool.Description, handler, opts...), nil
 }
 
 // convertSchema converts an MCP schema to a Blades jsonschema.Schema.
@@ -58,6 +57,26 @@ func convertSchema(mcpSchema any) (*jsonschema.Schema, error) {
 	return &schema, nil
 }
 
+// splitMediaContent separates the image and audio content of an MCP tool
+// result, converted to Blades DataParts, from the rest of the content.
+func splitMediaContent(content []mcp.Content) ([]blades.Part, []mcp.Content) {
+	var (
+		media []blades.Part
+		rest  = make([]mcp.Content, 0, len(content))
+	)
+	for _, c := range content {
+		switch v := c.(type) {
+		case *mcp.ImageContent:
+			media = append(media, blades.DataPart{Bytes: v.Data, MIMEType: blades.MIMEType(v.MIMEType)})
+		case *mcp.AudioContent:
+			media = append(media, blades.DataPart{Bytes: v.Data, MIMEType: blades.MIMEType(v.MIMEType)})
+		default:
+			rest = append(rest, c)
+		}
+	}
+	return media, rest
+}
+
 // formatToolResult converts MCP CallToolResult to a JSON string.
 func formatToolResult(result *mcp.CallToolResult) (string, error) {
 	// Check if the tool execution failed
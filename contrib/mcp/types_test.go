@@ -0,0 +1,25 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/go-kratos/blades"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSplitMediaContent(t *testing.T) {
+	media, rest := splitMediaContent([]sdkmcp.Content{
+		&sdkmcp.TextContent{Text: "chart rendered"},
+		&sdkmcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"},
+	})
+	if len(media) != 1 || len(rest) != 1 {
+		t.Fatalf("media = %v, rest = %v", media, rest)
+	}
+	image, ok := media[0].(blades.DataPart)
+	if !ok || string(image.Bytes) != "png" || image.MIMEType != blades.MIMEImagePNG {
+		t.Fatalf("media[0] = %#v", media[0])
+	}
+	if text, ok := rest[0].(*sdkmcp.TextContent); !ok || text.Text != "chart rendered" {
+		t.Fatalf("rest[0] = %#v", rest[0])
+	}
+}
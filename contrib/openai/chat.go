@@ -4,13 +4,17 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/go-kratos/blades"
 	"github.com/go-kratos/blades/tools"
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/packages/respjson"
 	"github.com/openai/openai-go/v3/shared"
 )
 
@@ -68,7 +72,7 @@ func (m *chatModel) Generate(ctx context.Context, req *blades.ModelRequest) (*bl
 	}
 	chatResponse, err := m.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		return nil, err
+		return nil, modelError(err)
 	}
 	res, err := choiceToResponse(ctx, params, chatResponse)
 	if err != nil {
@@ -88,10 +92,16 @@ func (m *chatModel) NewStreaming(ctx context.Context, req *blades.ModelRequest)
 		}
 		streaming := m.client.Chat.Completions.NewStreaming(ctx, params)
 		defer streaming.Close()
-		acc := openai.ChatCompletionAccumulator{}
+		var (
+			acc       = openai.ChatCompletionAccumulator{}
+			reasoning strings.Builder // the accumulator drops non-standard delta fields
+		)
 		for streaming.Next() {
 			chunk := streaming.Current()
 			acc.AddChunk(chunk)
+			for _, choice := range chunk.Choices {
+				reasoning.WriteString(reasoningContent(choice.Delta.JSON.ExtraFields))
+			}
 			message, err := chunkChoiceToResponse(ctx, chunk.Choices)
 			if err != nil {
 				yield(nil, err)
@@ -102,7 +112,7 @@ func (m *chatModel) NewStreaming(ctx context.Context, req *blades.ModelRequest)
 			}
 		}
 		if err := streaming.Err(); err != nil {
-			yield(nil, err)
+			yield(nil, modelError(err))
 			return
 		}
 		finalResponse, err := choiceToResponse(ctx, params, &acc.ChatCompletion)
@@ -110,6 +120,9 @@ func (m *chatModel) NewStreaming(ctx context.Context, req *blades.ModelRequest)
 			yield(nil, err)
 			return
 		}
+		if reasoning.Len() > 0 {
+			finalResponse.Message.Parts = append([]blades.Part{blades.ReasoningPart{Text: reasoning.String()}}, finalResponse.Message.Parts...)
+		}
 		yield(finalResponse, nil)
 	}
 }
@@ -150,6 +163,7 @@ func (m *chatModel) toChatCompletionParams(isStreaming bool, req *blades.ModelRe
 	if len(m.config.ExtraFields) > 0 {
 		params.SetExtraFields(m.config.ExtraFields)
 	}
+	applyGenerationConfig(&params, req.GenerationConfig)
 	if req.OutputSchema != nil {
 		schemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
 			Name:   "structured_outputs",
@@ -191,6 +205,10 @@ func (m *chatModel) toChatCompletionParams(isStreaming bool, req *blades.ModelRe
 					params.Messages = append(params.Messages, openai.ToolMessage(v.Response, v.ID))
 				}
 			}
+			// Tool messages only carry text, so multimodal results follow as user content.
+			if content := toToolContentMessage(msg); content != nil {
+				params.Messages = append(params.Messages, openai.UserMessage(toContentParts(content)))
+			}
 		}
 	}
 	return params, nil
@@ -219,6 +237,63 @@ func toToolCallMessage(msg *blades.Message) openai.ChatCompletionMessageParamUni
 	}
 }
 
+// applyGenerationConfig overrides the configured parameters with the set
+// fields of the request's generation config.
+func applyGenerationConfig(params *openai.ChatCompletionNewParams, config *blades.GenerationConfig) {
+	if config == nil {
+		return
+	}
+	if config.Temperature != nil {
+		params.Temperature = param.NewOpt(*config.Temperature)
+	}
+	if config.TopP != nil {
+		params.TopP = param.NewOpt(*config.TopP)
+	}
+	if config.MaxOutputTokens > 0 {
+		params.MaxCompletionTokens = param.NewOpt(config.MaxOutputTokens)
+	}
+	if len(config.StopSequences) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: config.StopSequences}
+	}
+	if config.Seed != nil {
+		params.Seed = param.NewOpt(*config.Seed)
+	}
+	if config.ParallelToolCalls != nil && len(params.Tools) > 0 {
+		params.ParallelToolCalls = param.NewOpt(*config.ParallelToolCalls)
+	}
+	if choice := config.ToolChoice; choice != nil && len(params.Tools) > 0 {
+		switch choice.Mode {
+		case blades.ToolChoiceAuto, blades.ToolChoiceNone, blades.ToolChoiceRequired:
+			params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: param.NewOpt(string(choice.Mode))}
+		case blades.ToolChoiceTool:
+			params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{
+				OfFunctionToolChoice: &openai.ChatCompletionNamedToolChoiceParam{
+					Function: openai.ChatCompletionNamedToolChoiceFunctionParam{Name: choice.Name},
+				},
+			}
+		}
+	}
+}
+
+// toToolContentMessage collects the multimodal content of the tool results in
+// msg into a user message, labelling each result with its tool call ID. It
+// returns nil when no tool result has content.
+func toToolContentMessage(msg *blades.Message) *blades.Message {
+	var parts []blades.Part
+	for _, part := range msg.Parts {
+		v, ok := part.(blades.ToolPart)
+		if !ok || len(v.Content) == 0 {
+			continue
+		}
+		parts = append(parts, blades.TextPart{Text: fmt.Sprintf("Content returned by tool call %s (%s):", v.ID, v.Name)})
+		parts = append(parts, v.Content...)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &blades.Message{Role: blades.RoleUser, Parts: parts}
+}
+
 func toTools(tools []tools.Tool) ([]openai.ChatCompletionToolUnionParam, error) {
 	if len(tools) == 0 {
 		return nil, nil
@@ -340,6 +415,9 @@ func choiceToResponse(ctx context.Context, params openai.ChatCompletionNewParams
 		TotalTokens:  cc.Usage.TotalTokens,
 	}
 	for _, choice := range cc.Choices {
+		if text := reasoningContent(choice.Message.JSON.ExtraFields); text != "" {
+			message.Parts = append(message.Parts, blades.ReasoningPart{Text: text})
+		}
 		if choice.Message.Content != "" {
 			message.Parts = append(message.Parts, blades.TextPart{Text: choice.Message.Content})
 		}
@@ -368,6 +446,9 @@ func choiceToResponse(ctx context.Context, params openai.ChatCompletionNewParams
 func chunkChoiceToResponse(ctx context.Context, choices []openai.ChatCompletionChunkChoice) (*blades.ModelResponse, error) {
 	message := blades.NewAssistantMessage(blades.StatusIncomplete)
 	for _, choice := range choices {
+		if text := reasoningContent(choice.Delta.JSON.ExtraFields); text != "" {
+			message.Parts = append(message.Parts, blades.ReasoningPart{Text: text})
+		}
 		if choice.Delta.Content != "" {
 			message.Parts = append(message.Parts, blades.TextPart{Text: choice.Delta.Content})
 		}
@@ -384,3 +465,26 @@ func chunkChoiceToResponse(ctx context.Context, choices []openai.ChatCompletionC
 	}
 	return &blades.ModelResponse{Message: message}, nil
 }
+
+// reasoningContent extracts the "reasoning_content" field that OpenAI-compatible
+// reasoning models (e.g. DeepSeek, Qwen) return next to the regular content.
+func reasoningContent(fields map[string]respjson.Field) string {
+	field, ok := fields["reasoning_content"]
+	if !ok {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal([]byte(field.Raw()), &text); err != nil {
+		return ""
+	}
+	return text
+}
+
+// modelError wraps an API error of the OpenAI SDK in a blades.ModelError.
+func modelError(err error) error {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return &blades.ModelError{StatusCode: apiErr.StatusCode, Err: err}
+	}
+	return err
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/go-kratos/blades"
+	"github.com/go-kratos/blades/tools"
 	openaisdk "github.com/openai/openai-go/v3"
 )
 
@@ -37,6 +40,89 @@ func TestToChatCompletionParamsAssistantRole(t *testing.T) {
 	}
 }
 
+func TestToChatCompletionParamsToolContent(t *testing.T) {
+	t.Parallel()
+
+	model := &chatModel{model: "gpt-test"}
+	req := &blades.ModelRequest{
+		Messages: []*blades.Message{{
+			Role: blades.RoleTool,
+			Parts: []blades.Part{blades.ToolPart{
+				ID:        "call_1",
+				Name:      "screenshot",
+				Request:   `{}`,
+				Response:  "captured",
+				Completed: true,
+				Content:   []blades.Part{blades.DataPart{Bytes: []byte("png"), MIMEType: blades.MIMEImagePNG}},
+			}},
+		}},
+	}
+	params, err := model.toChatCompletionParams(false, req)
+	if err != nil {
+		t.Fatalf("toChatCompletionParams returned error: %v", err)
+	}
+
+	payload, err := json.Marshal(params.Messages)
+	if err != nil {
+		t.Fatalf("marshal params messages: %v", err)
+	}
+	if got, want := len(params.Messages), 3; got != want {
+		t.Fatalf("messages len = %d, want %d; payload=%s", got, want, payload)
+	}
+	if !bytes.Contains(payload, []byte(`"role":"tool"`)) {
+		t.Fatalf("missing tool message; payload=%s", payload)
+	}
+	if !bytes.Contains(payload, []byte(`data:image/png;base64,cG5n`)) {
+		t.Fatalf("missing image content; payload=%s", payload)
+	}
+}
+
+func TestToChatCompletionParamsGenerationConfig(t *testing.T) {
+	t.Parallel()
+
+	var (
+		temperature = 0.2
+		seed        = int64(7)
+		parallel    = false
+	)
+	model := &chatModel{model: "gpt-test", config: Config{Temperature: 0.9, MaxOutputTokens: 100}}
+	tool := tools.NewTool("route", "route the request", tools.HandleFunc(func(context.Context, string) (string, error) {
+		return "", nil
+	}))
+	params, err := model.toChatCompletionParams(false, &blades.ModelRequest{
+		Tools:    []tools.Tool{tool},
+		Messages: []*blades.Message{blades.UserMessage("hello")},
+		GenerationConfig: &blades.GenerationConfig{
+			Temperature:       &temperature,
+			Seed:              &seed,
+			StopSequences:     []string{"END"},
+			ParallelToolCalls: &parallel,
+			ToolChoice:        &blades.ToolChoice{Mode: blades.ToolChoiceTool, Name: "route"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("toChatCompletionParams returned error: %v", err)
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatalf("unmarshal params: %v", err)
+	}
+	if got["temperature"] != 0.2 || got["seed"] != float64(7) || got["max_completion_tokens"] != float64(100) {
+		t.Fatalf("sampling params = %s", payload)
+	}
+	if got["parallel_tool_calls"] != false {
+		t.Fatalf("parallel_tool_calls = %v", got["parallel_tool_calls"])
+	}
+	choice, _ := got["tool_choice"].(map[string]any)
+	if function, _ := choice["function"].(map[string]any); function["name"] != "route" {
+		t.Fatalf("tool_choice = %v", got["tool_choice"])
+	}
+}
+
 func TestChoiceToResponseMarksToolPartsIncomplete(t *testing.T) {
 	t.Parallel()
 
@@ -102,3 +188,17 @@ func TestChunkChoiceToResponseMarksToolPartsIncomplete(t *testing.T) {
 		t.Fatalf("tool completed = %t, want %t", got, want)
 	}
 }
+
+func TestModelErrorCarriesStatusCode(t *testing.T) {
+	t.Parallel()
+
+	err := modelError(fmt.Errorf("request: %w", &openaisdk.Error{StatusCode: 429}))
+	var modelErr *blades.ModelError
+	if !errors.As(err, &modelErr) || modelErr.StatusCode != 429 {
+		t.Fatalf("modelError did not carry status 429")
+	}
+	plain := errors.New("boom")
+	if err := modelError(plain); err != plain {
+		t.Fatalf("modelError wrapped a non-API error: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/go-kratos/blades/contrib/sqlite
+
+go 1.24.0
+
+require (
+	github.com/go-kratos/blades v0.0.0-20251104140906-5d72b556bf96
+	github.com/google/uuid v1.6.0
+	github.com/mattn/go-sqlite3 v1.14.32
+)
+
+require (
+	github.com/go-kratos/kit v0.0.0-20251121083925-65298ad2aa44 // indirect
+	github.com/google/jsonschema-go v0.3.0 // indirect
+	golang.org/x/sync v0.18.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/go-kratos/blades => ../..
@@ -0,0 +1,181 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-kratos/blades"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3" // registers the "sqlite3" database/sql driver
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS blades_sessions (
+	id                TEXT PRIMARY KEY,
+	state             TEXT NOT NULL,
+	parent_id         TEXT NOT NULL DEFAULT '',
+	parent_message_id TEXT NOT NULL DEFAULT '',
+	updated_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS blades_session_messages (
+	session_id TEXT    NOT NULL,
+	seq        INTEGER NOT NULL,
+	message    TEXT    NOT NULL,
+	PRIMARY KEY (session_id, seq)
+);`
+
+// sessionStore is a SessionStore backed by a SQLite database.
+type sessionStore struct {
+	db *sql.DB
+}
+
+// NewSessionStore returns a SessionStore that persists sessions in db, creating
+// the required tables if they do not exist. Open db with the "sqlite3" driver,
+// for example sql.Open("sqlite3", "file:sessions.db?_busy_timeout=5000").
+// Session state is stored as a JSON document and history as one row per message.
+func NewSessionStore(ctx context.Context, db *sql.DB) (blades.SessionStore, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("sqlite: create schema: %w", err)
+	}
+	return &sessionStore{db: db}, nil
+}
+
+func (s *sessionStore) Create(ctx context.Context, id string, opts ...blades.SessionOption) (blades.Session, error) {
+	if id == "" {
+		id = uuid.NewString()
+	}
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO blades_sessions (id, state) VALUES (?, '{}') ON CONFLICT(id) DO NOTHING`, id)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, blades.ErrSessionExists
+	}
+	return blades.RestoreSession(&blades.SessionSnapshot{ID: id}, opts...), nil
+}
+
+func (s *sessionStore) Get(ctx context.Context, id string, opts ...blades.SessionOption) (blades.Session, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	snapshot := &blades.SessionSnapshot{ID: id}
+	var state string
+	err = tx.QueryRowContext(ctx, `SELECT state, parent_id, parent_message_id FROM blades_sessions WHERE id = ?`, id).
+		Scan(&state, &snapshot.ParentID, &snapshot.ParentMessageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, blades.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(state), &snapshot.State); err != nil {
+		return nil, fmt.Errorf("sqlite: decode state of session %s: %w", id, err)
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT message FROM blades_session_messages WHERE session_id = ? ORDER BY seq`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		message := new(blades.Message)
+		if err := json.Unmarshal([]byte(data), message); err != nil {
+			return nil, fmt.Errorf("sqlite: decode message of session %s: %w", id, err)
+		}
+		snapshot.History = append(snapshot.History, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return blades.RestoreSession(snapshot, opts...), nil
+}
+
+func (s *sessionStore) Save(ctx context.Context, session blades.Session) error {
+	snapshot, err := blades.SnapshotSession(session)
+	if err != nil {
+		return err
+	}
+	state, err := json.Marshal(snapshot.State)
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `INSERT INTO blades_sessions (id, state, parent_id, parent_message_id) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET state = excluded.state, parent_id = excluded.parent_id,
+		parent_message_id = excluded.parent_message_id, updated_at = CURRENT_TIMESTAMP`,
+		snapshot.ID, string(state), snapshot.ParentID, snapshot.ParentMessageID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blades_session_messages WHERE session_id = ?`, snapshot.ID); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO blades_session_messages (session_id, seq, message) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for seq, message := range snapshot.History {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, snapshot.ID, seq, string(data)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sessionStore) List(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM blades_sessions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *sessionStore) Delete(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `DELETE FROM blades_sessions WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return blades.ErrSessionNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blades_session_messages WHERE session_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
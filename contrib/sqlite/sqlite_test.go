@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kratos/blades"
+)
+
+func newTestStore(t *testing.T) blades.SessionStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := NewSessionStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestSessionStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	session, err := store.Create(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.SetState("count", 2)
+	session.Append(ctx, blades.UserMessage("hello"))
+	session.Append(ctx, blades.AssistantMessage("hi"))
+	if err := store.Save(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again must replace rather than duplicate the history.
+	if err := store.Save(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.State()["count"]; got != float64(2) {
+		t.Errorf("state[count] = %v (%T), want 2", got, got)
+	}
+	history, err := loaded.History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history len = %d, want 2", len(history))
+	}
+	if history[0].Text() != "hello" || history[1].Text() != "hi" {
+		t.Errorf("history = [%q %q], want [hello hi]", history[0].Text(), history[1].Text())
+	}
+}
+
+func TestSessionStore_Errors(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, blades.ErrSessionNotFound) {
+		t.Errorf("Get err = %v, want ErrSessionNotFound", err)
+	}
+	if _, err := store.Create(ctx, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create(ctx, "s1"); !errors.Is(err, blades.ErrSessionExists) {
+		t.Errorf("Create err = %v, want ErrSessionExists", err)
+	}
+	ids, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "s1" {
+		t.Errorf("ids = %v, want [s1]", ids)
+	}
+	if err := store.Delete(ctx, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "s1"); !errors.Is(err, blades.ErrSessionNotFound) {
+		t.Errorf("Delete err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSessionStore_ForkKeepsLineage(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	session, err := store.Create(ctx, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	question := blades.UserMessage("question")
+	session.Append(ctx, question)
+	session.Append(ctx, blades.AssistantMessage("answer"))
+
+	fork, err := blades.ForkSession(session, question.ID, "branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, fork); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.Get(ctx, "branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentID, messageID := blades.SessionParent(loaded)
+	if parentID != "root" || messageID != question.ID {
+		t.Errorf("SessionParent = (%q, %q), want (root, %q)", parentID, messageID, question.ID)
+	}
+	history, err := loaded.History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Errorf("history len = %d, want 1", len(history))
+	}
+}
@@ -20,6 +20,8 @@ type Invocation struct {
 	Instruction *Message
 	Message     *Message
 	Tools       []tools.Tool
+	// GenerationConfig overrides the generation parameters of every agent in the invocation.
+	GenerationConfig *GenerationConfig
 	// committed tracks whether the initial user message has been (or will be)
 	// appended to the session. All clones share the same *atomic.Bool pointer,
 	// so CompareAndSwap guarantees exactly-once append even under concurrent
@@ -51,14 +53,15 @@ func NewInvocationID() string {
 // perform the session append; all others skip. This is safe for concurrent use.
 func (inv *Invocation) Clone() *Invocation {
 	return &Invocation{
-		ID:          inv.ID,
-		Model:       inv.Model,
-		Session:     inv.Session,
-		Resume:      inv.Resume,
-		Stream:      inv.Stream,
-		Message:     inv.Message.Clone(),
-		Instruction: inv.Instruction.Clone(),
-		committed:   inv.committed,
-		Tools:       slices.Clone(inv.Tools),
+		ID:               inv.ID,
+		Model:            inv.Model,
+		Session:          inv.Session,
+		Resume:           inv.Resume,
+		Stream:           inv.Stream,
+		Message:          inv.Message.Clone(),
+		Instruction:      inv.Instruction.Clone(),
+		committed:        inv.committed,
+		Tools:            slices.Clone(inv.Tools),
+		GenerationConfig: inv.GenerationConfig,
 	}
 }
@@ -2,6 +2,7 @@ package blades
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
@@ -11,14 +12,58 @@ var (
 	ErrNoAgentContext = errors.New("agent not found in context")
 	// ErrMissingInvocationContext is returned when an invocation context is missing from the context.
 	ErrNoInvocationContext = errors.New("invocation not found in context")
+	// ErrNoToolContext is returned when a tool context is missing from the context.
+	ErrNoToolContext = errors.New("tool not found in context")
 	// ErrModelProviderRequired is returned when a model provider is not supplied where required.
 	ErrModelProviderRequired = errors.New("model provider is required")
+	// ErrModelUnavailable is returned when no provider of a composite model can serve a request.
+	ErrModelUnavailable = errors.New("no model provider available")
 	// ErrMaxIterationsExceeded is returned when an agent exceeds the maximum allowed iterations.
 	ErrMaxIterationsExceeded = errors.New("maximum iterations exceeded in agent execution")
 	// ErrMissingFinalResponse is returned when an agent's stream ends without a final response.
 	ErrNoFinalResponse = errors.New("stream ended without a final response")
 	// ErrInterrupted is returned when execution is interrupted.
 	ErrInterrupted = errors.New("execution was interrupted")
+	// ErrSessionNotFound is returned when a SessionStore has no session with the requested ID.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionExists is returned when creating a session whose ID is already stored.
+	ErrSessionExists = errors.New("session already exists")
+	// ErrMessageNotFound is returned when a session has no message with the requested ID.
+	ErrMessageNotFound = errors.New("message not found in session")
+	// ErrSessionStoreRequired is returned when a session ID is given to a Runner without a SessionStore.
+	ErrSessionStoreRequired = errors.New("session store is required to load sessions by ID")
+	// ErrToolNotFound is returned when the model calls a tool the agent does not have.
+	ErrToolNotFound = errors.New("tool not found")
+	// ErrToolTimeout is returned when a tool call exceeds its timeout.
+	ErrToolTimeout = errors.New("tool call timed out")
+	// ErrToolCallNotPending is returned when deciding on a tool call that is not awaiting approval.
+	ErrToolCallNotPending = errors.New("tool call is not pending approval")
+	// ErrInvalidOutput is returned when an agent's output does not match its output schema.
+	ErrInvalidOutput = errors.New("output does not match the output schema")
+	// ErrBudgetExceeded is returned when a run crosses its token or cost budget.
+	ErrBudgetExceeded = errors.New("budget exceeded")
+	// ErrPanic is returned when a Runner recovers a panic raised during a run.
+	ErrPanic = errors.New("panic recovered")
+	// ErrArtifactNotFound is returned when an ArtifactStore has no artifact with the requested name or version.
+	ErrArtifactNotFound = errors.New("artifact not found")
+	// ErrArtifactStoreRequired is returned when saving or loading artifacts on a Runner without an ArtifactStore.
+	ErrArtifactStoreRequired = errors.New("artifact store is required")
 	// ErrLoopEscalated is returned when a loop condition signals escalation to an outer handler.
 	ErrLoopEscalated = errors.New("loop escalated to outer handler")
 )
+
+// PanicError is a panic recovered by a Runner. It wraps ErrPanic.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrPanic, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrPanic
+}
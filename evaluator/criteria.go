@@ -2,45 +2,30 @@ package evaluator
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/go-kratos/blades"
-	"github.com/google/jsonschema-go/jsonschema"
 )
 
 // Criteria evaluates the relevancy of LLM responses.
 type Criteria struct {
-	agent blades.Agent
+	runner *blades.Runner
 }
 
 // New creates a new Criteria evaluator.
 func New(name string, opts ...blades.AgentOption) (Evaluator, error) {
-	schema, err := jsonschema.For[Evaluation](nil)
+	agent, err := blades.NewTypedAgent[Evaluation](name, opts...)
 	if err != nil {
 		return nil, err
 	}
-	agent, err := blades.NewAgent(
-		name,
-		append(opts, blades.WithOutputSchema(schema))...,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return &Criteria{agent: agent}, nil
+	return &Criteria{runner: blades.NewRunner(agent)}, nil
 }
 
-// Run evaluates the LLM response against the configured criteria.
+// Run evaluates the LLM response against the configured criteria. Evaluations
+// that do not match the schema are repaired by re-prompting the model.
 func (r *Criteria) Run(ctx context.Context, message *blades.Message) (*Evaluation, error) {
-	iter := r.agent.Run(ctx, &blades.Invocation{Message: message})
-	for msg, err := range iter {
-		if err != nil {
-			return nil, err
-		}
-		var evaluation Evaluation
-		if err := json.Unmarshal([]byte(msg.Text()), &evaluation); err != nil {
-			return nil, err
-		}
-		return &evaluation, nil
+	evaluation, err := blades.RunTyped[Evaluation](ctx, r.runner, message)
+	if err != nil {
+		return nil, err
 	}
-	return nil, blades.ErrNoFinalResponse
+	return &evaluation, nil
 }
@@ -0,0 +1,164 @@
+package blades
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies the kind of a lifecycle Event.
+type EventType string
+
+const (
+	// EventAgentStarted is emitted when an agent starts handling an invocation.
+	EventAgentStarted EventType = "agent_started"
+	// EventAgentFinished is emitted when an agent is done, with its final message or error.
+	EventAgentFinished EventType = "agent_finished"
+	// EventMessage is emitted for every message an agent yields.
+	EventMessage EventType = "message"
+	// EventToolStarted is emitted before a tool call runs.
+	EventToolStarted EventType = "tool_started"
+	// EventToolFinished is emitted after a tool call completes or fails.
+	EventToolFinished EventType = "tool_finished"
+	// EventToolProgress is emitted for every update of a streaming tool, with
+	// the partial response in Tool and the progress and step in Metadata.
+	EventToolProgress EventType = "tool_progress"
+	// EventHandoff is emitted when an agent hands the conversation to another agent.
+	EventHandoff EventType = "handoff"
+	// EventRetry is emitted before a failed handler is retried.
+	EventRetry EventType = "retry"
+	// EventModelFallback is emitted when a provider of a composite model fails
+	// and the request moves on, with the provider and error class in Metadata.
+	EventModelFallback EventType = "model_fallback"
+	// EventContextCompressed is emitted when a ContextCompressor shrinks the session history.
+	EventContextCompressed EventType = "context_compressed"
+	// EventCacheError is emitted when a caching model provider fails to store
+	// a response, which is still returned.
+	EventCacheError EventType = "cache_error"
+)
+
+// Event is a structured lifecycle event emitted while a Runner executes an agent.
+type Event struct {
+	Type         EventType
+	InvocationID string
+	// Agent is the name of the agent that emitted the event.
+	Agent string
+	// Parent is the name of the calling agent for EventAgentStarted of a sub-agent.
+	Parent string
+	Time   time.Time
+	// Duration is the elapsed time of finished agents and tool calls.
+	Duration time.Duration
+	// Message is the yielded message for EventMessage and the final message for EventAgentFinished.
+	Message *Message
+	// Tool is the tool call for tool events.
+	Tool *ToolPart
+	// Target is the agent receiving the conversation for EventHandoff.
+	Target string
+	// Attempt is the attempt about to run for EventRetry, starting at 2.
+	Attempt int
+	// TokenUsage is the usage of the message, or the total usage of the agent when finished.
+	TokenUsage TokenUsage
+	// Metadata holds type-specific details, e.g. message counts for EventContextCompressed.
+	Metadata map[string]any
+	Err      error
+}
+
+// EventHandler receives lifecycle events. It is called synchronously from the
+// goroutine that emits the event and must be safe for concurrent use.
+type EventHandler func(context.Context, *Event)
+
+// WithEventHandler registers a handler that receives the lifecycle events of every run.
+func WithEventHandler(handler EventHandler) RunnerOption {
+	return func(r *Runner) {
+		r.eventHandlers = append(r.eventHandlers, handler)
+	}
+}
+
+type ctxEventKey struct{}
+
+// eventEmitter dispatches events of one invocation to the registered handlers.
+type eventEmitter struct {
+	invocationID string
+	handlers     []EventHandler
+}
+
+// newEventContext returns a context that delivers events to the given handlers.
+func newEventContext(ctx context.Context, invocationID string, handlers ...EventHandler) context.Context {
+	if len(handlers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxEventKey{}, &eventEmitter{invocationID: invocationID, handlers: handlers})
+}
+
+// EmitEvent delivers the event to the handlers registered on the Runner. Empty
+// InvocationID, Agent and Time fields are filled in from the context. It is a
+// no-op when no handler is registered.
+func EmitEvent(ctx context.Context, event *Event) {
+	emitter, ok := ctx.Value(ctxEventKey{}).(*eventEmitter)
+	if !ok {
+		return
+	}
+	if event.InvocationID == "" {
+		event.InvocationID = emitter.invocationID
+	}
+	if event.Agent == "" {
+		if agent, ok := FromAgentContext(ctx); ok {
+			event.Agent = agent.Name()
+		}
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, handler := range emitter.handlers {
+		handler(ctx, event)
+	}
+}
+
+// agentEvents is a Middleware that emits the started, message and finished
+// events of the agent in ctx. parent is the name of the calling agent, if any.
+func agentEvents(parent string) Middleware {
+	return func(next Handler) Handler {
+		return HandleFunc(func(ctx context.Context, invocation *Invocation) Generator[*Message, error] {
+			return func(yield func(*Message, error) bool) {
+				if _, ok := ctx.Value(ctxEventKey{}).(*eventEmitter); !ok {
+					for m, err := range next.Handle(ctx, invocation) {
+						if !yield(m, err) {
+							return
+						}
+					}
+					return
+				}
+				var (
+					start    = time.Now()
+					final    *Message
+					usage    TokenUsage
+					runErr   error
+					finished = &Event{Type: EventAgentFinished}
+				)
+				EmitEvent(ctx, &Event{Type: EventAgentStarted, Parent: parent, Time: start})
+				defer func() {
+					finished.Duration = time.Since(start)
+					finished.Message = final
+					finished.TokenUsage = usage
+					finished.Err = runErr
+					EmitEvent(ctx, finished)
+				}()
+				for m, err := range next.Handle(ctx, invocation) {
+					if err != nil {
+						runErr = err
+					} else if m != nil {
+						final = m
+						if m.Status == StatusCompleted {
+							usage.InputTokens += m.TokenUsage.InputTokens
+							usage.OutputTokens += m.TokenUsage.OutputTokens
+							usage.TotalTokens += m.TokenUsage.TotalTokens
+						}
+						EmitEvent(ctx, &Event{Type: EventMessage, Message: m, TokenUsage: m.TokenUsage})
+					}
+					if !yield(m, err) {
+						return
+					}
+				}
+			}
+		})
+	}
+}
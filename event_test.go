@@ -0,0 +1,138 @@
+package blades
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+// eventRecorder collects events from concurrent emitters.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (r *eventRecorder) handle(_ context.Context, event *Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) types() []EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]EventType, 0, len(r.events))
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func newEventTestAgent(t *testing.T) Agent {
+	t.Helper()
+	tool := bladestools.NewTool("echo", "echo", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return `{"ok":true}`, nil
+	}))
+	agent, err := NewAgent("event-agent", WithModel(&toolLoopSessionModel{}), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	return agent
+}
+
+func TestRunnerEventHandler(t *testing.T) {
+	t.Parallel()
+
+	recorder := &eventRecorder{}
+	runner := NewRunner(newEventTestAgent(t), WithEventHandler(recorder.handle))
+	if _, err := runner.Run(context.Background(), UserMessage("hello"), WithInvocationID("inv-1")); err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+
+	want := []EventType{
+		EventAgentStarted,
+		EventToolStarted,
+		EventToolFinished,
+		EventMessage, // tool results
+		EventMessage, // final answer
+		EventAgentFinished,
+	}
+	if got := recorder.types(); !slices.Equal(got, want) {
+		t.Fatalf("event types = %v, want %v", got, want)
+	}
+	for _, event := range recorder.events {
+		if event.InvocationID != "inv-1" {
+			t.Fatalf("%s invocation ID = %q, want %q", event.Type, event.InvocationID, "inv-1")
+		}
+		if event.Agent != "event-agent" {
+			t.Fatalf("%s agent = %q, want %q", event.Type, event.Agent, "event-agent")
+		}
+	}
+	if tool := recorder.events[2].Tool; tool == nil || tool.Response != `{"ok":true}` {
+		t.Fatalf("tool finished event tool = %+v", tool)
+	}
+	finished := recorder.events[len(recorder.events)-1]
+	if finished.Message == nil || finished.Message.Text() != "done" {
+		t.Fatalf("agent finished message = %v", finished.Message)
+	}
+	if finished.Duration <= 0 {
+		t.Fatalf("agent finished duration = %s, want > 0", finished.Duration)
+	}
+}
+
+func TestRunnerRunEvents(t *testing.T) {
+	t.Parallel()
+
+	model := &scriptedStreamingModel{
+		streamResponses: []*ModelResponse{
+			streamingResponse(StatusIncomplete, "hel"),
+			streamingResponse(StatusCompleted, "hello"),
+		},
+	}
+	agent, err := NewAgent("stream-agent", WithModel(model))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	runner := NewRunner(agent)
+	var types []EventType
+	for event, err := range runner.RunEvents(context.Background(), UserMessage("hello")) {
+		if err != nil {
+			t.Fatalf("run events: %v", err)
+		}
+		types = append(types, event.Type)
+	}
+	want := []EventType{EventAgentStarted, EventMessage, EventMessage, EventAgentFinished}
+	if !slices.Equal(types, want) {
+		t.Fatalf("event types = %v, want %v", types, want)
+	}
+
+	// Stopping early must not block the run.
+	for range runner.RunEvents(context.Background(), UserMessage("hello")) {
+		break
+	}
+}
+
+func TestSessionHistoryEmitsContextCompressed(t *testing.T) {
+	t.Parallel()
+
+	recorder := &eventRecorder{}
+	ctx := newEventContext(context.Background(), "inv-1", recorder.handle)
+	session := NewSession(WithContextCompressor(&limitCompressor{max: 1}))
+	for _, text := range []string{"a", "b", "c"} {
+		if err := session.Append(ctx, UserMessage(text)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if _, err := session.History(ctx); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if got, want := recorder.types(), []EventType{EventContextCompressed}; !slices.Equal(got, want) {
+		t.Fatalf("event types = %v, want %v", got, want)
+	}
+	if got, want := recorder.events[0].Metadata["before"], 3; got != want {
+		t.Fatalf("before = %v, want %v", got, want)
+	}
+}
@@ -14,83 +14,55 @@ import (
 	"github.com/go-kratos/blades/tools"
 )
 
-const (
-	leaveApprovalKey    = "leave_approval"
-	defaultLeaveRequest = "My name is Alice. I need leave from 2026-03-12 to 2026-03-14 because I have a fever."
-)
+const defaultLeaveRequest = "My name is Alice. I need leave from 2026-03-12 to 2026-03-14 because I have a fever."
 
-type LeaveApprovalRequest struct {
+type LeaveRequest struct {
 	EmployeeName string `json:"employee_name" jsonschema:"Employee name"`
 	StartDate    string `json:"start_date" jsonschema:"Leave start date in YYYY-MM-DD format"`
 	EndDate      string `json:"end_date" jsonschema:"Leave end date in YYYY-MM-DD format"`
 	Reason       string `json:"reason" jsonschema:"Reason for the leave request"`
 }
 
-type LeaveApprovalResult struct {
-	Approved bool `json:"approved" jsonschema:"Whether the leave request is approved"`
-}
-
-type LeaveApprovalState struct {
-	Request  LeaveApprovalRequest
-	Decision string
+type LeaveResult struct {
+	Submitted bool `json:"submitted" jsonschema:"Whether the leave request was submitted"`
 }
 
-func requestLeaveApproval(ctx context.Context, req LeaveApprovalRequest) (LeaveApprovalResult, error) {
-	session, ok := blades.SessionFromContext(ctx)
-	if !ok {
-		return LeaveApprovalResult{}, blades.ErrNoSessionContext
-	}
-
-	state, _ := session.State()[leaveApprovalKey].(LeaveApprovalState)
-	state.Request = req
-	session.SetState(leaveApprovalKey, state)
-
-	if state.Decision == "" {
-		return LeaveApprovalResult{}, blades.ErrInterrupted
-	}
-
-	return LeaveApprovalResult{
-		Approved: state.Decision == "approve",
-	}, nil
+func submitLeave(ctx context.Context, req LeaveRequest) (LeaveResult, error) {
+	log.Printf("leave submitted for %s (%s - %s)", req.EmployeeName, req.StartDate, req.EndDate)
+	return LeaveResult{Submitted: true}, nil
 }
 
-func promptApproval(session blades.Session) error {
-	state, ok := session.State()[leaveApprovalKey].(LeaveApprovalState)
-	if !ok {
-		return fmt.Errorf("pending leave request not found in session")
-	}
-	req := state.Request
+// promptDecisions asks a human to approve or reject every pending tool call.
+func promptDecisions(session blades.Session) error {
 	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Println("Leave request is waiting for human approval:")
-	fmt.Printf("  Employee: %s\n", req.EmployeeName)
-	fmt.Printf("  Start:    %s\n", req.StartDate)
-	fmt.Printf("  End:      %s\n", req.EndDate)
-	fmt.Printf("  Reason:   %s\n", req.Reason)
-
-	for {
-		fmt.Print("Decision [approve/reject]: ")
-		decision, err := reader.ReadString('\n')
-		if err != nil {
-			return err
-		}
-		decision = strings.TrimSpace(decision)
-		if decision != "approve" && decision != "reject" {
-			fmt.Println("Please enter either approve or reject.")
-			continue
+	for _, call := range blades.PendingToolCalls(session) {
+		fmt.Printf("Tool call %s is waiting for human approval:\n", call.Name)
+		fmt.Printf("  Arguments: %s\n", call.Request)
+		for {
+			fmt.Print("Decision [approve/reject]: ")
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			action := blades.ToolDecisionAction(strings.TrimSpace(input))
+			if action != blades.ToolApprove && action != blades.ToolReject {
+				fmt.Println("Please enter either approve or reject.")
+				continue
+			}
+			if err := blades.ResolveToolCall(session, call.ID, blades.ToolDecision{Action: action}); err != nil {
+				return err
+			}
+			break
 		}
-
-		state.Decision = decision
-		session.SetState(leaveApprovalKey, state)
-		return nil
 	}
+	return nil
 }
 
 func main() {
-	approvalTool, err := tools.NewFunc(
-		"request_leave_approval",
-		"Submit a leave request for human approval. Always call this tool before answering a leave request.",
-		requestLeaveApproval,
+	leaveTool, err := tools.NewFunc(
+		"submit_leave",
+		"Submit a leave request. Always call this tool before answering a leave request.",
+		submitLeave,
 	)
 	if err != nil {
 		log.Fatal(err)
@@ -105,40 +77,27 @@ func main() {
 		blades.WithInstruction(`You are an HR leave assistant.
 For every leave request:
 1. Extract employee_name, start_date, end_date, and reason.
-2. Call the request_leave_approval tool exactly once.
-3. After the tool returns, tell the user whether the leave was approved or rejected.`),
-		blades.WithTools(approvalTool),
+2. Call the submit_leave tool exactly once.
+3. After the tool returns, tell the user whether the leave was submitted or rejected.`),
+		blades.WithTools(leaveTool),
+		blades.WithToolApproval(blades.RequireToolApproval("submit_leave")),
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	input := blades.UserMessage(defaultLeaveRequest)
 	ctx := context.Background()
 	session := blades.NewSession()
-	invocationID := "leave-approval-001"
 	runner := blades.NewRunner(agent)
 
-	output, err := runner.Run(
-		ctx,
-		input,
-		blades.WithSession(session),
-		blades.WithInvocationID(invocationID),
-	)
-	if !errors.Is(err, blades.ErrInterrupted) {
-		log.Fatal(err)
-	}
-	log.Println("leave request paused, waiting for human approval")
-	if err := promptApproval(session); err != nil {
-		log.Fatal(err)
+	output, err := runner.Run(ctx, blades.UserMessage(defaultLeaveRequest), blades.WithSession(session))
+	for errors.Is(err, blades.ErrInterrupted) {
+		log.Println("leave request paused, waiting for human approval")
+		if err := promptDecisions(session); err != nil {
+			log.Fatal(err)
+		}
+		output, err = runner.Run(ctx, nil, blades.WithResume(true), blades.WithSession(session))
 	}
-	output, err = runner.Run(
-		ctx,
-		input,
-		blades.WithResume(true),
-		blades.WithSession(session),
-		blades.WithInvocationID(invocationID),
-	)
 	if err != nil {
 		log.Fatal(err)
 	}
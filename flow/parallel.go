@@ -47,11 +47,16 @@ func (p *ParallelAgent) Run(ctx context.Context, invocation *blades.Invocation)
 		eg, ctx := errgroup.WithContext(ctx)
 		for _, agent := range p.config.SubAgents {
 			inv := invocation.Clone() // Clone sequentially before goroutine to avoid a data race on committed.
-			eg.Go(func() error {
-				for message, err := range agent.Run(ctx, inv) {
+			eg.Go(func() (err error) {
+				defer func() {
 					if err != nil {
 						// Send error result and stop
 						ch <- result{message: nil, err: err}
+					}
+				}()
+				defer blades.RecoverPanic(ctx, &err)
+				for message, err := range agent.Run(ctx, inv) {
+					if err != nil {
 						return err
 					}
 					ch <- result{message: message, err: nil}
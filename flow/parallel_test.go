@@ -0,0 +1,34 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/blades"
+)
+
+// panicAgent is a sub-agent that panics when run.
+type panicAgent struct{}
+
+func (panicAgent) Name() string        { return "panic" }
+func (panicAgent) Description() string { return "" }
+
+func (panicAgent) Run(context.Context, *blades.Invocation) blades.Generator[*blades.Message, error] {
+	return func(yield func(*blades.Message, error) bool) {
+		panic("sub-agent exploded")
+	}
+}
+
+func TestParallelAgent_RecoversSubAgentPanic(t *testing.T) {
+	parallel := NewParallelAgent(ParallelConfig{
+		Name:      "parallel",
+		SubAgents: []blades.Agent{newEchoAgent(t, "echo", "hi"), panicAgent{}},
+	})
+	runner := blades.NewRunner(parallel, blades.WithPanicRecovery())
+	_, err := runner.Run(context.Background(), blades.UserMessage("hi"))
+	var panicErr *blades.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "sub-agent exploded" {
+		t.Fatalf("run error = %v, want the sub-agent panic", err)
+	}
+}
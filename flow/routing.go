@@ -27,21 +27,29 @@ func NewRoutingAgent(config RoutingConfig) (blades.Agent, error) {
 	if err != nil {
 		return nil, err
 	}
+	var (
+		names   []string
+		targets = make(map[string]blades.Agent)
+	)
+	for _, agent := range config.SubAgents {
+		name := strings.TrimSpace(agent.Name())
+		names = append(names, name)
+		targets[name] = agent
+	}
+	handoffTool := handoff.NewHandoffTool(names...)
+	// The router always answers with a handoff to one of the sub-agents.
 	rootAgent, err := blades.NewAgent(
 		config.Name,
 		blades.WithModel(config.Model),
 		blades.WithDescription(config.Description),
 		blades.WithInstruction(instruction),
-		blades.WithTools(handoff.NewHandoffTool()),
+		blades.WithTools(handoffTool),
+		blades.WithToolChoice(blades.ToolChoice{Mode: blades.ToolChoiceTool, Name: handoffTool.Name()}),
 		blades.WithMiddleware(config.Middlewares...),
 	)
 	if err != nil {
 		return nil, err
 	}
-	targets := make(map[string]blades.Agent)
-	for _, agent := range config.SubAgents {
-		targets[strings.TrimSpace(agent.Name())] = agent
-	}
 	return &RoutingAgent{
 		Agent:   rootAgent,
 		targets: targets,
@@ -76,6 +84,7 @@ func (a *RoutingAgent) Run(ctx context.Context, invocation *blades.Invocation) b
 			yield(nil, fmt.Errorf("target agent not found: %s", targetAgent))
 			return
 		}
+		blades.EmitEvent(ctx, &blades.Event{Type: blades.EventHandoff, Agent: a.Name(), Target: targetAgent})
 		targetInvocation := invocation.Clone()
 		for message, err := range agent.Run(ctx, targetInvocation) {
 			if !yield(message, err) {
@@ -7,11 +7,14 @@ import (
 	"github.com/go-kratos/blades"
 )
 
-type routeSelectorModel struct{}
+type routeSelectorModel struct {
+	req *blades.ModelRequest
+}
 
 func (m *routeSelectorModel) Name() string { return "selector" }
 
 func (m *routeSelectorModel) Generate(ctx context.Context, req *blades.ModelRequest) (*blades.ModelResponse, error) {
+	m.req = req
 	msg := blades.NewAssistantMessage(blades.StatusCompleted)
 	msg.Role = blades.RoleTool
 	msg.Parts = append(msg.Parts, blades.NewToolPart("handoff-1", "handoff_to_agent", `{"agentName":"worker"}`))
@@ -71,3 +74,36 @@ func TestRoutingAgent_DoesNotLeakRouterToolsToTarget(t *testing.T) {
 		}
 	}
 }
+
+func TestRoutingAgent_ForcesHandoffToKnownAgent(t *testing.T) {
+	t.Parallel()
+
+	worker, err := blades.NewAgent("worker", blades.WithModel(&captureToolsModel{}))
+	if err != nil {
+		t.Fatalf("create target agent: %v", err)
+	}
+	selector := &routeSelectorModel{}
+	router, err := NewRoutingAgent(RoutingConfig{
+		Name:      "router",
+		Model:     selector,
+		SubAgents: []blades.Agent{worker},
+	})
+	if err != nil {
+		t.Fatalf("create routing agent: %v", err)
+	}
+	if _, err := blades.NewRunner(router).Run(context.Background(), blades.UserMessage("route this")); err != nil {
+		t.Fatalf("run routing agent: %v", err)
+	}
+
+	config := selector.req.GenerationConfig
+	if config == nil || config.ToolChoice == nil {
+		t.Fatalf("router request has no tool choice")
+	}
+	if config.ToolChoice.Mode != blades.ToolChoiceTool || config.ToolChoice.Name != "handoff_to_agent" {
+		t.Fatalf("tool choice = %+v, want handoff_to_agent", config.ToolChoice)
+	}
+	enum := selector.req.Tools[0].InputSchema().Properties["agentName"].Enum
+	if len(enum) != 1 || enum[0] != "worker" {
+		t.Fatalf("agentName enum = %v, want [worker]", enum)
+	}
+}
@@ -0,0 +1,128 @@
+package blades
+
+// ToolChoiceMode controls whether and how the model calls tools.
+type ToolChoiceMode string
+
+const (
+	// ToolChoiceAuto lets the model decide whether to call tools.
+	ToolChoiceAuto ToolChoiceMode = "auto"
+	// ToolChoiceNone forbids tool calls.
+	ToolChoiceNone ToolChoiceMode = "none"
+	// ToolChoiceRequired makes the model call at least one tool.
+	ToolChoiceRequired ToolChoiceMode = "required"
+	// ToolChoiceTool makes the model call the tool named by ToolChoice.Name.
+	ToolChoiceTool ToolChoiceMode = "tool"
+)
+
+// ToolChoice is the tool-calling policy of a model request.
+type ToolChoice struct {
+	Mode ToolChoiceMode `json:"mode"`
+	// Name is the tool to call when Mode is ToolChoiceTool.
+	Name string `json:"name,omitempty"`
+}
+
+// GenerationConfig holds provider-agnostic generation parameters of a model
+// request. Unset fields keep the defaults configured on the model provider.
+type GenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	MaxOutputTokens int64    `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+	Seed            *int64   `json:"seed,omitempty"`
+	// ToolChoice restricts tool calls; nil leaves the choice to the model.
+	ToolChoice *ToolChoice `json:"toolChoice,omitempty"`
+	// ParallelToolCalls allows or forbids several tool calls in one turn.
+	ParallelToolCalls *bool `json:"parallelToolCalls,omitempty"`
+}
+
+// WithGenerationConfig sets the generation parameters of every model request
+// of the agent. Fields set with WithRunGenerationConfig take precedence.
+func WithGenerationConfig(config GenerationConfig) AgentOption {
+	return func(a *agent) {
+		a.generationConfig = &config
+	}
+}
+
+// WithToolChoice sets the tool-calling policy of the agent's model requests,
+// taking precedence over the ToolChoice of WithGenerationConfig. A required or
+// specific-tool choice only applies until the model has called a tool; later
+// turns of the invocation use ToolChoiceAuto so the model can answer with the
+// tool results instead of looping until the iteration limit.
+func WithToolChoice(choice ToolChoice) AgentOption {
+	return func(a *agent) {
+		a.toolChoice = &choice
+	}
+}
+
+// WithRunGenerationConfig overrides the generation parameters of every agent
+// in the run, on top of the agents' own WithGenerationConfig settings. Agents
+// called as tools inherit it without the ToolChoice, which names the caller's
+// tools.
+func WithRunGenerationConfig(config GenerationConfig) RunOption {
+	return func(r *RunOptions) {
+		r.GenerationConfig = &config
+	}
+}
+
+// mergeGenerationConfig returns base with the set fields of override applied.
+// It returns nil when both are nil.
+func mergeGenerationConfig(base, override *GenerationConfig) *GenerationConfig {
+	if base == nil && override == nil {
+		return nil
+	}
+	var merged GenerationConfig
+	if base != nil {
+		merged = *base
+	}
+	if override == nil {
+		return &merged
+	}
+	if override.Temperature != nil {
+		merged.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		merged.TopP = override.TopP
+	}
+	if override.MaxOutputTokens > 0 {
+		merged.MaxOutputTokens = override.MaxOutputTokens
+	}
+	if override.StopSequences != nil {
+		merged.StopSequences = override.StopSequences
+	}
+	if override.Seed != nil {
+		merged.Seed = override.Seed
+	}
+	if override.ToolChoice != nil {
+		merged.ToolChoice = override.ToolChoice
+	}
+	if override.ParallelToolCalls != nil {
+		merged.ParallelToolCalls = override.ParallelToolCalls
+	}
+	return &merged
+}
+
+// forcesToolCall reports whether the tool choice makes the model call a tool.
+func (c *ToolChoice) forcesToolCall() bool {
+	return c != nil && (c.Mode == ToolChoiceRequired || c.Mode == ToolChoiceTool)
+}
+
+// releaseToolChoice returns config with a forced tool choice relaxed to
+// ToolChoiceAuto, for the model turns that follow a tool call.
+func releaseToolChoice(config *GenerationConfig) *GenerationConfig {
+	if config == nil || !config.ToolChoice.forcesToolCall() {
+		return config
+	}
+	released := *config
+	released.ToolChoice = &ToolChoice{Mode: ToolChoiceAuto}
+	return &released
+}
+
+// requestGenerationConfig returns the generation parameters of the agent's
+// model requests: its own settings overridden by those of the invocation.
+func (a *agent) requestGenerationConfig(invocation *Invocation) *GenerationConfig {
+	base := a.generationConfig
+	if a.toolChoice != nil {
+		base = mergeGenerationConfig(base, &GenerationConfig{ToolChoice: a.toolChoice})
+	}
+	return mergeGenerationConfig(base, invocation.GenerationConfig)
+}
@@ -0,0 +1,134 @@
+package blades
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+// toolChoiceModel records the tool choice of every request it answers.
+type toolChoiceModel struct {
+	usageModel
+	choices []ToolChoiceMode
+}
+
+func (m *toolChoiceModel) Generate(ctx context.Context, req *ModelRequest) (*ModelResponse, error) {
+	mode := ToolChoiceMode("")
+	if req.GenerationConfig != nil && req.GenerationConfig.ToolChoice != nil {
+		mode = req.GenerationConfig.ToolChoice.Mode
+	}
+	m.choices = append(m.choices, mode)
+	return m.usageModel.Generate(ctx, req)
+}
+
+func TestGenerationConfigMergedIntoModelRequest(t *testing.T) {
+	t.Parallel()
+
+	var (
+		agentTemp = 0.2
+		runTemp   = 0.9
+		topP      = 0.8
+	)
+	model := &captureModel{}
+	agent, err := NewAgent("agent", WithModel(model), WithGenerationConfig(GenerationConfig{
+		Temperature:   &agentTemp,
+		TopP:          &topP,
+		StopSequences: []string{"END"},
+	}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	runner := NewRunner(agent)
+	if _, err := runner.Run(context.Background(), UserMessage("hi")); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := model.req.GenerationConfig; got == nil || *got.Temperature != agentTemp {
+		t.Fatalf("agent config = %+v, want temperature %v", got, agentTemp)
+	}
+
+	_, err = runner.Run(context.Background(), UserMessage("hi"), WithRunGenerationConfig(GenerationConfig{
+		Temperature: &runTemp,
+		ToolChoice:  &ToolChoice{Mode: ToolChoiceNone},
+	}))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	got := model.req.GenerationConfig
+	if *got.Temperature != runTemp || *got.TopP != topP || !slices.Equal(got.StopSequences, []string{"END"}) {
+		t.Fatalf("merged config = %+v", got)
+	}
+	if got.ToolChoice == nil || got.ToolChoice.Mode != ToolChoiceNone {
+		t.Fatalf("tool choice = %+v, want none", got.ToolChoice)
+	}
+}
+
+func TestRunGenerationConfigReachesAgentTools(t *testing.T) {
+	t.Parallel()
+
+	temp := 0.3
+	subModel := &captureModel{}
+	sub, err := NewAgent("sub", WithModel(subModel))
+	if err != nil {
+		t.Fatalf("new sub agent: %v", err)
+	}
+	root, err := NewAgent("root", WithModel(&usageModel{name: "m", tool: "sub"}), WithTools(NewAgentTool(sub)))
+	if err != nil {
+		t.Fatalf("new root agent: %v", err)
+	}
+	_, err = NewRunner(root).Run(context.Background(), UserMessage("hi"), WithRunGenerationConfig(GenerationConfig{
+		Temperature: &temp,
+		ToolChoice:  &ToolChoice{Mode: ToolChoiceTool, Name: "sub"},
+	}))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	got := subModel.req.GenerationConfig
+	if got == nil || got.Temperature == nil || *got.Temperature != temp {
+		t.Fatalf("sub-agent config = %+v, want temperature %v", got, temp)
+	}
+	if got.ToolChoice != nil {
+		t.Fatalf("sub-agent tool choice = %+v, want the caller's choice dropped", got.ToolChoice)
+	}
+}
+
+func TestGenerationConfigUnsetByDefault(t *testing.T) {
+	t.Parallel()
+
+	model := &captureModel{}
+	agent, err := NewAgent("agent", WithModel(model))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	if _, err := NewRunner(agent).Run(context.Background(), UserMessage("hi")); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if model.req.GenerationConfig != nil {
+		t.Fatalf("generation config = %+v, want nil", model.req.GenerationConfig)
+	}
+}
+
+func TestWithToolChoiceReleasedAfterToolCall(t *testing.T) {
+	t.Parallel()
+
+	model := &toolChoiceModel{usageModel: usageModel{name: "m", tool: "lookup"}}
+	tool := bladestools.NewTool("lookup", "look up", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "found", nil
+	}))
+	agent, err := NewAgent("agent",
+		WithModel(model),
+		WithTools(tool),
+		WithGenerationConfig(GenerationConfig{ToolChoice: &ToolChoice{Mode: ToolChoiceNone}}),
+		WithToolChoice(ToolChoice{Mode: ToolChoiceTool, Name: "lookup"}),
+	)
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	if _, err := NewRunner(agent).Run(context.Background(), UserMessage("find it")); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if want := []ToolChoiceMode{ToolChoiceTool, ToolChoiceAuto}; !slices.Equal(model.choices, want) {
+		t.Fatalf("tool choices = %v, want %v", model.choices, want)
+	}
+}
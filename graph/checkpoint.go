@@ -3,6 +3,7 @@ package graph
 import (
 	"context"
 	"maps"
+	"slices"
 )
 
 // Checkpointer persists and restores checkpoints for a task identified by checkpointID.
@@ -18,16 +19,76 @@ type Checkpoint struct {
 	ID       string          `json:"id"`
 	Received map[string]int  `json:"received"`
 	Visited  map[string]bool `json:"visited"`
-	State    map[string]any  `json:"state"`
+	// Visits and Steps count the node executions, so resumed runs keep
+	// their step limits.
+	Visits map[string]int `json:"visits,omitempty"`
+	Steps  int            `json:"steps,omitempty"`
+	// Interrupts maps the nodes that interrupted the run to their payloads.
+	Interrupts map[string]any `json:"interrupts,omitempty"`
+	// FanOuts records the fan-outs in progress, by target node.
+	FanOuts map[string]*FanOutProgress `json:"fanOuts,omitempty"`
+	State   map[string]any             `json:"state"`
+}
+
+// FanOutProgress records the instances of a fan-out target.
+type FanOutProgress struct {
+	// Inputs are the inputs of the instances.
+	Inputs []map[string]any `json:"inputs"`
+	// Done marks the completed instances.
+	Done []bool `json:"done"`
+	// Outputs are the outputs of the completed instances, merged into the
+	// state in order once all instances complete.
+	Outputs []map[string]any `json:"outputs"`
+	// Interrupted marks the instances that raised Interrupt.
+	Interrupted []bool `json:"interrupted,omitempty"`
+	// Visit is the visit of the target the instances belong to.
+	Visit int `json:"visit,omitempty"`
+}
+
+// Clone returns a deep copy of the progress.
+func (p *FanOutProgress) Clone() *FanOutProgress {
+	return &FanOutProgress{
+		Inputs:      cloneStates(p.Inputs),
+		Done:        slices.Clone(p.Done),
+		Outputs:     cloneStates(p.Outputs),
+		Interrupted: slices.Clone(p.Interrupted),
+		Visit:       p.Visit,
+	}
 }
 
 // Clone returns a deep copy of the checkpoint so callers can modify it without
 // affecting the original snapshot.
 func (c *Checkpoint) Clone() *Checkpoint {
 	return &Checkpoint{
-		ID:       c.ID,
-		Received: maps.Clone(c.Received),
-		Visited:  maps.Clone(c.Visited),
-		State:    maps.Clone(c.State),
+		ID:         c.ID,
+		Received:   maps.Clone(c.Received),
+		Visited:    maps.Clone(c.Visited),
+		Visits:     maps.Clone(c.Visits),
+		Steps:      c.Steps,
+		Interrupts: maps.Clone(c.Interrupts),
+		FanOuts:    cloneFanOuts(c.FanOuts),
+		State:      maps.Clone(c.State),
+	}
+}
+
+func cloneStates(states []map[string]any) []map[string]any {
+	if states == nil {
+		return nil
+	}
+	out := make([]map[string]any, len(states))
+	for i, state := range states {
+		out[i] = maps.Clone(state)
+	}
+	return out
+}
+
+func cloneFanOuts(fanOuts map[string]*FanOutProgress) map[string]*FanOutProgress {
+	if fanOuts == nil {
+		return nil
+	}
+	out := make(map[string]*FanOutProgress, len(fanOuts))
+	for node, progress := range fanOuts {
+		out[node] = progress.Clone()
 	}
+	return out
 }
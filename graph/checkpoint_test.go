@@ -3,7 +3,10 @@ package graph
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -805,3 +808,187 @@ func TestCheckpointResumeMultiLevelFanOut(t *testing.T) {
 		}
 	}
 }
+
+func TestLoopResumesFromCheckpoint(t *testing.T) {
+	var failed atomic.Bool
+	g := New()
+	g.AddNode("plan", stepHandler("plan"))
+	g.AddNode("act", func(ctx context.Context, state State) (State, error) {
+		appendStep(state, "act")
+		state[valueKey] = getIntFromState(state, valueKey) + 1
+		return state, nil
+	})
+	g.AddNode("check", func(ctx context.Context, state State) (State, error) {
+		// Fail once, in the second iteration.
+		if getIntFromState(state, valueKey) == 2 && failed.CompareAndSwap(false, true) {
+			return nil, errors.New("transient")
+		}
+		appendStep(state, "check")
+		return state, nil
+	})
+	g.AddNode("done", stepHandler("done"))
+	g.AddEdge("plan", "act")
+	g.AddEdge("act", "check")
+	g.AddEdge("check", "act", WithEdgeCondition(func(ctx context.Context, state State) bool {
+		return getIntFromState(state, valueKey) < 3
+	}))
+	g.AddEdge("check", "done", WithEdgeCondition(func(ctx context.Context, state State) bool {
+		return getIntFromState(state, valueKey) >= 3
+	}))
+	g.SetEntryPoint("plan")
+	g.SetFinishPoint("done")
+
+	store := newMemoryCheckpointer()
+	exec, err := g.Compile(WithCheckpointer(store))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if _, err := exec.Execute(context.Background(), State{}, WithCheckpointID("loop")); err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+	state, err := exec.Resume(context.Background(), State{}, WithCheckpointID("loop"))
+	if err != nil {
+		t.Fatalf("resume error: %v", err)
+	}
+	want := []string{"plan", "act", "check", "act", "check", "act", "check", "done"}
+	if got := getStringSliceFromState(state, stepsKey); !reflect.DeepEqual(got, want) {
+		t.Fatalf("steps = %v, want %v", got, want)
+	}
+	checkpoints := store.snapshots("loop")
+	if cp := checkpoints[len(checkpoints)-1]; cp.Visits["act"] != 3 || cp.Steps == 0 {
+		t.Fatalf("last checkpoint visits = %v, steps = %d", cp.Visits, cp.Steps)
+	}
+}
+
+func TestStreamCheckpointsAndInterrupt(t *testing.T) {
+	store := newMemoryCheckpointer()
+	g := New()
+	g.AddNode("ask", func(ctx context.Context, state State) (State, error) {
+		answer, ok := ResumeValue(ctx)
+		if !ok {
+			return nil, Interrupt("name?")
+		}
+		return State{"name": answer}, nil
+	})
+	g.AddNode("greet", func(ctx context.Context, state State) (State, error) {
+		return State{"greeting": "hello " + state["name"].(string)}, nil
+	})
+	g.AddEdge("ask", "greet")
+	g.SetEntryPoint("ask")
+	g.SetFinishPoint("greet")
+	exec, err := g.Compile(WithCheckpointer(store))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	var (
+		payload     any
+		saved       *Checkpoint
+		interrupt   *InterruptError
+		interrupted bool
+	)
+	for event, err := range exec.Stream(context.Background(), State{}, WithCheckpointID("chat")) {
+		if err != nil {
+			interrupt, interrupted = IsInterrupt(err)
+			continue
+		}
+		switch event.Type {
+		case EventNodeInterrupted:
+			payload = event.Value
+		case EventCheckpointSaved:
+			saved = event.Checkpoint
+		}
+	}
+	if !interrupted || interrupt.Node != "ask" || payload != "name?" {
+		t.Fatalf("interrupt = %v, payload = %v", interrupt, payload)
+	}
+	if saved == nil || saved.Interrupts["ask"] != "name?" {
+		t.Fatalf("saved checkpoint = %+v", saved)
+	}
+
+	var final State
+	for event, err := range exec.ResumeStream(context.Background(), State{}, WithCheckpointID("chat"), WithResumeValue("ask", "ada")) {
+		if err != nil {
+			t.Fatalf("resume stream error: %v", err)
+		}
+		if event.Type == EventGraphFinished {
+			final = event.State
+		}
+	}
+	if final["greeting"] != "hello ada" {
+		t.Fatalf("final state = %v", final)
+	}
+}
+
+func TestFanOutResumesPartialProgress(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		var (
+			mu     sync.Mutex
+			runs   = make(map[string]int)
+			others sync.WaitGroup
+		)
+		others.Add(3)
+		g := New(WithParallel(parallel), WithReducer("summaries", AppendReducer))
+		g.AddNode("split", stepHandler("split"))
+		g.AddNode("summarize", func(ctx context.Context, state State) (State, error) {
+			doc := state["doc"].(string)
+			mu.Lock()
+			runs[doc]++
+			run := runs[doc]
+			mu.Unlock()
+			if run == 1 && doc != "c" && parallel {
+				others.Done()
+			}
+			if run == 1 && doc == "c" {
+				// In parallel mode c fails after the other instances
+				// succeeded; sequentially it stops the run before d.
+				if parallel {
+					others.Wait()
+				}
+				return nil, errors.New("rate limited")
+			}
+			return State{"summaries": "sum(" + doc + ")"}, nil
+		})
+		g.AddNode("join", func(ctx context.Context, state State) (State, error) {
+			summaries, _ := state["summaries"].([]string)
+			return State{"report": strings.Join(summaries, ";")}, nil
+		})
+		g.AddEdge("split", "summarize", WithFanOut(func(ctx context.Context, state State) ([]State, error) {
+			var inputs []State
+			for _, doc := range state["docs"].([]string) {
+				inputs = append(inputs, State{"doc": doc})
+			}
+			return inputs, nil
+		}))
+		g.AddEdge("summarize", "join")
+		g.SetEntryPoint("split")
+		g.SetFinishPoint("join")
+
+		store := newMemoryCheckpointer()
+		exec, err := g.Compile(WithCheckpointer(store))
+		if err != nil {
+			t.Fatalf("compile error: %v", err)
+		}
+		docs := State{"docs": []string{"a", "b", "c", "d"}}
+		if _, err := exec.Execute(context.Background(), docs, WithCheckpointID("docs")); err == nil {
+			t.Fatalf("parallel=%v expected the first run to fail", parallel)
+		}
+		checkpoints := store.snapshots("docs")
+		progress := checkpoints[len(checkpoints)-1].FanOuts["summarize"]
+		if progress == nil || progress.Done[2] || !progress.Done[0] {
+			t.Fatalf("parallel=%v fan-out progress = %+v", parallel, progress)
+		}
+
+		state, err := exec.Resume(context.Background(), State{}, WithCheckpointID("docs"))
+		if err != nil {
+			t.Fatalf("parallel=%v resume error: %v", parallel, err)
+		}
+		if state["report"] != "sum(a);sum(b);sum(c);sum(d)" {
+			t.Fatalf("parallel=%v report = %v", parallel, state["report"])
+		}
+		// Only the failed instance runs again.
+		if want := map[string]int{"a": 1, "b": 1, "c": 2, "d": 1}; !reflect.DeepEqual(runs, want) {
+			t.Fatalf("parallel=%v runs = %v, want %v", parallel, runs, want)
+		}
+	}
+}
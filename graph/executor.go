@@ -2,15 +2,22 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
+	"slices"
 )
 
+// ErrMaxStepsExceeded is returned when a run exceeds the step limit of the
+// graph or the visit limit of a node.
+var ErrMaxStepsExceeded = errors.New("graph: maximum steps exceeded")
+
 // ExecuteOption defines an option for the Execute method.
 type ExecuteOption func(*executeOptions)
 
 type executeOptions struct {
 	CheckpointID string
+	ResumeValues map[string]any
 }
 
 // WithCheckpointID sets a specific CheckpointID for the execution.
@@ -24,9 +31,22 @@ func WithCheckpointID(CheckpointID string) ExecuteOption {
 type nodeInfo struct {
 	outEdges           []conditionalEdge // Precomputed outgoing edges
 	unconditionalDests []string          // Target names for unconditional edges
-	dependencies       int               // Number of dependencies (predecessor count)
+	dependencies       int               // Number of dependencies (predecessor count), loop edges excluded
+	predecessors       []string          // Sources of the incoming edges, loop edges excluded
+	loops              map[string]*loop  // Loops started by the outgoing loop edges, by target
 	isFinish           bool              // Whether this is the finish node
 	hasConditions      bool              // Whether outgoing edges carry conditions
+	hasFanOuts         bool              // Whether outgoing edges fan out
+	maxVisits          int               // Maximum executions per run; 0 means unlimited
+	rank               int               // Position in the topological order, loop edges excluded
+}
+
+// loop describes the iteration started by taking a loop edge.
+type loop struct {
+	target string
+	// reset maps every node re-run by the loop, the target and its
+	// descendants, to the number of its incoming edges from other re-run nodes.
+	reset map[string]int
 }
 
 // Executor represents a compiled graph ready for execution. It is safe for
@@ -39,19 +59,29 @@ type Executor struct {
 
 // NewExecutor creates a new Executor for the given graph.
 func NewExecutor(g *Graph, checkpointer Checkpointer) *Executor {
+	loopEdges := g.findLoopEdges()
 	dependencyCounts := make(map[string]int)
-	for _, edges := range g.edges {
+	predecessors := make(map[string][]string)
+	for from, edges := range g.edges {
 		for _, edge := range edges {
+			if loopEdges[loopEdge{from: from, to: edge.to}] {
+				continue
+			}
 			dependencyCounts[edge.to]++
+			predecessors[edge.to] = append(predecessors[edge.to], from)
 		}
 	}
+	ranks := topologicalRanks(g, loopEdges, dependencyCounts)
 	// Build nodeInfo map with precomputed data
 	nodeInfos := make(map[string]*nodeInfo, len(g.nodes))
 	for nodeName := range g.nodes {
 		rawEdges := cloneEdges(g.edges[nodeName])
-		hasConditions := false
+		hasConditions, hasFanOuts := false, false
 		unconditionalDests := make([]string, 0, len(rawEdges))
 		for _, edge := range rawEdges {
+			if edge.fanOut != nil {
+				hasFanOuts = true
+			}
 			if edge.condition != nil {
 				hasConditions = true
 			} else {
@@ -62,8 +92,20 @@ func NewExecutor(g *Graph, checkpointer Checkpointer) *Executor {
 			outEdges:           rawEdges,
 			unconditionalDests: unconditionalDests,
 			dependencies:       dependencyCounts[nodeName],
+			predecessors:       predecessors[nodeName],
 			isFinish:           nodeName == g.finishPoint,
 			hasConditions:      hasConditions,
+			hasFanOuts:         hasFanOuts,
+			maxVisits:          g.nodeConfigs[nodeName].maxVisits,
+			rank:               ranks[nodeName],
+		}
+		for _, edge := range rawEdges {
+			if loopEdges[loopEdge{from: nodeName, to: edge.to}] {
+				if node.loops == nil {
+					node.loops = make(map[string]*loop)
+				}
+				node.loops[edge.to] = newLoop(g, edge.to, loopEdges, predecessors)
+			}
 		}
 		nodeInfos[nodeName] = node
 	}
@@ -74,33 +116,100 @@ func NewExecutor(g *Graph, checkpointer Checkpointer) *Executor {
 	}
 }
 
-// Execute runs the graph task starting from the given state.
+// Execute runs the graph task starting from the given state. If a node
+// raises Interrupt, the run stops once the running nodes finish, saves a
+// checkpoint and returns the state so far with an *InterruptError.
 func (e *Executor) Execute(ctx context.Context, state State, opts ...ExecuteOption) (State, error) {
-	o := executeOptions{}
-	for _, opt := range opts {
-		opt(&o)
-	}
+	o := newExecuteOptions(opts)
 	t := newTask(e, state, e.checkpointer, o.CheckpointID)
 	return t.run(ctx, nil)
 }
 
 // Resume continues a previously started task using the configured Checkpointer.
+// After an interrupt, WithResumeValue delivers the answers to the interrupted
+// nodes, which run again.
 func (e *Executor) Resume(ctx context.Context, state State, opts ...ExecuteOption) (State, error) {
+	task, checkpoint, err := e.resumeTask(ctx, state, newExecuteOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return task.run(ctx, checkpoint)
+}
+
+func newExecuteOptions(opts []ExecuteOption) executeOptions {
 	o := executeOptions{}
 	for _, opt := range opts {
 		opt(&o)
 	}
+	return o
+}
+
+// resumeTask loads the checkpoint of a resumed run and prepares its task.
+func (e *Executor) resumeTask(ctx context.Context, state State, o executeOptions) (*Task, *Checkpoint, error) {
 	if e.checkpointer == nil {
-		return nil, fmt.Errorf("graph: no checkpointer configured")
+		return nil, nil, fmt.Errorf("graph: no checkpointer configured")
 	}
 	checkpoint, err := e.checkpointer.Resume(ctx, o.CheckpointID)
 	if err != nil {
-		return nil, fmt.Errorf("graph: failed to load checkpoint: %w", err)
+		return nil, nil, fmt.Errorf("graph: failed to load checkpoint: %w", err)
 	}
 	// Merge checkpoint state with provided state (provided values override checkpoint)
 	maps.Copy(checkpoint.State, state)
 	task := newTask(e, checkpoint.State, e.checkpointer, o.CheckpointID)
-	return task.run(ctx, checkpoint)
+	for node, value := range o.ResumeValues {
+		if _, ok := checkpoint.Interrupts[node]; !ok {
+			return nil, nil, fmt.Errorf("graph: resume value for node %s, which is not interrupted", node)
+		}
+		if task.resumeValues == nil {
+			task.resumeValues = make(map[string]any, len(o.ResumeValues))
+		}
+		task.resumeValues[node] = value
+	}
+	return task, checkpoint, nil
+}
+
+// newLoop returns the loop re-running target and its descendants.
+func newLoop(g *Graph, target string, loopEdges map[loopEdge]bool, predecessors map[string][]string) *loop {
+	nodes := g.forwardReachable(target, loopEdges)
+	reset := make(map[string]int, len(nodes))
+	for node := range nodes {
+		count := 0
+		for _, from := range predecessors[node] {
+			if nodes[from] {
+				count++
+			}
+		}
+		reset[node] = count
+	}
+	return &loop{target: target, reset: reset}
+}
+
+// topologicalRanks orders the nodes topologically, ignoring loop edges and
+// breaking ties by name, and returns the position of every node.
+func topologicalRanks(g *Graph, loopEdges map[loopEdge]bool, dependencyCounts map[string]int) map[string]int {
+	remaining := maps.Clone(dependencyCounts)
+	var ready []string
+	for name := range g.nodes {
+		if remaining[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	ranks := make(map[string]int, len(g.nodes))
+	for len(ready) > 0 {
+		slices.Sort(ready)
+		node := ready[0]
+		ready = ready[1:]
+		ranks[node] = len(ranks)
+		for _, edge := range g.edges[node] {
+			if loopEdges[loopEdge{from: node, to: edge.to}] {
+				continue
+			}
+			if remaining[edge.to]--; remaining[edge.to] == 0 {
+				ready = append(ready, edge.to)
+			}
+		}
+	}
+	return ranks
 }
 
 // cloneEdges creates a copy of edge slice to avoid shared state issues.
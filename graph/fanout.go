@@ -0,0 +1,253 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// fanOut tracks the instances of a fan-out target.
+type fanOut struct {
+	inputs      []State
+	done        []bool
+	outputs     []State
+	running     []bool
+	interrupted []bool
+	// visit is the visit of the target the instances belong to; 0 until the
+	// first instance starts.
+	visit int
+}
+
+func newFanOut(inputs []State) *fanOut {
+	return &fanOut{
+		inputs:      inputs,
+		done:        make([]bool, len(inputs)),
+		outputs:     make([]State, len(inputs)),
+		running:     make([]bool, len(inputs)),
+		interrupted: make([]bool, len(inputs)),
+	}
+}
+
+func restoreFanOut(p *FanOutProgress) *fanOut {
+	inputs := make([]State, len(p.Inputs))
+	for i, input := range p.Inputs {
+		inputs[i] = State(input)
+	}
+	f := newFanOut(inputs)
+	copy(f.done, p.Done)
+	for i, output := range p.Outputs {
+		if i < len(f.outputs) {
+			f.outputs[i] = State(output)
+		}
+	}
+	copy(f.interrupted, p.Interrupted)
+	f.visit = p.Visit
+	return f
+}
+
+// progress returns the checkpointed form of the fan-out.
+func (f *fanOut) progress() *FanOutProgress {
+	p := &FanOutProgress{
+		Inputs:  make([]map[string]any, len(f.inputs)),
+		Done:    slices.Clone(f.done),
+		Outputs: make([]map[string]any, len(f.outputs)),
+		Visit:   f.visit,
+	}
+	for i := range f.inputs {
+		p.Inputs[i] = maps.Clone(f.inputs[i])
+		p.Outputs[i] = maps.Clone(f.outputs[i])
+	}
+	if slices.Contains(f.interrupted, true) {
+		p.Interrupted = slices.Clone(f.interrupted)
+	}
+	return p
+}
+
+func (f *fanOut) completed() bool {
+	return !slices.Contains(f.done, false)
+}
+
+// activate takes the edge from node. A fan-out edge first computes the
+// inputs of the instances of its target. It returns false if the run failed.
+func (t *Task) activate(ctx context.Context, node string, edge conditionalEdge, state State) bool {
+	if edge.fanOut != nil {
+		inputs, err := edge.fanOut(ctx, state)
+		if err != nil {
+			t.fail(fmt.Errorf("graph: failed to fan out from node %s to %s: %w", node, edge.to, err))
+			return false
+		}
+		t.mu.Lock()
+		if t.fanOuts == nil {
+			t.fanOuts = make(map[string]*fanOut)
+		}
+		t.fanOuts[edge.to] = newFanOut(inputs)
+		t.mu.Unlock()
+	}
+	t.satisfy(node, edge.to, true)
+	return true
+}
+
+// scheduleFanOutLocked starts the pending instances of a fan-out target, all
+// of them in parallel mode and one at a time otherwise. A target whose
+// instances are all done completes. It is called with t.mu held and releases it.
+func (t *Task) scheduleFanOutLocked(ctx context.Context, node string, f *fanOut) {
+	parallel := t.executor.graph.parallel
+	if f.visit == 0 {
+		t.visits[node]++
+		f.visit = t.visits[node]
+	}
+	var pending []int
+	for i := range f.inputs {
+		if !f.done[i] && !f.running[i] {
+			pending = append(pending, i)
+			if !parallel {
+				break
+			}
+		}
+	}
+	if len(pending) == 0 {
+		if f.completed() {
+			t.completeFanOutLocked(ctx, node)
+			return
+		}
+		t.mu.Unlock()
+		return
+	}
+	t.steps += len(pending)
+	if err := t.checkLimitsLocked(node); err != nil {
+		t.mu.Unlock()
+		t.fail(err)
+		return
+	}
+
+	state := t.state.ToMap()
+	runs := make([]func(), 0, len(pending))
+	for _, i := range pending {
+		input := maps.Clone(state)
+		maps.Copy(input, f.inputs[i])
+		instanceCtx := ctx
+		// Deliver the resume value to an instance re-running after its interrupt
+		if value, ok := t.resumeValues[node]; ok && f.interrupted[i] {
+			f.interrupted[i] = false
+			instanceCtx = context.WithValue(ctx, ctxResumeKey{}, resumeValue{value: value})
+		}
+		f.running[i] = true
+		t.inFlight[node]++
+		t.wg.Add(1)
+		runs = append(runs, func() {
+			defer t.nodeDone(node)
+			t.executeInstance(instanceCtx, node, i, input)
+		})
+	}
+	t.mu.Unlock()
+
+	for _, run := range runs {
+		if parallel {
+			go run()
+		} else {
+			run()
+		}
+	}
+}
+
+// executeInstance runs an instance of a fan-out target and completes the
+// target after its last instance. The outputs of the instances are kept
+// until then, so they are merged in the order of the instances whatever
+// order they complete in, even across resumed runs.
+func (t *Task) executeInstance(ctx context.Context, node string, index int, state State) {
+	_, resumed := ResumeValue(ctx)
+	t.mu.Lock()
+	if t.err != nil || t.finished || (len(t.interrupts) > 0 && !resumed) {
+		t.mu.Unlock()
+		return
+	}
+	t.mu.Unlock()
+
+	_, updates, err := t.runHandler(ctx, node, state)
+	if interrupt, ok := IsInterrupt(err); ok {
+		t.interrupt(node, index, interrupt)
+		return
+	}
+	if err != nil {
+		t.fail(fmt.Errorf("graph: failed to execute node %s instance %d: %w", node, index, err))
+		return
+	}
+
+	t.mu.Lock()
+	f := t.fanOuts[node]
+	f.running[index] = false
+	f.done[index] = true
+	f.outputs[index] = updates
+	t.progressSinceCheckpoint = true
+	if !f.completed() {
+		// In sequential mode the next instance is scheduled like a node.
+		if !slices.Contains(f.running, true) {
+			t.ready = append(t.ready, node)
+			t.readyCond.Signal()
+		}
+		t.mu.Unlock()
+		return
+	}
+	t.completeFanOutLocked(ctx, node)
+}
+
+// completeFanOutLocked merges the outputs of the instances of the fan-out
+// target, marks it visited and takes its outgoing edges, whose conditions see
+// the merged state. It is called with t.mu held and releases it.
+func (t *Task) completeFanOutLocked(ctx context.Context, node string) {
+	f := t.fanOuts[node]
+	for i, output := range f.outputs {
+		if err := t.mergeLocked(node, i, output); err != nil {
+			t.mu.Unlock()
+			t.fail(err)
+			return
+		}
+	}
+	delete(t.fanOuts, node)
+	delete(t.resumeValues, node)
+	t.visited[node] = true
+	t.progressSinceCheckpoint = true
+	info := t.executor.nodeInfos[node]
+	if info.isFinish {
+		if !t.finished {
+			t.finished = true
+			t.readyCond.Broadcast()
+		}
+		t.mu.Unlock()
+		return
+	}
+	state := t.state.ToMap()
+	t.mu.Unlock()
+	t.processOutgoing(ctx, node, info, state)
+}
+
+// saveFanOutProgress saves a checkpoint of a failed run whose fan-outs have
+// completed instances, so a resumed run does not repeat them. All nodes have
+// stopped by then, so the checkpoint is consistent.
+func (t *Task) saveFanOutProgress(ctx context.Context) {
+	t.mu.Lock()
+	if t.checkpointer == nil || t.checkpointID == "" || !t.progressSinceCheckpoint || len(t.pendingLoops) > 0 {
+		t.mu.Unlock()
+		return
+	}
+	partial := false
+	for _, f := range t.fanOuts {
+		if slices.Contains(f.done, true) {
+			partial = true
+		}
+	}
+	if !partial {
+		t.mu.Unlock()
+		return
+	}
+	checkpoint := t.checkpointLocked()
+	t.progressSinceCheckpoint = false
+	t.mu.Unlock()
+
+	// The run may have failed because ctx was canceled.
+	if err := t.checkpointer.Save(context.WithoutCancel(ctx), checkpoint); err != nil {
+		return
+	}
+	t.event(&Event{Type: EventCheckpointSaved, Checkpoint: checkpoint.Clone()})
+}
@@ -0,0 +1,155 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFanOutMapsDocuments(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		var (
+			mu   sync.Mutex
+			runs = make(map[string]int)
+		)
+		g := New(WithParallel(parallel), WithReducer("summaries", AppendReducer))
+		g.AddNode("split", stepHandler("split"))
+		g.AddNode("summarize", func(ctx context.Context, state State) (State, error) {
+			doc := state["doc"].(string)
+			mu.Lock()
+			runs[doc]++
+			mu.Unlock()
+			return State{"summaries": "sum(" + doc + ")"}, nil
+		})
+		g.AddNode("join", func(ctx context.Context, state State) (State, error) {
+			summaries, _ := state["summaries"].([]string)
+			return State{"report": strings.Join(summaries, ";")}, nil
+		})
+		g.AddEdge("split", "summarize", WithFanOut(func(ctx context.Context, state State) ([]State, error) {
+			var inputs []State
+			for _, doc := range state["docs"].([]string) {
+				inputs = append(inputs, State{"doc": doc})
+			}
+			return inputs, nil
+		}))
+		g.AddEdge("summarize", "join")
+		g.SetEntryPoint("split")
+		g.SetFinishPoint("join")
+
+		exec, err := g.Compile()
+		if err != nil {
+			t.Fatalf("compile error: %v", err)
+		}
+		state, err := exec.Execute(context.Background(), State{"docs": []string{"a", "b", "c", "d"}})
+		if err != nil {
+			t.Fatalf("parallel=%v execute error: %v", parallel, err)
+		}
+		if state["report"] != "sum(a);sum(b);sum(c);sum(d)" {
+			t.Fatalf("parallel=%v report = %v", parallel, state["report"])
+		}
+		if want := map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}; !reflect.DeepEqual(runs, want) {
+			t.Fatalf("parallel=%v runs = %v", parallel, runs)
+		}
+	}
+}
+
+func TestFanOutWithoutInputs(t *testing.T) {
+	var runs int32
+	g := New()
+	g.AddNode("split", stepHandler("split"))
+	g.AddNode("summarize", func(ctx context.Context, state State) (State, error) {
+		atomic.AddInt32(&runs, 1)
+		return state, nil
+	})
+	g.AddNode("join", stepHandler("join"))
+	g.AddEdge("split", "summarize", WithFanOut(func(ctx context.Context, state State) ([]State, error) {
+		return nil, nil
+	}))
+	g.AddEdge("summarize", "join")
+	g.SetEntryPoint("split")
+	g.SetFinishPoint("join")
+
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	state, err := exec.Execute(context.Background(), State{})
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if steps := getStringSliceFromState(state, stepsKey); !reflect.DeepEqual(steps, []string{"split", "join"}) || runs != 0 {
+		t.Fatalf("steps = %v, summarize runs = %d", steps, runs)
+	}
+}
+
+func TestFanOutInterruptedInstance(t *testing.T) {
+	g := New(WithReducer("summaries", AppendReducer))
+	g.AddNode("split", stepHandler("split"))
+	g.AddNode("summarize", func(ctx context.Context, state State) (State, error) {
+		doc := state["doc"].(string)
+		answer, ok := ResumeValue(ctx)
+		if doc != "b" {
+			if ok {
+				return nil, errors.New("resume value delivered to " + doc)
+			}
+			return State{"summaries": "sum(" + doc + ")"}, nil
+		}
+		if !ok {
+			return nil, Interrupt("summarize " + doc + "?")
+		}
+		return State{"summaries": "sum(b:" + answer.(string) + ")"}, nil
+	})
+	g.AddNode("join", func(ctx context.Context, state State) (State, error) {
+		summaries, _ := state["summaries"].([]string)
+		return State{"report": strings.Join(summaries, ";")}, nil
+	})
+	g.AddEdge("split", "summarize", WithFanOut(func(ctx context.Context, state State) ([]State, error) {
+		var inputs []State
+		for _, doc := range state["docs"].([]string) {
+			inputs = append(inputs, State{"doc": doc})
+		}
+		return inputs, nil
+	}))
+	g.AddEdge("summarize", "join")
+	g.SetEntryPoint("split")
+	g.SetFinishPoint("join")
+
+	exec, err := g.Compile(WithCheckpointer(newMemoryCheckpointer()))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	_, err = exec.Execute(context.Background(), State{"docs": []string{"a", "b", "c"}}, WithCheckpointID("docs"))
+	if interrupt, ok := IsInterrupt(err); !ok || interrupt.Node != "summarize" || interrupt.Payload != "summarize b?" {
+		t.Fatalf("execute error = %v, want interrupt of summarize", err)
+	}
+	state, err := exec.Resume(context.Background(), State{}, WithCheckpointID("docs"), WithResumeValue("summarize", "short"))
+	if err != nil {
+		t.Fatalf("resume error: %v", err)
+	}
+	if state["report"] != "sum(a);sum(b:short);sum(c)" {
+		t.Fatalf("report = %v", state["report"])
+	}
+}
+
+func TestFanOutTargetWithOtherIncomingEdge(t *testing.T) {
+	g := New()
+	g.AddNode("split", stepHandler("split"))
+	g.AddNode("extra", stepHandler("extra"))
+	g.AddNode("summarize", stepHandler("summarize"))
+	g.AddEdge("split", "summarize", WithFanOut(func(ctx context.Context, state State) ([]State, error) {
+		return []State{{}}, nil
+	}))
+	g.AddEdge("split", "extra")
+	g.AddEdge("extra", "summarize")
+	g.SetEntryPoint("split")
+	g.SetFinishPoint("summarize")
+
+	_, err := g.Compile()
+	if err == nil || !strings.Contains(err.Error(), "fan-out target 'summarize' cannot have other incoming edges") {
+		t.Fatalf("compile error = %v", err)
+	}
+}
@@ -1,11 +1,16 @@
 package graph
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 )
 
+// defaultMaxSteps bounds the node executions of a single run.
+const defaultMaxSteps = 1000
+
 // Option configures the Graph behavior.
 type Option func(*Graph)
 
@@ -23,6 +28,32 @@ func WithMiddleware(ms ...Middleware) Option {
 	}
 }
 
+// WithMaxSteps limits the number of node executions of a single run, which
+// bounds the iterations of loops. Runs exceeding it fail with an error
+// wrapping ErrMaxStepsExceeded. Defaults to 1000; n <= 0 removes the limit.
+func WithMaxSteps(n int) Option {
+	return func(g *Graph) {
+		g.maxSteps = n
+	}
+}
+
+// NodeOption configures a node before it is added to the graph.
+type NodeOption func(*nodeConfig)
+
+// WithMaxVisits limits how many times the node runs in a single run, failing
+// the run with an error wrapping ErrMaxStepsExceeded beyond it. By default a
+// node is only bounded by WithMaxSteps.
+func WithMaxVisits(n int) NodeOption {
+	return func(cfg *nodeConfig) {
+		cfg.maxVisits = n
+	}
+}
+
+// nodeConfig holds the options of a node.
+type nodeConfig struct {
+	maxVisits int
+}
+
 // EdgeCondition is a function that determines if an edge should be followed based on the current state.
 type EdgeCondition func(ctx context.Context, state State) bool
 
@@ -36,20 +67,51 @@ func WithEdgeCondition(condition EdgeCondition) EdgeOption {
 	}
 }
 
+// FanOutFunc returns the inputs of the instances of a fan-out target, one
+// input per instance, from the output of the edge's source node.
+type FanOutFunc func(ctx context.Context, state State) ([]State, error)
+
+// WithFanOut makes the edge start one instance of its target per input
+// returned by fn when the edge is taken. Every instance runs the target's
+// handler with the state merged with its input, in parallel unless the graph
+// runs sequentially. The target completes once all its instances have, so
+// nodes after it wait for all of them. The outputs of the instances are then
+// merged into the state in the order of the inputs; declare reducers with
+// WithReducer to combine them. With no input the target completes without
+// running. Checkpoints record the completed instances, so a resumed run only
+// re-runs the others.
+//
+// The target of a fan-out edge must have no other incoming edge.
+func WithFanOut(fn FanOutFunc) EdgeOption {
+	return func(edge *conditionalEdge) {
+		edge.fanOut = fn
+	}
+}
+
 // conditionalEdge represents an edge with an optional condition.
 type conditionalEdge struct {
 	to        string
 	condition EdgeCondition // nil means always follow this edge
+	fanOut    FanOutFunc    // non-nil runs the target once per input
+}
+
+// loopEdge identifies an edge that closes a cycle.
+type loopEdge struct {
+	from string
+	to   string
 }
 
 // Graph represents a directed graph of processing nodes.
-// Cycles are rejected at compile time.
+// Cycles are allowed when they contain a conditional edge to leave them.
 type Graph struct {
 	nodes       map[string]Handler
+	nodeConfigs map[string]nodeConfig
 	edges       map[string][]conditionalEdge
 	entryPoint  string
 	finishPoint string
 	parallel    bool
+	maxSteps    int
+	reducers    map[string]Reducer
 	middlewares []Middleware
 }
 
@@ -70,9 +132,11 @@ func WithCheckpointer(checkpointer Checkpointer) CompileOption {
 // New creates a new Graph instance with the provided options.
 func New(opts ...Option) *Graph {
 	g := &Graph{
-		nodes:    make(map[string]Handler),
-		edges:    make(map[string][]conditionalEdge),
-		parallel: true,
+		nodes:       make(map[string]Handler),
+		nodeConfigs: make(map[string]nodeConfig),
+		edges:       make(map[string][]conditionalEdge),
+		parallel:    true,
+		maxSteps:    defaultMaxSteps,
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -82,13 +146,18 @@ func New(opts ...Option) *Graph {
 	return g
 }
 
-// AddNode adds a named node with its handler to the graph.
+// AddNode adds a named node with its handler to the graph. Options can configure the node.
 // Returns the graph for chaining.
-func (g *Graph) AddNode(name string, handler Handler) *Graph {
+func (g *Graph) AddNode(name string, handler Handler, opts ...NodeOption) *Graph {
 	if _, ok := g.nodes[name]; ok {
 		return g
 	}
+	var cfg nodeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 	g.nodes[name] = handler
+	g.nodeConfigs[name] = cfg
 	return g
 }
 
@@ -176,6 +245,32 @@ func (g *Graph) validateStructure() error {
 			return fmt.Errorf("graph: node '%s' has mixed conditional and unconditional edges", from)
 		}
 	}
+	return g.validateFanOuts()
+}
+
+// validateFanOuts verifies that fan-out targets are only entered through
+// their fan-out edge.
+func (g *Graph) validateFanOuts() error {
+	incoming := make(map[string]int, len(g.nodes))
+	for _, edges := range g.edges {
+		for _, edge := range edges {
+			incoming[edge.to]++
+		}
+	}
+	loops := g.findLoopEdges()
+	for from, edges := range g.edges {
+		for _, edge := range edges {
+			if edge.fanOut == nil {
+				continue
+			}
+			if edge.to == g.entryPoint || incoming[edge.to] > 1 {
+				return fmt.Errorf("graph: fan-out target '%s' cannot have other incoming edges", edge.to)
+			}
+			if loops[loopEdge{from: from, to: edge.to}] {
+				return fmt.Errorf("graph: fan-out edge from '%s' to '%s' cannot close a cycle", from, edge.to)
+			}
+		}
+	}
 	return nil
 }
 
@@ -203,51 +298,114 @@ func (g *Graph) ensureReachable() error {
 	return fmt.Errorf("graph: finish node not reachable: %s", g.finishPoint)
 }
 
-// ensureAcyclic verifies that the graph does not contain directed cycles.
-func (g *Graph) ensureAcyclic() error {
+// findLoopEdges returns the edges closing a cycle: the edges leading back to
+// a node on the path of a depth-first search. The search starts at the entry
+// point and visits the other nodes in name order, so loop edges are the ones
+// returning to the earliest node of each cycle.
+func (g *Graph) findLoopEdges() map[loopEdge]bool {
 	const (
 		stateUnvisited = iota
 		stateVisiting
 		stateVisited
 	)
 	states := make(map[string]int, len(g.nodes))
-	stack := make([]string, 0, len(g.nodes))
+	loops := make(map[loopEdge]bool)
 
-	var visit func(string) error
-	visit = func(node string) error {
+	var visit func(string)
+	visit = func(node string) {
 		states[node] = stateVisiting
-		stack = append(stack, node)
-
 		for _, edge := range g.edges[node] {
-			next := edge.to
-			switch states[next] {
+			switch states[edge.to] {
 			case stateVisiting:
-				cycleStart := 0
-				for i, name := range stack {
-					if name == next {
-						cycleStart = i
-						break
-					}
-				}
-				cycle := append(append([]string{}, stack[cycleStart:]...), next)
-				return fmt.Errorf("graph: cycles are not supported (cycle: %s)", strings.Join(cycle, " -> "))
+				loops[loopEdge{from: node, to: edge.to}] = true
 			case stateUnvisited:
-				if err := visit(next); err != nil {
-					return err
-				}
+				visit(edge.to)
 			}
 		}
-
-		stack = stack[:len(stack)-1]
 		states[node] = stateVisited
-		return nil
 	}
 
+	names := make([]string, 0, len(g.nodes))
 	for name := range g.nodes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if _, ok := g.nodes[g.entryPoint]; ok {
+		visit(g.entryPoint)
+	}
+	for _, name := range names {
 		if states[name] == stateUnvisited {
-			if err := visit(name); err != nil {
-				return err
+			visit(name)
+		}
+	}
+	return loops
+}
+
+// forwardReachable returns the nodes reachable from start without taking loop edges.
+func (g *Graph) forwardReachable(start string, loops map[loopEdge]bool) map[string]bool {
+	reached := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.edges[node] {
+			if loops[loopEdge{from: node, to: edge.to}] || reached[edge.to] {
+				continue
 			}
+			reached[edge.to] = true
+			queue = append(queue, edge.to)
+		}
+	}
+	return reached
+}
+
+// loopPath returns a path of the cycle closed by the loop edge, from its
+// target through its source and back.
+func (g *Graph) loopPath(loop loopEdge, loops map[loopEdge]bool) []string {
+	parents := map[string]string{loop.to: ""}
+	queue := []string{loop.to}
+	for len(queue) > 0 && queue[0] != loop.from {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.edges[node] {
+			if _, seen := parents[edge.to]; seen || loops[loopEdge{from: node, to: edge.to}] {
+				continue
+			}
+			parents[edge.to] = node
+			queue = append(queue, edge.to)
+		}
+	}
+	path := []string{loop.to}
+	for node := loop.from; node != loop.to; node = parents[node] {
+		path = append(path, node)
+	}
+	slices.Reverse(path[1:])
+	return append(path, loop.to)
+}
+
+// ensureGuardedCycles verifies that every cycle contains a conditional edge,
+// so that a run can leave it.
+func (g *Graph) ensureGuardedCycles() error {
+	loops := g.findLoopEdges()
+	sorted := make([]loopEdge, 0, len(loops))
+	for loop := range loops {
+		sorted = append(sorted, loop)
+	}
+	slices.SortFunc(sorted, func(a, b loopEdge) int {
+		return cmp.Or(strings.Compare(a.from, b.from), strings.Compare(a.to, b.to))
+	})
+	for _, loop := range sorted {
+		path := g.loopPath(loop, loops)
+		guarded := false
+		for _, node := range path {
+			for _, edge := range g.edges[node] {
+				if edge.condition != nil {
+					guarded = true
+				}
+			}
+		}
+		if !guarded {
+			return fmt.Errorf("graph: cycles are not supported without a conditional edge (cycle: %s)", strings.Join(path, " -> "))
 		}
 	}
 	return nil
@@ -256,6 +414,9 @@ func (g *Graph) ensureAcyclic() error {
 // Compile validates and compiles the graph into an Executor.
 // Nodes wait for all activated incoming edges to complete before executing (join semantics).
 // An edge is "activated" when its source node executes and chooses that edge.
+// Edges closing a cycle do not count as incoming edges: taking one starts a new
+// iteration once the running nodes are done, re-running its target and every
+// node downstream of it.
 // Provide no WithCheckpointer option to disable checkpoint persistence.
 func (g *Graph) Compile(opts ...CompileOption) (*Executor, error) {
 	cfg := compileConfig{}
@@ -269,7 +430,7 @@ func (g *Graph) Compile(opts ...CompileOption) (*Executor, error) {
 		return nil, err
 	}
 	// Check for cycles before other structural checks
-	if err := g.ensureAcyclic(); err != nil {
+	if err := g.ensureGuardedCycles(); err != nil {
 		return nil, err
 	}
 	// Check reachability
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// InterruptError pauses a run at a node, for example to wait for human
+// input. A handler raises it with Interrupt; Execute and Resume then save a
+// checkpoint and return it with Node and CheckpointID set. When several nodes
+// interrupt, the returned error joins their InterruptErrors; Interrupts lists
+// them. Resuming the run re-runs the interrupted nodes, which read the answers
+// passed with WithResumeValue with ResumeValue.
+type InterruptError struct {
+	// Node is the name of the interrupted node.
+	Node string
+	// Payload is the value the node passed to Interrupt, such as a question
+	// for the human.
+	Payload any
+	// CheckpointID identifies the checkpoint to resume the run from.
+	CheckpointID string
+}
+
+func (e *InterruptError) Error() string {
+	if e.Node == "" {
+		return "graph: interrupted"
+	}
+	return fmt.Sprintf("graph: node %s interrupted", e.Node)
+}
+
+// Interrupt returns an error that pauses the run at the current node. The
+// node's output is discarded; the node runs again when the run is resumed.
+func Interrupt(payload any) error {
+	return &InterruptError{Payload: payload}
+}
+
+// IsInterrupt reports whether err is an interrupt, returning the first one if
+// so. Use Interrupts to get every interrupt of a run.
+func IsInterrupt(err error) (*InterruptError, bool) {
+	var interrupt *InterruptError
+	if errors.As(err, &interrupt) {
+		return interrupt, true
+	}
+	return nil, false
+}
+
+// Interrupts returns every interrupt in err's tree, in the order the nodes
+// raised them.
+func Interrupts(err error) []*InterruptError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *InterruptError:
+		return []*InterruptError{e}
+	case interface{ Unwrap() []error }:
+		var interrupts []*InterruptError
+		for _, err := range e.Unwrap() {
+			interrupts = append(interrupts, Interrupts(err)...)
+		}
+		return interrupts
+	default:
+		return Interrupts(errors.Unwrap(err))
+	}
+}
+
+// WithResumeValue sets the answer delivered to the interrupted node when the
+// run is resumed. Pass it once per node to answer several interrupts; nodes
+// without an answer run again without one. All interrupted fan-out instances
+// of node receive the same answer.
+func WithResumeValue(node string, value any) ExecuteOption {
+	return func(cfg *executeOptions) {
+		if cfg.ResumeValues == nil {
+			cfg.ResumeValues = make(map[string]any)
+		}
+		cfg.ResumeValues[node] = value
+	}
+}
+
+type ctxResumeKey struct{}
+
+type resumeValue struct {
+	value any
+}
+
+// ResumeValue returns the answer passed with WithResumeValue to a node
+// re-running after an interrupt. ok is false on any other run of the node.
+func ResumeValue(ctx context.Context) (value any, ok bool) {
+	v, ok := ctx.Value(ctxResumeKey{}).(resumeValue)
+	return v.value, ok
+}
@@ -0,0 +1,223 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestInterruptAndResume(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		var counters struct {
+			draft   int32
+			approve int32
+			review  int32
+			publish int32
+		}
+		g := New(WithParallel(parallel))
+		g.AddNode("draft", func(ctx context.Context, state State) (State, error) {
+			atomic.AddInt32(&counters.draft, 1)
+			state["draft"] = "hello"
+			return state, nil
+		})
+		g.AddNode("approve", func(ctx context.Context, state State) (State, error) {
+			atomic.AddInt32(&counters.approve, 1)
+			answer, ok := ResumeValue(ctx)
+			if !ok {
+				return nil, Interrupt("publish " + state["draft"].(string) + "?")
+			}
+			return State{"approved": answer}, nil
+		})
+		g.AddNode("review", func(ctx context.Context, state State) (State, error) {
+			atomic.AddInt32(&counters.review, 1)
+			return State{"reviewed": true}, nil
+		})
+		g.AddNode("publish", func(ctx context.Context, state State) (State, error) {
+			atomic.AddInt32(&counters.publish, 1)
+			state["published"] = state["approved"] == "yes" && state["reviewed"] == true
+			return state, nil
+		})
+		g.AddEdge("draft", "approve")
+		g.AddEdge("draft", "review")
+		g.AddEdge("approve", "publish")
+		g.AddEdge("review", "publish")
+		g.SetEntryPoint("draft")
+		g.SetFinishPoint("publish")
+
+		store := newMemoryCheckpointer()
+		exec, err := g.Compile(WithCheckpointer(store))
+		if err != nil {
+			t.Fatalf("compile error: %v", err)
+		}
+		state, err := exec.Execute(context.Background(), State{}, WithCheckpointID("run"))
+		interrupt, ok := IsInterrupt(err)
+		if !ok {
+			t.Fatalf("parallel=%v execute error = %v, want interrupt", parallel, err)
+		}
+		if interrupt.Node != "approve" || interrupt.Payload != "publish hello?" || interrupt.CheckpointID != "run" {
+			t.Fatalf("parallel=%v interrupt = %+v", parallel, interrupt)
+		}
+		if state["draft"] != "hello" || state["published"] != nil {
+			t.Fatalf("parallel=%v interrupted state = %v", parallel, state)
+		}
+		checkpoints := store.snapshots("run")
+		if cp := checkpoints[len(checkpoints)-1]; cp.Interrupts["approve"] != "publish hello?" {
+			t.Fatalf("parallel=%v checkpoint interrupts = %v", parallel, cp.Interrupts)
+		}
+
+		state, err = exec.Resume(context.Background(), State{}, WithCheckpointID("run"), WithResumeValue("approve", "yes"))
+		if err != nil {
+			t.Fatalf("parallel=%v resume error: %v", parallel, err)
+		}
+		if state["published"] != true {
+			t.Fatalf("parallel=%v resumed state = %v", parallel, state)
+		}
+		if counters.draft != 1 || counters.approve != 2 || counters.review != 1 || counters.publish != 1 {
+			t.Fatalf("parallel=%v unexpected counters: %+v", parallel, counters)
+		}
+	}
+}
+
+func TestResumeWithoutValueInterruptsAgain(t *testing.T) {
+	g := New()
+	g.AddNode("approve", func(ctx context.Context, state State) (State, error) {
+		answer, ok := ResumeValue(ctx)
+		if !ok {
+			return nil, Interrupt("publish?")
+		}
+		state["approved"] = answer
+		return state, nil
+	})
+	g.AddNode("publish", stepHandler("publish"))
+	g.AddEdge("approve", "publish")
+	g.SetEntryPoint("approve")
+	g.SetFinishPoint("publish")
+
+	exec, err := g.Compile(WithCheckpointer(newMemoryCheckpointer()))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if _, err := exec.Execute(context.Background(), State{}, WithCheckpointID("run")); err == nil {
+		t.Fatal("expected an interrupt")
+	}
+	_, err = exec.Resume(context.Background(), State{}, WithCheckpointID("run"))
+	if _, ok := IsInterrupt(err); !ok {
+		t.Fatalf("resume error = %v, want interrupt", err)
+	}
+	state, err := exec.Resume(context.Background(), State{}, WithCheckpointID("run"), WithResumeValue("approve", "no"))
+	if err != nil {
+		t.Fatalf("resume error: %v", err)
+	}
+	if state["approved"] != "no" || !reflect.DeepEqual(getStringSliceFromState(state, stepsKey), []string{"publish"}) {
+		t.Fatalf("resumed state = %v", state)
+	}
+}
+
+func TestInterruptsOfParallelNodes(t *testing.T) {
+	var (
+		arrived atomic.Int32
+		started = make(chan struct{})
+	)
+	g := New()
+	g.AddNode("start", stepHandler("start"))
+	for _, name := range []string{"a", "b"} {
+		g.AddNode(name, func(ctx context.Context, state State) (State, error) {
+			// Both nodes are running before either interrupts.
+			if arrived.Add(1) == 2 {
+				close(started)
+			}
+			<-started
+			answer, ok := ResumeValue(ctx)
+			if !ok {
+				return nil, Interrupt(name + "?")
+			}
+			return State{name: answer}, nil
+		})
+		g.AddEdge("start", name)
+		g.AddEdge(name, "join")
+	}
+	g.AddNode("join", func(ctx context.Context, state State) (State, error) {
+		return State{"joined": state["a"].(string) + state["b"].(string)}, nil
+	})
+	g.SetEntryPoint("start")
+	g.SetFinishPoint("join")
+
+	exec, err := g.Compile(WithCheckpointer(newMemoryCheckpointer()))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	_, err = exec.Execute(context.Background(), State{}, WithCheckpointID("run"))
+	interrupts := Interrupts(err)
+	slices.SortFunc(interrupts, func(x, y *InterruptError) int { return strings.Compare(x.Node, y.Node) })
+	if len(interrupts) != 2 || interrupts[0].Payload != "a?" || interrupts[1].Payload != "b?" {
+		t.Fatalf("interrupts = %v", err)
+	}
+	if _, err := exec.Resume(context.Background(), State{}, WithCheckpointID("run"), WithResumeValue("join", "x")); err == nil {
+		t.Fatal("resume value for a node that is not interrupted was accepted")
+	}
+
+	// Only a is answered, so b interrupts again.
+	_, err = exec.Resume(context.Background(), State{}, WithCheckpointID("run"), WithResumeValue("a", "1"))
+	if interrupts := Interrupts(err); len(interrupts) != 1 || interrupts[0].Node != "b" {
+		t.Fatalf("resume error = %v, want the interrupt of b", err)
+	}
+	state, err := exec.Resume(context.Background(), State{}, WithCheckpointID("run"), WithResumeValue("b", "2"))
+	if err != nil {
+		t.Fatalf("resume error: %v", err)
+	}
+	if state["joined"] != "12" {
+		t.Fatalf("state = %v", state)
+	}
+}
+
+func TestInterruptWithoutCheckpointer(t *testing.T) {
+	g := New()
+	g.AddNode("approve", func(ctx context.Context, state State) (State, error) {
+		return nil, Interrupt("publish?")
+	})
+	g.AddNode("publish", stepHandler("publish"))
+	g.AddEdge("approve", "publish")
+	g.SetEntryPoint("approve")
+	g.SetFinishPoint("publish")
+
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	state, err := exec.Execute(context.Background(), State{})
+	var interrupt *InterruptError
+	if !errors.As(err, &interrupt) || interrupt.Node != "approve" {
+		t.Fatalf("execute error = %v, want wrapped interrupt of approve", err)
+	}
+	if steps := getStringSliceFromState(state, stepsKey); len(steps) != 0 {
+		t.Fatalf("unexpected steps after the interrupt: %v", steps)
+	}
+}
+
+func TestRetryDoesNotRetryInterrupt(t *testing.T) {
+	var attempts int32
+	g := New(WithMiddleware(Retry(3)))
+	g.AddNode("approve", func(ctx context.Context, state State) (State, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, Interrupt("publish?")
+	})
+	g.AddNode("publish", stepHandler("publish"))
+	g.AddEdge("approve", "publish")
+	g.SetEntryPoint("approve")
+	g.SetFinishPoint("publish")
+
+	exec, err := g.Compile(WithCheckpointer(newMemoryCheckpointer()))
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	if _, err := exec.Execute(context.Background(), State{}, WithCheckpointID("run")); err == nil {
+		t.Fatal("expected an interrupt")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("approve ran %d times, want 1", got)
+	}
+}
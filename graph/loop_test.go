@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func stepHandler(name string) Handler {
+	return func(ctx context.Context, state State) (State, error) {
+		appendStep(state, name)
+		return state, nil
+	}
+}
+
+func appendStep(state State, name string) {
+	steps := getStringSliceFromState(state, stepsKey)
+	state[stepsKey] = append(slices.Clone(steps), name)
+}
+
+func TestLoopRunsUntilConditionExits(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		g := New(WithParallel(parallel))
+		g.AddNode("plan", stepHandler("plan"))
+		g.AddNode("act", func(ctx context.Context, state State) (State, error) {
+			appendStep(state, "act")
+			state[valueKey] = getIntFromState(state, valueKey) + 1
+			return state, nil
+		})
+		g.AddNode("check", stepHandler("check"))
+		g.AddNode("done", stepHandler("done"))
+		g.AddEdge("plan", "act")
+		g.AddEdge("act", "check")
+		g.AddEdge("check", "act", WithEdgeCondition(func(ctx context.Context, state State) bool {
+			return getIntFromState(state, valueKey) < 3
+		}))
+		g.AddEdge("check", "done", WithEdgeCondition(func(ctx context.Context, state State) bool {
+			return getIntFromState(state, valueKey) >= 3
+		}))
+		g.SetEntryPoint("plan")
+		g.SetFinishPoint("done")
+
+		exec, err := g.Compile()
+		if err != nil {
+			t.Fatalf("compile error: %v", err)
+		}
+		state, err := exec.Execute(context.Background(), State{})
+		if err != nil {
+			t.Fatalf("parallel=%v execute error: %v", parallel, err)
+		}
+		want := []string{"plan", "act", "check", "act", "check", "act", "check", "done"}
+		if got := getStringSliceFromState(state, stepsKey); !reflect.DeepEqual(got, want) {
+			t.Fatalf("parallel=%v steps = %v, want %v", parallel, got, want)
+		}
+	}
+}
+
+func TestLoopWithoutConditionalEdgeRejected(t *testing.T) {
+	g := New()
+	g.AddNode("A", stepHandler("A"))
+	g.AddNode("B", stepHandler("B"))
+	g.AddNode("C", stepHandler("C"))
+	g.AddEdge("A", "B")
+	g.AddEdge("B", "A")
+	g.AddEdge("B", "C")
+	g.SetEntryPoint("A")
+	g.SetFinishPoint("C")
+
+	_, err := g.Compile()
+	if err == nil || !strings.Contains(err.Error(), "A -> B -> A") {
+		t.Fatalf("compile error = %v, want unguarded cycle A -> B -> A", err)
+	}
+}
+
+func TestLoopStepLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		graph   []Option
+		act     []NodeOption
+		message string
+	}{
+		{name: "max steps", graph: []Option{WithMaxSteps(10)}, message: "exceeded"},
+		{name: "max visits", act: []NodeOption{WithMaxVisits(4)}, message: "node act ran more than 4 times"},
+	} {
+		g := New(tc.graph...)
+		g.AddNode("act", func(ctx context.Context, state State) (State, error) {
+			state[valueKey] = getIntFromState(state, valueKey) + 1
+			return state, nil
+		}, tc.act...)
+		g.AddNode("check", stepHandler("check"))
+		g.AddNode("done", stepHandler("done"))
+		g.AddEdge("act", "check")
+		g.AddEdge("check", "act", WithEdgeCondition(func(ctx context.Context, state State) bool {
+			return getIntFromState(state, valueKey) < 100
+		}))
+		g.AddEdge("check", "done", WithEdgeCondition(func(ctx context.Context, state State) bool {
+			return getIntFromState(state, valueKey) >= 100
+		}))
+		g.SetEntryPoint("act")
+		g.SetFinishPoint("done")
+
+		exec, err := g.Compile()
+		if err != nil {
+			t.Fatalf("%s: compile error: %v", tc.name, err)
+		}
+		_, err = exec.Execute(context.Background(), State{})
+		if !errors.Is(err, ErrMaxStepsExceeded) || !strings.Contains(err.Error(), tc.message) {
+			t.Fatalf("%s: execute error = %v, want ErrMaxStepsExceeded", tc.name, err)
+		}
+	}
+}
+
+func TestLoopSelfEdge(t *testing.T) {
+	g := New()
+	g.AddNode("poll", func(ctx context.Context, state State) (State, error) {
+		state[valueKey] = getIntFromState(state, valueKey) + 1
+		return state, nil
+	}, WithMaxVisits(5))
+	g.AddNode("done", stepHandler("done"))
+	g.AddEdge("poll", "poll", WithEdgeCondition(func(ctx context.Context, state State) bool {
+		return getIntFromState(state, valueKey) < 5
+	}))
+	g.AddEdge("poll", "done", WithEdgeCondition(func(ctx context.Context, state State) bool {
+		return getIntFromState(state, valueKey) >= 5
+	}))
+	g.SetEntryPoint("poll")
+	g.SetFinishPoint("done")
+
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	state, err := exec.Execute(context.Background(), State{})
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if val := getIntFromState(state, valueKey); val != 5 {
+		t.Fatalf("poll ran %d times, want 5", val)
+	}
+}
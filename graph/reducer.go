@@ -0,0 +1,171 @@
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Reducer combines the current value of a state key with a value written by
+// a node. current is nil when the key is not set yet.
+type Reducer func(current, update any) (any, error)
+
+// WithReducer declares how writes to key are merged into the state. Without a
+// reducer the last write wins, so parallel branches overwrite each other.
+//
+// Every value a node returns for a reduced key is a write, such as the new
+// elements for AppendReducer. Handlers may still return the state they
+// received, or a copy of it that keeps its other keys: the reduced keys they
+// leave unchanged are not written again.
+//
+// Within a run, the writes to a reduced key are reduced in a deterministic
+// order: by loop iteration, then by the topological order of the nodes, ties
+// broken by name, then by fan-out instance. Parallel and sequential runs
+// therefore produce the same state.
+func WithReducer(key string, reducer Reducer) Option {
+	return func(g *Graph) {
+		if g.reducers == nil {
+			g.reducers = make(map[string]Reducer)
+		}
+		g.reducers[key] = reducer
+	}
+}
+
+// AppendReducer appends the update to the current slice. The update is
+// either a slice of the same type, whose elements are appended, or a single
+// element. A single element is appended to a new slice of its type when the
+// key is not set yet.
+func AppendReducer(current, update any) (any, error) {
+	if update == nil {
+		return current, nil
+	}
+	u := reflect.ValueOf(update)
+	if current == nil {
+		if u.Kind() == reflect.Slice {
+			return reflect.AppendSlice(reflect.MakeSlice(u.Type(), 0, u.Len()), u).Interface(), nil
+		}
+		return reflect.Append(reflect.MakeSlice(reflect.SliceOf(u.Type()), 0, 1), u).Interface(), nil
+	}
+	c := reflect.ValueOf(current)
+	if c.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("graph: append to %T, want a slice", current)
+	}
+	// The current slice is copied, since nodes may still hold it.
+	out := reflect.AppendSlice(reflect.MakeSlice(c.Type(), 0, c.Len()+1), c)
+	switch {
+	case u.Type() == c.Type():
+		out = reflect.AppendSlice(out, u)
+	case u.Type().AssignableTo(c.Type().Elem()):
+		out = reflect.Append(out, u)
+	default:
+		return nil, fmt.Errorf("graph: cannot append %T to %T", update, current)
+	}
+	return out.Interface(), nil
+}
+
+// MergeMapReducer copies the entries of the update map into a copy of the
+// current map, overwriting existing keys. Both maps must have the same type.
+func MergeMapReducer(current, update any) (any, error) {
+	if update == nil {
+		return current, nil
+	}
+	u := reflect.ValueOf(update)
+	if u.Kind() != reflect.Map {
+		return nil, fmt.Errorf("graph: merge %T, want a map", update)
+	}
+	out := reflect.MakeMapWithSize(u.Type(), u.Len())
+	if current != nil {
+		c := reflect.ValueOf(current)
+		if c.Type() != u.Type() {
+			return nil, fmt.Errorf("graph: cannot merge %T into %T", update, current)
+		}
+		for iter := c.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+	for iter := u.MapRange(); iter.Next(); {
+		out.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return out.Interface(), nil
+}
+
+// SumReducer adds the update to the current number. Both must have the same
+// integer or floating-point type.
+func SumReducer(current, update any) (any, error) {
+	if current == nil {
+		return update, nil
+	}
+	if update == nil {
+		return current, nil
+	}
+	c, u := reflect.ValueOf(current), reflect.ValueOf(update)
+	if c.Type() != u.Type() {
+		return nil, fmt.Errorf("graph: cannot add %T to %T", update, current)
+	}
+	out := reflect.New(c.Type()).Elem()
+	switch {
+	case c.CanInt():
+		out.SetInt(c.Int() + u.Int())
+	case c.CanUint():
+		out.SetUint(c.Uint() + u.Uint())
+	case c.CanFloat():
+		out.SetFloat(c.Float() + u.Float())
+	default:
+		return nil, fmt.Errorf("graph: cannot sum %T", current)
+	}
+	return out.Interface(), nil
+}
+
+// reduction accumulates the writes to a reduced key during a run.
+type reduction struct {
+	base   any
+	writes []reducedWrite
+	// values[i] is base reduced over writes[:i+1].
+	values  []any
+	reducer Reducer
+}
+
+// reducedWrite is a value written to a reduced key by a node execution.
+type reducedWrite struct {
+	iteration int
+	rank      int
+	index     int // instance of a fan-out target
+	value     any
+}
+
+// add records the write and returns the key's value reduced over all writes
+// in their deterministic order. A write that comes after the others is
+// reduced into the current value; one that lands before later writes, such
+// as a parallel write of a lower rank, re-reduces only the writes after it.
+func (r *reduction) add(write reducedWrite) (any, error) {
+	i, _ := slices.BinarySearchFunc(r.writes, write, compareWrites)
+	for i < len(r.writes) && compareWrites(r.writes[i], write) == 0 {
+		i++
+	}
+	writes := slices.Insert(slices.Clone(r.writes[i:]), 0, write)
+	values := make([]any, len(writes))
+	value := r.base
+	if i > 0 {
+		value = r.values[i-1]
+	}
+	for j, w := range writes {
+		var err error
+		if value, err = r.reducer(value, w.value); err != nil {
+			return nil, err
+		}
+		values[j] = value
+	}
+	r.writes = append(r.writes[:i], writes...)
+	r.values = append(r.values[:i], values...)
+	return value, nil
+}
+
+func compareWrites(a, b reducedWrite) int {
+	if a.iteration != b.iteration {
+		return a.iteration - b.iteration
+	}
+	if a.rank != b.rank {
+		return a.rank - b.rank
+	}
+	return a.index - b.index
+}
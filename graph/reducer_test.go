@@ -0,0 +1,266 @@
+package graph
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReducersFanIn(t *testing.T) {
+	want := State{
+		"findings": []string{"start", "code", "docs", "web"},
+		"count":    3,
+		"sources":  map[string]string{"code": "ok", "docs": "ok", "web": "ok"},
+		"summary":  "start,code,docs,web",
+	}
+	for _, parallel := range []bool{true, false} {
+		g := New(
+			WithParallel(parallel),
+			WithReducer("findings", AppendReducer),
+			WithReducer("count", SumReducer),
+			WithReducer("sources", MergeMapReducer),
+		)
+		g.AddNode("start", func(ctx context.Context, state State) (State, error) {
+			return State{"findings": []string{"start"}}, nil
+		})
+		branches := map[string]time.Duration{"web": 3 * time.Millisecond, "docs": time.Millisecond, "code": 0}
+		for name, delay := range branches {
+			g.AddNode(name, func(ctx context.Context, state State) (State, error) {
+				time.Sleep(delay)
+				return State{
+					"findings": name,
+					"count":    1,
+					"sources":  map[string]string{name: "ok"},
+				}, nil
+			})
+			g.AddEdge("start", name)
+			g.AddEdge(name, "join")
+		}
+		g.AddNode("join", func(ctx context.Context, state State) (State, error) {
+			return State{"summary": strings.Join(state["findings"].([]string), ",")}, nil
+		})
+		g.SetEntryPoint("start")
+		g.SetFinishPoint("join")
+
+		exec, err := g.Compile()
+		if err != nil {
+			t.Fatalf("compile error: %v", err)
+		}
+		state, err := exec.Execute(context.Background(), State{})
+		if err != nil {
+			t.Fatalf("parallel=%v execute error: %v", parallel, err)
+		}
+		if !reflect.DeepEqual(state, want) {
+			t.Fatalf("parallel=%v state = %v, want %v", parallel, state, want)
+		}
+	}
+}
+
+func TestReducerLoopKeepsIterationOrder(t *testing.T) {
+	g := New(WithReducer("log", AppendReducer))
+	g.AddNode("act", func(ctx context.Context, state State) (State, error) {
+		return State{"log": "act", "round": len(state["log"].([]string))/2 + 1}, nil
+	})
+	g.AddNode("check", func(ctx context.Context, state State) (State, error) {
+		return State{"log": "check", "round": state["round"]}, nil
+	})
+	g.AddNode("done", func(ctx context.Context, state State) (State, error) { return nil, nil })
+	g.AddEdge("act", "check")
+	g.AddEdge("check", "act", WithEdgeCondition(func(ctx context.Context, state State) bool { return state["round"].(int) < 3 }))
+	g.AddEdge("check", "done", WithEdgeCondition(func(ctx context.Context, state State) bool { return state["round"].(int) >= 3 }))
+	g.SetEntryPoint("act")
+	g.SetFinishPoint("done")
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	state, err := exec.Execute(context.Background(), State{"log": []string{}})
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	want := []string{"act", "check", "act", "check", "act", "check"}
+	if !reflect.DeepEqual(state["log"], want) {
+		t.Fatalf("log = %v, want %v", state["log"], want)
+	}
+}
+
+func TestReducerNodeAfterLoop(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		g := New(WithReducer("log", AppendReducer), WithParallel(parallel))
+		g.AddNode("plan", func(ctx context.Context, state State) (State, error) {
+			return State{"log": "plan"}, nil
+		})
+		g.AddNode("act", func(ctx context.Context, state State) (State, error) {
+			count, _ := state["count"].(int)
+			return State{"log": "act", "count": count + 1}, nil
+		})
+		g.AddNode("summarize", func(ctx context.Context, state State) (State, error) {
+			return State{"log": "summarize"}, nil
+		})
+		g.AddEdge("plan", "act")
+		g.AddEdge("act", "act", WithEdgeCondition(func(ctx context.Context, state State) bool { return state["count"].(int) < 2 }))
+		g.AddEdge("act", "summarize", WithEdgeCondition(func(ctx context.Context, state State) bool { return state["count"].(int) >= 2 }))
+		g.SetEntryPoint("plan")
+		g.SetFinishPoint("summarize")
+		exec, err := g.Compile()
+		if err != nil {
+			t.Fatalf("compile error: %v", err)
+		}
+		state, err := exec.Execute(context.Background(), State{})
+		if err != nil {
+			t.Fatalf("parallel=%v execute error: %v", parallel, err)
+		}
+		want := []string{"plan", "act", "act", "summarize"}
+		if !reflect.DeepEqual(state["log"], want) {
+			t.Fatalf("parallel=%v log = %v, want %v", parallel, state["log"], want)
+		}
+	}
+}
+
+func TestReducerNodeReturnsInputState(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		g := New(WithReducer("log", AppendReducer), WithParallel(parallel))
+		g.AddNode("a", func(ctx context.Context, state State) (State, error) {
+			return State{"log": "a"}, nil
+		})
+		g.AddNode("b", func(ctx context.Context, state State) (State, error) {
+			return state, nil
+		})
+		g.AddNode("c", func(ctx context.Context, state State) (State, error) {
+			state["log"] = "c"
+			return maps.Clone(state), nil
+		})
+		g.AddNode("d", func(ctx context.Context, state State) (State, error) {
+			return state, nil
+		})
+		g.AddEdge("a", "b")
+		g.AddEdge("b", "c")
+		g.AddEdge("c", "d")
+		g.SetEntryPoint("a")
+		g.SetFinishPoint("d")
+
+		exec, err := g.Compile()
+		if err != nil {
+			t.Fatalf("compile error: %v", err)
+		}
+		state, err := exec.Execute(context.Background(), State{"topic": "agents"})
+		if err != nil {
+			t.Fatalf("parallel=%v execute error: %v", parallel, err)
+		}
+		if want := []string{"a", "c"}; !reflect.DeepEqual(state["log"], want) {
+			t.Fatalf("parallel=%v log = %v, want %v", parallel, state["log"], want)
+		}
+	}
+}
+
+func TestReducerRunsOncePerWrite(t *testing.T) {
+	var calls int32
+	g := New(WithReducer("count", func(current, update any) (any, error) {
+		atomic.AddInt32(&calls, 1)
+		return SumReducer(current, update)
+	}))
+	g.AddNode("act", func(ctx context.Context, state State) (State, error) {
+		return State{"count": 1, valueKey: getIntFromState(state, valueKey) + 1}, nil
+	})
+	g.AddNode("done", stepHandler("done"))
+	g.AddEdge("act", "act", WithEdgeCondition(func(ctx context.Context, state State) bool {
+		return getIntFromState(state, valueKey) < 10
+	}))
+	g.AddEdge("act", "done", WithEdgeCondition(func(ctx context.Context, state State) bool {
+		return getIntFromState(state, valueKey) >= 10
+	}))
+	g.SetEntryPoint("act")
+	g.SetFinishPoint("done")
+
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	state, err := exec.Execute(context.Background(), State{})
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if state["count"] != 10 || calls != 10 {
+		t.Fatalf("count = %v after %d reducer calls, want 10 after 10", state["count"], calls)
+	}
+}
+
+func TestCustomReducerError(t *testing.T) {
+	g := New(WithReducer("max", func(current, update any) (any, error) {
+		if current == nil || update.(int) > current.(int) {
+			return update, nil
+		}
+		return current, nil
+	}), WithReducer("count", SumReducer))
+	g.AddNode("a", func(ctx context.Context, state State) (State, error) { return State{"max": 3}, nil })
+	g.AddNode("b", func(ctx context.Context, state State) (State, error) { return State{"max": 1, "count": "x"}, nil })
+	g.AddEdge("a", "b")
+	g.SetEntryPoint("a")
+	g.SetFinishPoint("b")
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	_, err = exec.Execute(context.Background(), State{"count": 1})
+	if err == nil || !strings.Contains(err.Error(), "reduce count written by node b") {
+		t.Fatalf("execute error = %v, want reduce error", err)
+	}
+
+	exec, err = g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	state, err := exec.Execute(context.Background(), State{})
+	if err != nil {
+		t.Fatalf("execute error: %v", err)
+	}
+	if state["max"] != 3 || state["count"] != "x" {
+		t.Fatalf("state = %v", state)
+	}
+}
+
+func TestBuiltinReducers(t *testing.T) {
+	tests := []struct {
+		name    string
+		reducer Reducer
+		current any
+		update  any
+		want    any
+		wantErr bool
+	}{
+		{"append element to nil", AppendReducer, nil, 1, []int{1}, false},
+		{"append slice to nil", AppendReducer, nil, []string{"a"}, []string{"a"}, false},
+		{"append slice", AppendReducer, []string{"a"}, []string{"b", "c"}, []string{"a", "b", "c"}, false},
+		{"append any element", AppendReducer, []any{"a"}, 2, []any{"a", 2}, false},
+		{"append mismatched", AppendReducer, []string{"a"}, 1, nil, true},
+		{"append to non-slice", AppendReducer, "a", "b", nil, true},
+		{"merge into nil", MergeMapReducer, nil, map[string]int{"a": 1}, map[string]int{"a": 1}, false},
+		{"merge overwrites", MergeMapReducer, map[string]int{"a": 1, "b": 2}, map[string]int{"b": 3}, map[string]int{"a": 1, "b": 3}, false},
+		{"merge mismatched", MergeMapReducer, map[string]int{}, map[string]any{}, nil, true},
+		{"sum ints", SumReducer, 2, 3, 5, false},
+		{"sum floats", SumReducer, 1.5, 0.25, 1.75, false},
+		{"sum into nil", SumReducer, nil, uint8(4), uint8(4), false},
+		{"sum mismatched", SumReducer, 1, 1.0, nil, true},
+		{"sum strings", SumReducer, "a", "b", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.reducer(tt.current, tt.update)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	current := []string{"a"}
+	if _, err := AppendReducer(current, "b"); err != nil || len(current) != 1 {
+		t.Fatalf("append modified the current slice: %v, %v", current, err)
+	}
+}
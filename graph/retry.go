@@ -18,6 +18,7 @@ import (
 //   - The same `state` value is passed to the handler on each attempt. Handlers must not mutate `state`.
 //   - If all attempts are exhausted and the handler continues to return an error, the last error is returned and no further retries are performed.
 //   - Retry behavior (e.g., backoff, which errors are retryable) can be customized via retry.Option.
+//   - Interrupts raised with Interrupt are returned at once and never retried.
 //
 // Example usage:
 //
@@ -33,15 +34,23 @@ func Retry(attempts int, opts ...retry.Option) Middleware {
 	return func(next Handler) Handler {
 		return func(ctx context.Context, input State) (State, error) {
 			var (
-				err    error
-				output State
+				err       error
+				output    State
+				interrupt error
 			)
 			if err = r.Do(ctx, func(ctx context.Context) error {
 				output, err = next(ctx, input)
+				if _, ok := IsInterrupt(err); ok {
+					interrupt = err
+					return nil
+				}
 				return err
 			}); err != nil {
 				return nil, err
 			}
+			if interrupt != nil {
+				return nil, interrupt
+			}
 			return output, nil
 		}
 	}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/blades"
+)
+
+// EventType identifies the kind of a graph Event.
+type EventType string
+
+const (
+	// EventNodeStarted is emitted when a node starts running.
+	EventNodeStarted EventType = "node_started"
+	// EventNodeOutput is emitted for every intermediate value a node emits with Emit.
+	EventNodeOutput EventType = "node_output"
+	// EventStateUpdated is emitted with the state update returned by a node.
+	EventStateUpdated EventType = "state_updated"
+	// EventNodeFinished is emitted when a node completes.
+	EventNodeFinished EventType = "node_finished"
+	// EventNodeError is emitted when a node fails.
+	EventNodeError EventType = "node_error"
+	// EventNodeInterrupted is emitted when a node raises Interrupt, with its payload in Value.
+	EventNodeInterrupted EventType = "node_interrupted"
+	// EventCheckpointSaved is emitted after a checkpoint is saved.
+	EventCheckpointSaved EventType = "checkpoint_saved"
+	// EventGraphFinished is the last event of a successful run, with the final State.
+	EventGraphFinished EventType = "graph_finished"
+)
+
+// Event is a progress event of a streamed graph run.
+type Event struct {
+	Type EventType
+	// Node is the name of the node the event is about, if any.
+	Node string
+	Time time.Time
+	// Duration is the elapsed time of finished and failed nodes.
+	Duration time.Duration
+	// State is the update of EventStateUpdated and the final state of EventGraphFinished.
+	State State
+	// Value is the emitted value of EventNodeOutput and the payload of EventNodeInterrupted.
+	Value any
+	// Checkpoint is the saved checkpoint of EventCheckpointSaved.
+	Checkpoint *Checkpoint
+	Err        error
+}
+
+type ctxEmitKey struct{}
+
+// Emit sends an intermediate value of the running node to the consumer of a
+// streamed run as an EventNodeOutput. It blocks until the event is consumed
+// and does nothing when the run is not streamed.
+func Emit(ctx context.Context, value any) {
+	if emit, ok := ctx.Value(ctxEmitKey{}).(func(any)); ok {
+		emit(value)
+	}
+}
+
+// Stream runs the graph like Execute, yielding the events of the run as they
+// happen and ending with EventGraphFinished. A failed or interrupted run ends
+// with its error instead. Stopping the iteration cancels the run.
+func (e *Executor) Stream(ctx context.Context, state State, opts ...ExecuteOption) blades.Generator[*Event, error] {
+	return func(yield func(*Event, error) bool) {
+		o := newExecuteOptions(opts)
+		t := newTask(e, state, e.checkpointer, o.CheckpointID)
+		stream(ctx, t, nil, yield)
+	}
+}
+
+// ResumeStream resumes a run like Resume, yielding its events like Stream.
+func (e *Executor) ResumeStream(ctx context.Context, state State, opts ...ExecuteOption) blades.Generator[*Event, error] {
+	return func(yield func(*Event, error) bool) {
+		t, checkpoint, err := e.resumeTask(ctx, state, newExecuteOptions(opts))
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		stream(ctx, t, checkpoint, yield)
+	}
+}
+
+// stream runs the task in the background and yields its events.
+func stream(ctx context.Context, t *Task, checkpoint *Checkpoint, yield func(*Event, error) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan *Event)
+	t.emit = func(event *Event) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+	var (
+		state State
+		err   error
+		done  = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		state, err = t.run(ctx, checkpoint)
+	}()
+	for {
+		select {
+		case event := <-events:
+			if !yield(event, nil) {
+				cancel()
+				<-done
+				return
+			}
+		case <-done:
+			// Every event is emitted before run returns, so none is lost.
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			yield(&Event{Type: EventGraphFinished, Time: time.Now(), State: state}, nil)
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStreamEvents(t *testing.T) {
+	g := New()
+	g.AddNode("fetch", func(ctx context.Context, state State) (State, error) {
+		state["doc"] = "text"
+		return state, nil
+	})
+	g.AddNode("summarize", func(ctx context.Context, state State) (State, error) {
+		Emit(ctx, "sum")
+		Emit(ctx, "mary")
+		state["summary"] = "summary of " + state["doc"].(string)
+		return state, nil
+	})
+	g.AddEdge("fetch", "summarize")
+	g.SetEntryPoint("fetch")
+	g.SetFinishPoint("summarize")
+
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	var (
+		got     []string
+		outputs []any
+		final   State
+	)
+	for event, err := range exec.Stream(context.Background(), State{}) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		if event.Time.IsZero() {
+			t.Fatalf("event %s has no time", event.Type)
+		}
+		got = append(got, string(event.Type)+":"+event.Node)
+		switch event.Type {
+		case EventNodeOutput:
+			outputs = append(outputs, event.Value)
+		case EventGraphFinished:
+			final = event.State
+		}
+	}
+	want := []string{
+		"node_started:fetch", "state_updated:fetch", "node_finished:fetch",
+		"node_started:summarize", "node_output:summarize", "node_output:summarize",
+		"state_updated:summarize", "node_finished:summarize", "graph_finished:",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(outputs, []any{"sum", "mary"}) {
+		t.Fatalf("outputs = %v", outputs)
+	}
+	if final["summary"] != "summary of text" {
+		t.Fatalf("final state = %v", final)
+	}
+}
+
+func TestStreamNodeError(t *testing.T) {
+	boom := errors.New("boom")
+	g := New()
+	g.AddNode("fail", func(ctx context.Context, state State) (State, error) { return nil, boom })
+	g.SetEntryPoint("fail")
+	g.SetFinishPoint("fail")
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	var nodeErr, runErr error
+	for event, err := range exec.Stream(context.Background(), State{}) {
+		if err != nil {
+			runErr = err
+			continue
+		}
+		if event.Type == EventNodeError {
+			nodeErr = event.Err
+		}
+	}
+	if nodeErr != boom || !errors.Is(runErr, boom) {
+		t.Fatalf("node error = %v, run error = %v", nodeErr, runErr)
+	}
+}
+
+func TestStreamStopCancelsRun(t *testing.T) {
+	cancelled := make(chan struct{})
+	g := New()
+	g.AddNode("wait", func(ctx context.Context, state State) (State, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+	g.SetEntryPoint("wait")
+	g.SetFinishPoint("wait")
+	exec, err := g.Compile()
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+	for event, err := range exec.Stream(context.Background(), State{}) {
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+		if event.Type == EventNodeStarted {
+			break
+		}
+	}
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("the running node was not cancelled")
+	}
+}
@@ -2,9 +2,12 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
+	"reflect"
 	"sync"
+	"time"
 
 	syncmap "github.com/go-kratos/kit/container/maps"
 )
@@ -28,10 +31,28 @@ type Task struct {
 	remaining map[string]int
 	// Number of contributions observed per node
 	received map[string]int
-	// In-flight: nodes currently executing
-	inFlight map[string]bool
+	// In-flight: nodes currently executing, with their number of running instances
+	inFlight map[string]int
 	// Visited: nodes that have completed
 	visited map[string]bool
+	// Loops whose edges were taken, restarted once no node is running or ready
+	pendingLoops []*loop
+	// Number of node executions, in total and per node
+	steps  int
+	visits map[string]int
+	// Number of loop iterations started during this run
+	iteration int
+	// Interrupts raised by nodes, in order; the run stops once they are set
+	interrupts []*InterruptError
+	// Resume values of interrupted nodes, delivered on their next execution
+	resumeValues map[string]any
+	// Writes to reduced keys during this run, by key
+	reductions map[string]*reduction
+	// Fan-outs started but not completed, by target node
+	fanOuts map[string]*fanOut
+
+	// Sink of the events of a streamed run; nil otherwise
+	emit func(*Event)
 
 	checkpointer            Checkpointer
 	checkpointID            string
@@ -48,8 +69,9 @@ func newTask(e *Executor, state State, checkpointer Checkpointer, checkpointID s
 		ready:        make([]string, 0, 4),
 		remaining:    make(map[string]int, len(e.graph.nodes)),
 		received:     make(map[string]int),
-		inFlight:     make(map[string]bool, len(e.graph.nodes)),
+		inFlight:     make(map[string]int, len(e.graph.nodes)),
 		visited:      make(map[string]bool, len(e.graph.nodes)),
+		visits:       make(map[string]int, len(e.graph.nodes)),
 		checkpointer: checkpointer,
 		checkpointID: checkpointID,
 	}
@@ -65,15 +87,22 @@ func (t *Task) run(ctx context.Context, checkpoint *Checkpoint) (State, error) {
 	}
 	// Main scheduling loop
 	for {
+		if err := ctx.Err(); err != nil {
+			t.fail(err)
+		}
 		t.emitCheckpointIfIdle(ctx)
 		// Check termination conditions
 		shouldStop, err := t.checkTermination()
 		if err != nil {
+			t.saveFanOutProgress(ctx)
 			return nil, err
 		}
 		if shouldStop {
 			return t.state.ToMap(), nil
 		}
+		if t.isInterrupted() {
+			return t.stopInterrupted(ctx)
+		}
 		// Schedule next ready node
 		if !t.scheduleNext(ctx) {
 			// No ready nodes, wait for in-flight to complete
@@ -105,7 +134,17 @@ func (t *Task) restoreCheckpoint(cp Checkpoint) {
 	if cp.Visited != nil {
 		t.visited = cp.Visited
 	}
-	t.inFlight = make(map[string]bool, len(t.executor.graph.nodes))
+	if cp.Visits != nil {
+		t.visits = cp.Visits
+	}
+	t.steps = cp.Steps
+	t.inFlight = make(map[string]int, len(t.executor.graph.nodes))
+	for node, progress := range cp.FanOuts {
+		if t.fanOuts == nil {
+			t.fanOuts = make(map[string]*fanOut, len(cp.FanOuts))
+		}
+		t.fanOuts[node] = restoreFanOut(progress)
+	}
 	for key, value := range cp.State {
 		if _, exists := t.state.Load(key); exists {
 			continue
@@ -119,8 +158,10 @@ func (t *Task) restoreCheckpoint(cp Checkpoint) {
 	t.progressSinceCheckpoint = false
 }
 
+// shouldCheckpointLocked reports whether a checkpoint is due. Checkpoints are
+// not taken while loops are pending, since they do not record them.
 func (t *Task) shouldCheckpointLocked() bool {
-	return t.checkpointer != nil && t.checkpointID != "" && t.progressSinceCheckpoint && len(t.inFlight) == 0
+	return t.checkpointer != nil && t.checkpointID != "" && t.progressSinceCheckpoint && len(t.inFlight) == 0 && len(t.pendingLoops) == 0 && len(t.interrupts) == 0
 }
 
 // rebuildRemainingLocked derives remaining counts from visited nodes and graph topology.
@@ -134,6 +175,9 @@ func (t *Task) rebuildRemainingLocked() {
 			continue
 		}
 		for _, edge := range edges {
+			if _, ok := t.executor.nodeInfos[from].loops[edge.to]; ok {
+				continue
+			}
 			satisfied[edge.to]++
 		}
 	}
@@ -180,18 +224,34 @@ func (t *Task) emitCheckpointIfIdle(ctx context.Context) {
 		t.mu.Unlock()
 		return
 	}
+	checkpoint := t.checkpointLocked()
+	t.progressSinceCheckpoint = false
+	t.mu.Unlock()
+
+	if err := t.checkpointer.Save(ctx, checkpoint); err != nil {
+		t.fail(fmt.Errorf("graph: checkpoint save failed: %w", err))
+		return
+	}
+	t.event(&Event{Type: EventCheckpointSaved, Checkpoint: checkpoint.Clone()})
+}
+
+// checkpointLocked returns a checkpoint of the progress of the task.
+func (t *Task) checkpointLocked() *Checkpoint {
 	checkpoint := &Checkpoint{
 		ID:       t.checkpointID,
 		Received: maps.Clone(t.received),
 		Visited:  maps.Clone(t.visited),
+		Visits:   maps.Clone(t.visits),
+		Steps:    t.steps,
 		State:    t.state.ToMap(),
 	}
-	t.progressSinceCheckpoint = false
-	t.mu.Unlock()
-
-	if err := t.checkpointer.Save(ctx, checkpoint); err != nil {
-		t.fail(fmt.Errorf("graph: checkpoint save failed: %w", err))
+	for node, f := range t.fanOuts {
+		if checkpoint.FanOuts == nil {
+			checkpoint.FanOuts = make(map[string]*FanOutProgress, len(t.fanOuts))
+		}
+		checkpoint.FanOuts[node] = f.progress()
 	}
+	return checkpoint
 }
 
 // checkTermination checks if execution should terminate and returns the result
@@ -224,8 +284,8 @@ func (t *Task) scheduleNext(ctx context.Context) bool {
 		return false
 	}
 
-	for len(t.ready) == 0 {
-		if t.err != nil || t.finished {
+	for len(t.ready) == 0 || (len(t.interrupts) > 0 && !t.readyResumedLocked()) {
+		if t.err != nil || t.finished || len(t.interrupts) > 0 {
 			t.mu.Unlock()
 			return false
 		}
@@ -234,6 +294,10 @@ func (t *Task) scheduleNext(ctx context.Context) bool {
 			return false
 		}
 		if len(t.inFlight) == 0 {
+			if len(t.pendingLoops) > 0 {
+				t.restartLoopsLocked()
+				continue
+			}
 			t.mu.Unlock()
 			t.fail(fmt.Errorf("graph: finish node not reachable: %s", t.executor.graph.finishPoint))
 			return false
@@ -256,9 +320,28 @@ func (t *Task) scheduleNext(ctx context.Context) bool {
 		return true
 	}
 
+	if f := t.fanOuts[node]; f != nil {
+		t.scheduleFanOutLocked(ctx, node, f)
+		return true
+	}
+
+	t.steps++
+	t.visits[node]++
+	if err := t.checkLimitsLocked(node); err != nil {
+		t.mu.Unlock()
+		t.fail(err)
+		return false
+	}
+
+	// Deliver the resume value to a node re-running after its interrupt
+	if value, ok := t.resumeValues[node]; ok {
+		delete(t.resumeValues, node)
+		ctx = context.WithValue(ctx, ctxResumeKey{}, resumeValue{value: value})
+	}
+
 	// Mark as in-flight
 	state := t.state.ToMap()
-	t.inFlight[node] = true
+	t.inFlight[node]++
 	t.wg.Add(1)
 	parallel := t.executor.graph.parallel
 	t.mu.Unlock()
@@ -268,6 +351,51 @@ func (t *Task) scheduleNext(ctx context.Context) bool {
 	return true
 }
 
+// checkLimitsLocked returns an error when the execution of node exceeds the
+// step limit of the graph or the visit limit of the node.
+func (t *Task) checkLimitsLocked(node string) error {
+	if limit := t.executor.graph.maxSteps; limit > 0 && t.steps > limit {
+		return fmt.Errorf("%w: more than %d node executions", ErrMaxStepsExceeded, limit)
+	}
+	if limit := t.executor.nodeInfos[node].maxVisits; limit > 0 && t.visits[node] > limit {
+		return fmt.Errorf("%w: node %s ran more than %d times", ErrMaxStepsExceeded, node, limit)
+	}
+	return nil
+}
+
+// restartLoopsLocked starts a new iteration of every pending loop: the target
+// and its descendants become unvisited, waiting again for their incoming
+// edges from re-run nodes, and the target is scheduled. It is called once no
+// node is running or ready, so the edges of the finished iteration cannot
+// leak into the new one.
+func (t *Task) restartLoopsLocked() {
+	for _, l := range t.pendingLoops {
+		for node, deps := range l.reset {
+			delete(t.visited, node)
+			delete(t.received, node)
+			delete(t.fanOuts, node)
+			remaining := deps
+			for _, from := range t.executor.nodeInfos[node].predecessors {
+				if _, rerun := l.reset[from]; !rerun && !t.visited[from] {
+					remaining++
+				}
+			}
+			if remaining > 0 {
+				t.remaining[node] = remaining
+			} else {
+				delete(t.remaining, node)
+			}
+		}
+		t.received[l.target]++
+		if t.remaining[l.target] == 0 {
+			t.ready = append(t.ready, l.target)
+		}
+	}
+	t.pendingLoops = nil
+	t.iteration++
+	t.progressSinceCheckpoint = true
+}
+
 // executeAsync executes a node either in a goroutine (parallel) or directly (serial)
 func (t *Task) executeAsync(ctx context.Context, node string, state State, parallel bool) {
 	run := func() {
@@ -284,21 +412,19 @@ func (t *Task) executeAsync(ctx context.Context, node string, state State, paral
 
 func (t *Task) executeNode(ctx context.Context, node string, state State) {
 	// Check early termination
+	_, resumed := ResumeValue(ctx)
 	t.mu.Lock()
-	if t.err != nil || t.finished {
+	if t.err != nil || t.finished || (len(t.interrupts) > 0 && !resumed) {
 		t.mu.Unlock()
 		return
 	}
 	t.mu.Unlock()
 
-	// Execute handler
-	handler := t.executor.graph.nodes[node]
-	if len(t.executor.graph.middlewares) > 0 {
-		handler = ChainMiddlewares(t.executor.graph.middlewares...)(handler)
+	state, updates, err := t.runHandler(ctx, node, state)
+	if interrupt, ok := IsInterrupt(err); ok {
+		t.interrupt(node, -1, interrupt)
+		return
 	}
-
-	nodeCtx := NewNodeContext(ctx, &NodeContext{Name: node})
-	state, err := handler(nodeCtx, state)
 	if err != nil {
 		t.fail(fmt.Errorf("graph: failed to execute node %s: %w", node, err))
 		return
@@ -306,8 +432,10 @@ func (t *Task) executeNode(ctx context.Context, node string, state State) {
 
 	// Mark as visited and get precomputed node info
 	t.mu.Lock()
-	for key, value := range state {
-		t.state.Store(key, value)
+	if err := t.mergeLocked(node, 0, updates); err != nil {
+		t.mu.Unlock()
+		t.fail(err)
+		return
 	}
 	t.visited[node] = true
 	t.progressSinceCheckpoint = true
@@ -327,10 +455,105 @@ func (t *Task) executeNode(ctx context.Context, node string, state State) {
 	t.processOutgoing(ctx, node, info, state)
 }
 
+// runHandler runs the handler of node with the graph middlewares and emits
+// the events of the execution. updates is the output without the reduced
+// keys the handler left unchanged.
+func (t *Task) runHandler(ctx context.Context, node string, state State) (output, updates State, err error) {
+	handler := t.executor.graph.nodes[node]
+	if len(t.executor.graph.middlewares) > 0 {
+		handler = ChainMiddlewares(t.executor.graph.middlewares...)(handler)
+	}
+
+	nodeCtx := NewNodeContext(ctx, &NodeContext{Name: node})
+	if t.emit != nil {
+		nodeCtx = context.WithValue(nodeCtx, ctxEmitKey{}, func(value any) {
+			t.event(&Event{Type: EventNodeOutput, Node: node, Value: value})
+		})
+	}
+	var input State
+	if len(t.executor.graph.reducers) > 0 {
+		// Handlers may change their input state in place.
+		input = maps.Clone(state)
+	}
+	started := time.Now()
+	t.event(&Event{Type: EventNodeStarted, Node: node, Time: started})
+	output, err = handler(nodeCtx, state)
+	if interrupt, ok := IsInterrupt(err); ok {
+		t.event(&Event{Type: EventNodeInterrupted, Node: node, Value: interrupt.Payload, Duration: time.Since(started), Err: err})
+		return nil, nil, err
+	}
+	if err != nil {
+		t.event(&Event{Type: EventNodeError, Node: node, Duration: time.Since(started), Err: err})
+		return nil, nil, err
+	}
+	if len(output) > 0 {
+		t.event(&Event{Type: EventStateUpdated, Node: node, State: maps.Clone(output)})
+	}
+	t.event(&Event{Type: EventNodeFinished, Node: node, Duration: time.Since(started)})
+	return output, t.updates(state, input, output), nil
+}
+
+// updates returns the writes of output. When the handler returned its state,
+// the reduced keys it left unchanged from input are not written again.
+func (t *Task) updates(state, input, output State) State {
+	if !t.returnsState(state, input, output) {
+		return output
+	}
+	var updates State
+	for key, value := range output {
+		if _, ok := t.executor.graph.reducers[key]; !ok {
+			continue
+		}
+		if current, ok := input[key]; !ok || !reflect.DeepEqual(current, value) {
+			continue
+		}
+		if updates == nil {
+			updates = maps.Clone(output)
+		}
+		delete(updates, key)
+	}
+	if updates == nil {
+		return output
+	}
+	return updates
+}
+
+// returnsState reports whether output is the state the handler received or a
+// copy of it: one that holds every key of input and leaves the keys without a
+// reducer unchanged. Other outputs hold only updates.
+func (t *Task) returnsState(state, input, output State) bool {
+	if reflect.ValueOf(output).UnsafePointer() == reflect.ValueOf(state).UnsafePointer() {
+		return true
+	}
+	copied := false
+	for key, value := range input {
+		v, ok := output[key]
+		if !ok {
+			return false
+		}
+		if _, ok := t.executor.graph.reducers[key]; ok {
+			continue
+		}
+		if !reflect.DeepEqual(value, v) {
+			return false
+		}
+		copied = true
+	}
+	return copied
+}
+
 func (t *Task) processOutgoing(ctx context.Context, node string, info *nodeInfo, state State) {
 	if !info.hasConditions {
-		for _, dest := range info.unconditionalDests {
-			t.satisfy(node, dest, true)
+		if !info.hasFanOuts {
+			for _, dest := range info.unconditionalDests {
+				t.satisfy(node, dest, true)
+			}
+			return
+		}
+		for _, edge := range info.outEdges {
+			if !t.activate(ctx, node, edge, state) {
+				return
+			}
 		}
 		return
 	}
@@ -343,7 +566,9 @@ func (t *Task) processOutgoing(ctx context.Context, node string, info *nodeInfo,
 		}
 		if edge.condition(ctx, state) {
 			matched = true
-			t.satisfy(node, edge.to, true)
+			if !t.activate(ctx, node, edge, state) {
+				return
+			}
 		} else {
 			t.satisfy(node, edge.to, false)
 		}
@@ -360,6 +585,16 @@ func (t *Task) processOutgoing(ctx context.Context, node string, info *nodeInfo,
 func (t *Task) satisfy(from, to string, activated bool) {
 	t.mu.Lock()
 
+	// Loop edges are not dependencies; a taken one restarts the loop later.
+	if l, ok := t.executor.nodeInfos[from].loops[to]; ok {
+		if activated {
+			t.pendingLoops = append(t.pendingLoops, l)
+			t.readyCond.Signal()
+		}
+		t.mu.Unlock()
+		return
+	}
+
 	// Early exit if already visited
 	if t.visited[to] {
 		t.mu.Unlock()
@@ -384,7 +619,7 @@ func (t *Task) satisfy(from, to string, activated bool) {
 	}
 
 	// Check if node is ready
-	if t.remaining[to] == 0 && !t.visited[to] && !t.inFlight[to] {
+	if t.remaining[to] == 0 && !t.visited[to] && t.inFlight[to] == 0 {
 		if t.received[to] == 0 {
 			// All predecessors skipped - mark as skipped and propagate skip
 			t.visited[to] = true
@@ -406,12 +641,136 @@ func (t *Task) satisfy(from, to string, activated bool) {
 
 func (t *Task) nodeDone(node string) {
 	t.mu.Lock()
-	delete(t.inFlight, node)
+	if t.inFlight[node]--; t.inFlight[node] <= 0 {
+		delete(t.inFlight, node)
+	}
 	t.readyCond.Broadcast()
 	t.mu.Unlock()
 	t.wg.Done()
 }
 
+// mergeLocked writes the output of a node execution into the state. Values
+// of reduced keys are reduced with the other writes of the run; index is the
+// fan-out instance that wrote them.
+func (t *Task) mergeLocked(node string, index int, output State) error {
+	for key, value := range output {
+		reducer, ok := t.executor.graph.reducers[key]
+		if !ok {
+			t.state.Store(key, value)
+			continue
+		}
+		r, ok := t.reductions[key]
+		if !ok {
+			base, _ := t.state.Load(key)
+			r = &reduction{base: base, reducer: reducer}
+			if t.reductions == nil {
+				t.reductions = make(map[string]*reduction)
+			}
+			t.reductions[key] = r
+		}
+		reduced, err := r.add(reducedWrite{iteration: t.iteration, rank: t.executor.nodeInfos[node].rank, index: index, value: value})
+		if err != nil {
+			return fmt.Errorf("graph: failed to reduce %s written by node %s: %w", key, node, err)
+		}
+		t.state.Store(key, reduced)
+	}
+	return nil
+}
+
+// interrupt stops scheduling new nodes. The node, or the fan-out instance
+// of the node at index, is left unfinished, so it runs again when the run is
+// resumed, and its execution does not count towards the step limits. index
+// is -1 for nodes that do not fan out.
+func (t *Task) interrupt(node string, index int, interrupt *InterruptError) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.steps--
+	if f := t.fanOuts[node]; index >= 0 && f != nil {
+		f.running[index] = false
+		f.interrupted[index] = true
+	} else {
+		t.visits[node]--
+	}
+	t.interrupts = append(t.interrupts, &InterruptError{
+		Node:         node,
+		Payload:      interrupt.Payload,
+		CheckpointID: t.checkpointID,
+	})
+	t.readyCond.Broadcast()
+}
+
+// readyResumedLocked moves the first ready node that has a resume value to
+// the front of the ready queue and reports whether there is one. Such nodes
+// still run after another node interrupted, so their answers are not lost.
+func (t *Task) readyResumedLocked() bool {
+	for i, node := range t.ready {
+		if _, ok := t.resumeValues[node]; ok {
+			t.ready[0], t.ready[i] = t.ready[i], t.ready[0]
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Task) isInterrupted() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.interrupts) > 0
+}
+
+// stopInterrupted waits for the running nodes, saves a checkpoint recording
+// the interrupts and returns them, joined when there are several.
+func (t *Task) stopInterrupted(ctx context.Context) (State, error) {
+	t.wg.Wait()
+	t.mu.Lock()
+	if t.err != nil {
+		err := t.err
+		t.mu.Unlock()
+		return nil, err
+	}
+	// Loops taken by nodes that finished alongside the interrupt are started
+	// now, since checkpoints do not record pending loops.
+	if len(t.pendingLoops) > 0 {
+		t.restartLoopsLocked()
+	}
+	interrupts := make(map[string]any, len(t.interrupts))
+	for _, interrupt := range t.interrupts {
+		interrupts[interrupt.Node] = interrupt.Payload
+	}
+	checkpoint := t.checkpointLocked()
+	checkpoint.Interrupts = interrupts
+	var interrupt error = t.interrupts[0]
+	if len(t.interrupts) > 1 {
+		errs := make([]error, len(t.interrupts))
+		for i, interrupt := range t.interrupts {
+			errs[i] = interrupt
+		}
+		interrupt = errors.Join(errs...)
+	}
+	t.mu.Unlock()
+
+	if t.checkpointer == nil || t.checkpointID == "" {
+		return nil, fmt.Errorf("graph: cannot resume without a checkpointer and checkpoint ID: %w", interrupt)
+	}
+	if err := t.checkpointer.Save(ctx, checkpoint); err != nil {
+		return nil, fmt.Errorf("graph: checkpoint save failed: %w", err)
+	}
+	t.event(&Event{Type: EventCheckpointSaved, Checkpoint: checkpoint.Clone()})
+	return maps.Clone(checkpoint.State), interrupt
+}
+
+// event sends event to the consumer of a streamed run. It must not be called
+// with t.mu held, since the consumer may be slow.
+func (t *Task) event(event *Event) {
+	if t.emit == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	t.emit(event)
+}
+
 func (t *Task) fail(err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
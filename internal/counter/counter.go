@@ -21,6 +21,8 @@ func (c *charBasedCounter) Count(messages ...*blades.Message) int64 {
 			switch v := p.(type) {
 			case blades.TextPart:
 				total += int64(len(v.Text)+3) / 4
+			case blades.ReasoningPart:
+				total += int64(len(v.Text)+3) / 4
 			case blades.ToolPart:
 				total += int64(len(v.Name)+len(v.Request)+len(v.Response)+3) / 4
 			}
@@ -15,26 +15,34 @@ var (
 	ActionHandoffToAgent = "handoff_to_agent"
 )
 
-type handoffTool struct{}
+type handoffTool struct {
+	targets []string
+}
 
-func NewHandoffTool() tools.Tool {
-	return &handoffTool{}
+// NewHandoffTool returns the tool that hands off to one of the named agents.
+// The names constrain the agentName argument; none leaves it unconstrained.
+func NewHandoffTool(targets ...string) tools.Tool {
+	return &handoffTool{targets: targets}
 }
 
-func (h *handoffTool) Name() string { return "handoff_to_agent" }
+func (h *handoffTool) Name() string { return ActionHandoffToAgent }
 func (h *handoffTool) Description() string {
 	return `Transfer the question to another agent.
 Use this tool to hand off control to a more suitable agent based on the agents' descriptions.`
 }
 func (h *handoffTool) InputSchema() *jsonschema.Schema {
+	agentName := &jsonschema.Schema{
+		Type:        "string",
+		Description: "The name of the target agent to hand off the request to.",
+	}
+	for _, target := range h.targets {
+		agentName.Enum = append(agentName.Enum, target)
+	}
 	return &jsonschema.Schema{
 		Type:     "object",
 		Required: []string{"agentName"},
 		Properties: map[string]*jsonschema.Schema{
-			"agentName": {
-				Type:        "string",
-				Description: "The name of the target agent to hand off the request to.",
-			},
+			"agentName": agentName,
 		},
 	}
 }
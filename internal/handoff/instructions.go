@@ -13,12 +13,11 @@ Agent Name: {{.Name}}
 Agent Description: {{.Description}}
 {{end}}
 Your task:
-- Determine whether you are the most appropriate agent to answer the user's question based on your own description.
-- If another agent is clearly better suited to handle the user's request, you must transfer the query by calling the "handoff_to_agent" function.
-- If no other agent is more suitable, respond to the user directly as a helpful assistant, providing clear, detailed, and accurate information.
+- Determine which of the agents above is the most appropriate to answer the user's question based on their descriptions.
+- Transfer the query to that agent by calling the "handoff_to_agent" function.
 
 Important rules:
-- When transferring a query, output only the function call, and nothing else.
+- Output only the function call, and nothing else.
 - Do not include explanations, reasoning, or any additional text outside of the function call.`
 
 var handoffToAgentPromptTmpl = template.Must(template.New("handoff_to_agent_prompt").Parse(handoffInstructionTemplate))
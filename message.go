@@ -1,8 +1,10 @@
 package blades
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/google/uuid"
 )
@@ -59,6 +61,57 @@ type ToolPart struct {
 	Request   string `json:"arguments"`
 	Response  string `json:"result,omitempty"`
 	Completed bool   `json:"completed,omitempty"`
+	// Content holds multimodal results returned alongside Response, e.g. a
+	// DataPart with a rendered chart or a FilePart with a screenshot URL.
+	Content []Part `json:"content,omitempty"`
+}
+
+// MarshalJSON encodes the tool part with a "type" tag on every content part.
+func (p ToolPart) MarshalJSON() ([]byte, error) {
+	type alias ToolPart
+	content := make([]json.RawMessage, 0, len(p.Content))
+	for _, part := range p.Content {
+		data, err := marshalPart(part)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, data)
+	}
+	return json.Marshal(struct {
+		alias
+		Content []json.RawMessage `json:"content,omitempty"`
+	}{alias: alias(p), Content: content})
+}
+
+// UnmarshalJSON decodes a tool part produced by MarshalJSON.
+func (p *ToolPart) UnmarshalJSON(data []byte) error {
+	type alias ToolPart
+	aux := struct {
+		*alias
+		Content []json.RawMessage `json:"content,omitempty"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Content = nil
+	for _, raw := range aux.Content {
+		part, err := unmarshalPart(raw)
+		if err != nil {
+			return err
+		}
+		p.Content = append(p.Content, part)
+	}
+	return nil
+}
+
+// ReasoningPart is the model's reasoning ("thinking") output. Signature holds
+// the opaque provider token that must be sent back unchanged on later turns,
+// e.g. Claude's thinking signature or Gemini's thought signature. Redacted
+// marks encrypted reasoning whose payload is carried in Signature.
+type ReasoningPart struct {
+	Text      string `json:"text,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Redacted  bool   `json:"redacted,omitempty"`
 }
 
 // NewToolPart creates a tool call part that has not completed yet.
@@ -70,15 +123,27 @@ func NewToolPart(id, name, request string) ToolPart {
 	}
 }
 
-// Part is a part of a message, which can be text or a file.
+// Part is a part of a message, which can be text, a file, data or a tool call.
+// PartType returns the tag used for the "type" field in the JSON encoding of
+// a Message; custom parts must be registered with RegisterPart to be decoded.
 type Part interface {
-	isPart()
+	PartType() string
 }
 
-func (TextPart) isPart() {}
-func (FilePart) isPart() {}
-func (DataPart) isPart() {}
-func (ToolPart) isPart() {}
+// PartType returns "text".
+func (TextPart) PartType() string { return "text" }
+
+// PartType returns "file".
+func (FilePart) PartType() string { return "file" }
+
+// PartType returns "data".
+func (DataPart) PartType() string { return "data" }
+
+// PartType returns "tool".
+func (ToolPart) PartType() string { return "tool" }
+
+// PartType returns "reasoning".
+func (ReasoningPart) PartType() string { return "reasoning" }
 
 // TokenUsage tracks token consumption for a message.
 type TokenUsage struct {
@@ -99,6 +164,123 @@ type Message struct {
 	TokenUsage   TokenUsage     `json:"tokenUsage,omitempty"`
 	Actions      map[string]any `json:"actions,omitempty"`
 	Metadata     map[string]any `json:"metadata,omitempty"`
+	// Model is the name of the model that generated the message. Providers
+	// wrapping other providers set it to the model that served the request.
+	Model string `json:"model,omitempty"`
+	// StateDelta holds the session state changes made while producing the
+	// message; a deleted key maps to nil.
+	StateDelta State `json:"stateDelta,omitempty"`
+}
+
+// MarshalJSON encodes the message with a "type" tag on every part so that
+// the polymorphic Parts slice can be decoded back by UnmarshalJSON.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type alias Message
+	parts := make([]json.RawMessage, 0, len(m.Parts))
+	for _, part := range m.Parts {
+		data, err := marshalPart(part)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, data)
+	}
+	return json.Marshal(struct {
+		alias
+		Parts []json.RawMessage `json:"parts"`
+	}{alias: alias(m), Parts: parts})
+}
+
+// UnmarshalJSON decodes a message produced by MarshalJSON.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type alias Message
+	aux := struct {
+		*alias
+		Parts []json.RawMessage `json:"parts"`
+	}{alias: (*alias)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	m.Parts = nil
+	for _, raw := range aux.Parts {
+		part, err := unmarshalPart(raw)
+		if err != nil {
+			return err
+		}
+		m.Parts = append(m.Parts, part)
+	}
+	return nil
+}
+
+// partDecoders maps a part type tag to the function that decodes it.
+var (
+	partMu       sync.RWMutex
+	partDecoders = make(map[string]func([]byte) (Part, error))
+)
+
+func init() {
+	RegisterPart[TextPart]()
+	RegisterPart[FilePart]()
+	RegisterPart[DataPart]()
+	RegisterPart[ToolPart]()
+	RegisterPart[ReasoningPart]()
+}
+
+// RegisterPart registers the Part type T under the tag returned by its
+// PartType method so that messages carrying it can be decoded from JSON.
+// T must encode to a JSON object without a "type" field. Registering a tag
+// twice panics; call RegisterPart from an init function.
+func RegisterPart[T Part]() {
+	var zero T
+	kind := zero.PartType()
+	partMu.Lock()
+	defer partMu.Unlock()
+	if _, ok := partDecoders[kind]; ok {
+		panic(fmt.Sprintf("blades: part type %q already registered", kind))
+	}
+	partDecoders[kind] = func(data []byte) (Part, error) {
+		var part T
+		if err := json.Unmarshal(data, &part); err != nil {
+			return nil, err
+		}
+		return part, nil
+	}
+}
+
+// marshalPart encodes a part as a JSON object carrying its type tag.
+func marshalPart(part Part) ([]byte, error) {
+	data, err := json.Marshal(part)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("blades: part %T must encode to a JSON object: %w", part, err)
+	}
+	if _, ok := fields["type"]; ok {
+		return nil, fmt.Errorf("blades: part %T must not have a \"type\" field", part)
+	}
+	fields["type"], err = json.Marshal(part.PartType())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// unmarshalPart decodes a part previously encoded by marshalPart.
+func unmarshalPart(data []byte) (Part, error) {
+	var tag struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &tag); err != nil {
+		return nil, err
+	}
+	partMu.RLock()
+	decode, ok := partDecoders[tag.Type]
+	partMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("blades: unknown part type %q", tag.Type)
+	}
+	return decode(data)
 }
 
 // Text returns the first text part of the message, or an empty string if none exists.
@@ -114,6 +296,19 @@ func (m *Message) Text() string {
 	return strings.TrimSuffix(buf.String(), "\n")
 }
 
+// Reasoning returns the concatenated reasoning text of the message, or an
+// empty string if it has no reasoning parts.
+func (m *Message) Reasoning() string {
+	var buf strings.Builder
+	for _, part := range m.Parts {
+		if v, ok := part.(ReasoningPart); ok && v.Text != "" {
+			buf.WriteString(v.Text)
+			buf.WriteByte('\n')
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
 // File returns the first file part of the message, or nil if none exists.
 func (m *Message) File() *FilePart {
 	for _, part := range m.Parts {
@@ -152,6 +347,8 @@ func (m *Message) String() string {
 			buf.WriteString("[File: " + v.Name + " (" + string(v.MIMEType) + ")]")
 		case DataPart:
 			buf.WriteString("[Data: " + v.Name + " (" + string(v.MIMEType) + "), " + fmt.Sprintf("%d bytes", len(v.Bytes)) + "]")
+		case ReasoningPart:
+			buf.WriteString("[Reasoning: " + v.Text + "]")
 		case ToolPart:
 			buf.WriteString("[Tool: " + v.Name + " (Request: " + v.Request + ", Response: " + v.Response + ")]")
 		}
@@ -191,20 +388,14 @@ func NewMessageID() string {
 }
 
 // NewMessageParts converts a heterogeneous list of content inputs into model parts.
-// Accepts raw string, Text, FileURI, and FileBytes.
+// Accepts raw strings and any Part value; other inputs are ignored.
 func NewMessageParts(inputs ...any) []Part {
 	parts := make([]Part, 0, len(inputs))
 	for _, input := range inputs {
 		switch v := any(input).(type) {
 		case string:
 			parts = append(parts, TextPart{v})
-		case TextPart:
-			parts = append(parts, v)
-		case FilePart:
-			parts = append(parts, v)
-		case DataPart:
-			parts = append(parts, v)
-		case ToolPart:
+		case Part:
 			parts = append(parts, v)
 		}
 	}
@@ -1,6 +1,9 @@
 package blades
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 func TestMergeParts(t *testing.T) {
 	t.Parallel()
@@ -25,3 +28,160 @@ func TestMergeParts(t *testing.T) {
 		}
 	})
 }
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	msg := UserMessage(
+		"hello",
+		FilePart{Name: "a.png", URI: "file:///a.png", MIMEType: MIMEImagePNG},
+		DataPart{Name: "b.wav", Bytes: []byte{1, 2, 3}, MIMEType: MIMEAudioWAV},
+		ToolPart{ID: "call_1", Name: "lookup", Request: `{"q":1}`, Response: "ok", Completed: true},
+	)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got Message
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.ID != msg.ID || got.Role != RoleUser {
+		t.Fatalf("got id=%q role=%q, want id=%q role=%q", got.ID, got.Role, msg.ID, RoleUser)
+	}
+	if len(got.Parts) != len(msg.Parts) {
+		t.Fatalf("parts len = %d, want %d", len(got.Parts), len(msg.Parts))
+	}
+	if got.String() != msg.String() {
+		t.Fatalf("round trip = %s, want %s", got.String(), msg.String())
+	}
+}
+
+func TestToolPartContentJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	part := ToolPart{ID: "call_1", Name: "render", Response: "ok", Completed: true, Content: []Part{
+		DataPart{Name: "chart.png", Bytes: []byte{1, 2}, MIMEType: MIMEImagePNG},
+		FilePart{URI: "https://example.com/a.png", MIMEType: MIMEImagePNG},
+	}}
+	data, err := json.Marshal(AssistantMessage(part))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got Message
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	tool, ok := got.Parts[0].(ToolPart)
+	if !ok || len(tool.Content) != 2 {
+		t.Fatalf("tool part = %#v", got.Parts[0])
+	}
+	if data, ok := tool.Content[0].(DataPart); !ok || data.Name != "chart.png" || len(data.Bytes) != 2 {
+		t.Fatalf("content[0] = %#v", tool.Content[0])
+	}
+	if file, ok := tool.Content[1].(FilePart); !ok || file.URI != "https://example.com/a.png" {
+		t.Fatalf("content[1] = %#v", tool.Content[1])
+	}
+}
+
+// citationPart is a custom part used to exercise RegisterPart.
+type citationPart struct {
+	Source string `json:"source"`
+}
+
+func (citationPart) PartType() string { return "test_citation" }
+
+func init() {
+	RegisterPart[citationPart]()
+}
+
+func TestMessageJSON_CustomPart(t *testing.T) {
+	t.Parallel()
+
+	msg := AssistantMessage("see source", citationPart{Source: "https://example.com"})
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got Message
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got.Parts) != 2 {
+		t.Fatalf("parts len = %d, want 2", len(got.Parts))
+	}
+	citation, ok := got.Parts[1].(citationPart)
+	if !ok {
+		t.Fatalf("part type = %T, want citationPart", got.Parts[1])
+	}
+	if citation.Source != "https://example.com" {
+		t.Fatalf("citation source = %q", citation.Source)
+	}
+}
+
+func TestMessageJSON_PartTypeTag(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(UserMessage("hi"))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var raw struct {
+		Parts []map[string]any `json:"parts"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := raw.Parts[0]["type"]; got != "text" {
+		t.Fatalf("part type tag = %v, want text", got)
+	}
+}
+
+func TestMessageJSON_UnknownPartType(t *testing.T) {
+	t.Parallel()
+
+	var msg Message
+	err := json.Unmarshal([]byte(`{"id":"1","role":"user","parts":[{"type":"unknown"}]}`), &msg)
+	if err == nil {
+		t.Fatal("expected error for unknown part type")
+	}
+}
+
+func TestRegisterPart_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic when registering a duplicate part type")
+		}
+	}()
+	RegisterPart[TextPart]()
+}
+
+func TestMessageReasoning(t *testing.T) {
+	msg := NewAssistantMessage(StatusCompleted)
+	msg.Parts = []Part{
+		ReasoningPart{Text: "step one."},
+		ReasoningPart{Signature: "sig", Redacted: true},
+		ReasoningPart{Text: "step two."},
+		TextPart{Text: "answer"},
+	}
+	if got, want := msg.Reasoning(), "step one.\nstep two."; got != want {
+		t.Fatalf("Reasoning() = %q, want %q", got, want)
+	}
+	if got, want := msg.Text(), "answer"; got != want {
+		t.Fatalf("Text() = %q, want %q", got, want)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got, ok := decoded.Parts[1].(ReasoningPart); !ok || !got.Redacted || got.Signature != "sig" {
+		t.Fatalf("decoded part = %#v", decoded.Parts[1])
+	}
+}
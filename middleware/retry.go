@@ -36,10 +36,18 @@ func Retry(attempts int, opts ...retry.Option) blades.Middleware {
 	return func(next blades.Handler) blades.Handler {
 		return blades.HandleFunc(func(ctx context.Context, invocation *blades.Invocation) blades.Generator[*blades.Message, error] {
 			return func(yield func(*blades.Message, error) bool) {
+				var (
+					attempt int
+					lastErr error
+				)
 				err := r.Do(ctx, func(ctx context.Context) error {
+					if attempt++; attempt > 1 {
+						blades.EmitEvent(ctx, &blades.Event{Type: blades.EventRetry, Attempt: attempt, Err: lastErr})
+					}
 					// Execute the handler and yield messages
 					for msg, err := range next.Handle(ctx, invocation) {
 						if err != nil {
+							lastErr = err
 							return err
 						}
 						// Yield successful messages immediately
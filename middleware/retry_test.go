@@ -320,3 +320,47 @@ func TestRetry_ReceiverStopsProcessing(t *testing.T) {
 		t.Errorf("expected no error, got %v", lastErr)
 	}
 }
+
+type flakyModel struct{ calls int }
+
+func (m *flakyModel) Name() string { return "flaky" }
+
+func (m *flakyModel) Generate(context.Context, *blades.ModelRequest) (*blades.ModelResponse, error) {
+	m.calls++
+	if m.calls == 1 {
+		return nil, errors.New("temporary failure")
+	}
+	return &blades.ModelResponse{Message: blades.AssistantMessage("ok")}, nil
+}
+
+func (m *flakyModel) NewStreaming(context.Context, *blades.ModelRequest) blades.Generator[*blades.ModelResponse, error] {
+	return nil
+}
+
+func TestRetry_EmitsRetryEvent(t *testing.T) {
+	agent, err := blades.NewAgent("retry-agent",
+		blades.WithModel(&flakyModel{}),
+		blades.WithMiddleware(Retry(2)),
+	)
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	var retries []*blades.Event
+	runner := blades.NewRunner(agent, blades.WithEventHandler(func(_ context.Context, event *blades.Event) {
+		if event.Type == blades.EventRetry {
+			retries = append(retries, event)
+		}
+	}))
+	if _, err := runner.Run(context.Background(), blades.UserMessage("hi")); err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+	if len(retries) != 1 {
+		t.Fatalf("expected 1 retry event, got %d", len(retries))
+	}
+	if retries[0].Attempt != 2 || retries[0].Err == nil {
+		t.Errorf("unexpected retry event: %+v", retries[0])
+	}
+	if retries[0].Agent != "retry-agent" {
+		t.Errorf("expected retry event from retry-agent, got %q", retries[0].Agent)
+	}
+}
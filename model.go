@@ -14,6 +14,8 @@ type ModelRequest struct {
 	Instruction  *Message           `json:"instruction,omitempty"`
 	InputSchema  *jsonschema.Schema `json:"inputSchema,omitempty"`
 	OutputSchema *jsonschema.Schema `json:"outputSchema,omitempty"`
+	// GenerationConfig overrides the provider's generation parameters.
+	GenerationConfig *GenerationConfig `json:"generationConfig,omitempty"`
 }
 
 // ModelResponse is a single assistant message as a result of generation.
@@ -30,3 +32,17 @@ type ModelProvider interface {
 	// NewStreaming executes the request and returns a stream of assistant responses.
 	NewStreaming(context.Context, *ModelRequest) Generator[*ModelResponse, error]
 }
+
+// ModelError is an error of a model provider's API. Providers wrap the errors
+// of their SDKs in it so callers can tell rate limits and server failures from
+// invalid requests without depending on the SDKs.
+type ModelError struct {
+	// StatusCode is the HTTP status code of the failed call, or 0 if unknown.
+	StatusCode int
+	Err        error
+}
+
+func (e *ModelError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying provider error.
+func (e *ModelError) Unwrap() error { return e.Err }
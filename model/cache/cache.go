@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/blades"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// Store keeps cached responses by key.
+type Store interface {
+	// Get returns the value stored under key. ok is false when the key is
+	// missing or its value has expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key for ttl; ttl <= 0 stores it without expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Option configures a caching ModelProvider.
+type Option func(*cachingProvider)
+
+// WithStore sets the Store of cached responses. Default is an in-memory store.
+func WithStore(store Store) Option {
+	return func(c *cachingProvider) {
+		c.store = store
+	}
+}
+
+// WithTTL sets how long responses stay cached. Default is 0, which caches
+// responses until the store evicts them.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *cachingProvider) {
+		c.ttl = ttl
+	}
+}
+
+type cachingProvider struct {
+	provider blades.ModelProvider
+	store    Store
+	ttl      time.Duration
+}
+
+// NewModelProvider returns a ModelProvider that answers repeated requests
+// from the store instead of calling provider. Requests are keyed by Key. Only
+// completed responses are cached; failures and abandoned streams are not. A
+// cached stream replays its recorded chunks, and a response cached by
+// Generate is streamed as a single completed response. Cached responses
+// report no token usage, since serving them costs nothing. A response that
+// cannot be stored is still returned, and the failure is emitted as an
+// EventCacheError.
+func NewModelProvider(provider blades.ModelProvider, opts ...Option) (blades.ModelProvider, error) {
+	if provider == nil {
+		return nil, blades.ErrModelProviderRequired
+	}
+	c := &cachingProvider{provider: provider}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.store == nil {
+		c.store = NewInMemoryStore()
+	}
+	return c, nil
+}
+
+// Name returns the name of the wrapped provider.
+func (c *cachingProvider) Name() string {
+	return c.provider.Name()
+}
+
+// Generate returns the cached response of the request, calling the provider
+// on a miss.
+func (c *cachingProvider) Generate(ctx context.Context, req *blades.ModelRequest) (*blades.ModelResponse, error) {
+	key, err := Key(c.provider.Name(), req)
+	if err != nil {
+		return nil, err
+	}
+	responses, ok, err := c.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return responses[len(responses)-1], nil
+	}
+	resp, err := c.provider.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.save(ctx, key, []*blades.ModelResponse{resp})
+	return resp, nil
+}
+
+// NewStreaming replays the cached responses of the request, streaming from
+// the provider on a miss.
+func (c *cachingProvider) NewStreaming(ctx context.Context, req *blades.ModelRequest) blades.Generator[*blades.ModelResponse, error] {
+	return func(yield func(*blades.ModelResponse, error) bool) {
+		key, err := Key(c.provider.Name(), req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		cached, ok, err := c.load(ctx, key)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if ok {
+			for _, resp := range cached {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			return
+		}
+		var responses []*blades.ModelResponse
+		for resp, err := range c.provider.NewStreaming(ctx, req) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			responses = append(responses, resp)
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		c.save(ctx, key, responses)
+	}
+}
+
+// load returns the cached responses under key with their token usage cleared.
+// Their messages get new IDs, shared by the chunks of one message, since a
+// cache hit produces new messages.
+func (c *cachingProvider) load(ctx context.Context, key string) ([]*blades.ModelResponse, bool, error) {
+	value, ok, err := c.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	var responses []*blades.ModelResponse
+	if err := json.Unmarshal(value, &responses); err != nil {
+		return nil, false, fmt.Errorf("cache: decode responses: %w", err)
+	}
+	if len(responses) == 0 {
+		return nil, false, nil
+	}
+	ids := make(map[string]string)
+	for _, resp := range responses {
+		if resp == nil || resp.Message == nil {
+			continue
+		}
+		id, ok := ids[resp.Message.ID]
+		if !ok {
+			id = blades.NewMessageID()
+			ids[resp.Message.ID] = id
+		}
+		resp.Message.ID = id
+		resp.Message.TokenUsage = blades.TokenUsage{}
+	}
+	return responses, true, nil
+}
+
+// save caches the responses under key when the last one is completed. The
+// responses were already served, so a failure to cache them does not fail the
+// call; it is emitted as an EventCacheError instead.
+func (c *cachingProvider) save(ctx context.Context, key string, responses []*blades.ModelResponse) {
+	if len(responses) == 0 {
+		return
+	}
+	final := responses[len(responses)-1]
+	if final == nil || final.Message == nil || final.Message.Status != blades.StatusCompleted {
+		return
+	}
+	value, err := json.Marshal(responses)
+	if err != nil {
+		err = fmt.Errorf("cache: encode responses: %w", err)
+	} else if err = c.store.Set(ctx, key, value, c.ttl); err != nil {
+		err = fmt.Errorf("cache: store responses: %w", err)
+	}
+	if err != nil {
+		blades.EmitEvent(ctx, &blades.Event{
+			Type:     blades.EventCacheError,
+			Err:      err,
+			Metadata: map[string]any{"model": c.provider.Name()},
+		})
+	}
+}
+
+// keyTool is the part of a tool declaration that affects the model output.
+type keyTool struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	InputSchema *jsonschema.Schema `json:"inputSchema,omitempty"`
+}
+
+// Key returns the cache key of a request to the named model: a SHA-256 hash
+// of the canonical JSON of its messages, instruction, tool declarations,
+// schemas and generation config. Message IDs, authors and metadata do not
+// affect the key.
+func Key(model string, req *blades.ModelRequest) (string, error) {
+	canonical := struct {
+		Model            string                   `json:"model"`
+		Instruction      *blades.Message          `json:"instruction,omitempty"`
+		Messages         []*blades.Message        `json:"messages"`
+		Tools            []keyTool                `json:"tools,omitempty"`
+		InputSchema      *jsonschema.Schema       `json:"inputSchema,omitempty"`
+		OutputSchema     *jsonschema.Schema       `json:"outputSchema,omitempty"`
+		GenerationConfig *blades.GenerationConfig `json:"generationConfig,omitempty"`
+	}{
+		Model:            model,
+		InputSchema:      req.InputSchema,
+		OutputSchema:     req.OutputSchema,
+		GenerationConfig: req.GenerationConfig,
+		Messages:         make([]*blades.Message, 0, len(req.Messages)),
+	}
+	// Only the role and parts of a message are sent to the model.
+	if req.Instruction != nil {
+		canonical.Instruction = &blades.Message{Role: req.Instruction.Role, Parts: req.Instruction.Parts}
+	}
+	for _, m := range req.Messages {
+		canonical.Messages = append(canonical.Messages, &blades.Message{Role: m.Role, Parts: m.Parts})
+	}
+	for _, tool := range req.Tools {
+		canonical.Tools = append(canonical.Tools, keyTool{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			InputSchema: tool.InputSchema(),
+		})
+	}
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("cache: encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// memoryEntry is a value of the in-memory store.
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryStore is a Store that keeps values in memory.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// NewInMemoryStore returns a Store that keeps values in memory. Expired
+// values are dropped when they are read.
+func NewInMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = s.now().Add(ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = entry
+	return nil
+}
@@ -0,0 +1,183 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/blades"
+	"github.com/go-kratos/blades/model/cache"
+	"github.com/go-kratos/blades/model/mock"
+)
+
+func request(text string) *blades.ModelRequest {
+	return &blades.ModelRequest{Messages: []*blades.Message{blades.UserMessage(text)}}
+}
+
+func TestCachedGenerate(t *testing.T) {
+	live := mock.NewModelProvider(mock.WithTurns(mock.Text("first"), mock.Text("second")))
+	provider, err := cache.NewModelProvider(live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for range 2 {
+		// A new message ID must not change the key.
+		resp, err := provider.Generate(ctx, request("hi"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Message.Text() != "first" {
+			t.Fatalf("response = %q, want the cached first", resp.Message.Text())
+		}
+	}
+	resp, err := provider.Generate(ctx, request("other"))
+	if err != nil || resp.Message.Text() != "second" {
+		t.Fatalf("other request = %v, %v, want a new response", resp, err)
+	}
+	if got := len(live.Requests()); got != 2 {
+		t.Fatalf("provider called %d times, want 2", got)
+	}
+}
+
+func TestCachedStreamReplaysChunks(t *testing.T) {
+	live := mock.NewModelProvider(mock.WithTurns(mock.StreamText("Hel", "lo")))
+	provider, err := cache.NewModelProvider(live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := func() []string {
+		var texts []string
+		for resp, err := range provider.NewStreaming(context.Background(), request("greet")) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			texts = append(texts, resp.Message.Text())
+		}
+		return texts
+	}
+	first, second := stream(), stream()
+	if !slices.Equal(first, second) || len(second) != 3 {
+		t.Fatalf("cached stream = %v, want %v", second, first)
+	}
+	resp, err := provider.Generate(context.Background(), request("greet"))
+	if err != nil || resp.Message.Text() != "Hello" {
+		t.Fatalf("generate from cached stream = %v, %v", resp, err)
+	}
+}
+
+func TestCacheHitsGetNewMessageIDs(t *testing.T) {
+	live := mock.NewModelProvider(mock.WithTurns(mock.Text("hi"), mock.StreamText("Hel", "lo")))
+	provider, err := cache.NewModelProvider(live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ids := make(map[string]bool)
+	for range 3 {
+		resp, err := provider.Generate(ctx, request("hi"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids[resp.Message.ID] {
+			t.Fatalf("message ID %s returned twice", resp.Message.ID)
+		}
+		ids[resp.Message.ID] = true
+	}
+	for range 2 {
+		var streamIDs []string
+		for resp, err := range provider.NewStreaming(ctx, request("greet")) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			streamIDs = append(streamIDs, resp.Message.ID)
+		}
+		if ids[streamIDs[0]] {
+			t.Fatalf("stream message ID %s returned twice", streamIDs[0])
+		}
+		ids[streamIDs[0]] = true
+	}
+}
+
+// failingStore is a Store whose writes fail.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]byte, bool, error) { return nil, false, nil }
+
+func (failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("store unavailable")
+}
+
+func TestCacheWriteFailureKeepsResponse(t *testing.T) {
+	live := mock.NewModelProvider(mock.WithTurns(mock.Text("hi"), mock.StreamText("Hel", "lo")))
+	provider, err := cache.NewModelProvider(live, cache.WithStore(failingStore{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := provider.Generate(context.Background(), request("hi"))
+	if err != nil || resp.Message.Text() != "hi" {
+		t.Fatalf("generate = %v, %v, want the live response", resp, err)
+	}
+	var texts []string
+	for resp, err := range provider.NewStreaming(context.Background(), request("greet")) {
+		if err != nil {
+			t.Fatalf("stream error after the chunks: %v", err)
+		}
+		texts = append(texts, resp.Message.Text())
+	}
+	if len(texts) != 3 {
+		t.Fatalf("stream = %v, want 3 chunks", texts)
+	}
+
+	// Inside a run, the failure is reported as an event.
+	live = mock.NewModelProvider(mock.WithTurns(mock.Text("hi")))
+	provider, err = cache.NewModelProvider(live, cache.WithStore(failingStore{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := blades.NewAgent("agent", blades.WithModel(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cacheErrs []error
+	runner := blades.NewRunner(agent, blades.WithEventHandler(func(_ context.Context, event *blades.Event) {
+		if event.Type == blades.EventCacheError {
+			cacheErrs = append(cacheErrs, event.Err)
+		}
+	}))
+	if _, err := runner.Run(context.Background(), blades.UserMessage("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if len(cacheErrs) != 1 {
+		t.Fatalf("cache error events = %v, want 1", cacheErrs)
+	}
+}
+
+func TestInMemoryStoreTTL(t *testing.T) {
+	store := cache.NewInMemoryStore()
+	ctx := context.Background()
+	if err := store.Set(ctx, "k", []byte("v"), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, err := store.Get(ctx, "k"); ok || err != nil {
+		t.Fatalf("expired value returned: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestKeyDependsOnModelAndConfig(t *testing.T) {
+	req := request("hi")
+	a, err := cache.Key("m1", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := cache.Key("m2", req)
+	withConfig := request("hi")
+	withConfig.GenerationConfig = &blades.GenerationConfig{MaxOutputTokens: 10}
+	c, _ := cache.Key("m1", withConfig)
+	if a == b || a == c {
+		t.Fatalf("keys collide: %s %s %s", a, b, c)
+	}
+}
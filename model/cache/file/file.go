@@ -0,0 +1,101 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/blades/model/cache"
+)
+
+// entry is the file format of a cached value.
+type entry struct {
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	Value     []byte    `json:"value"`
+}
+
+// store is a cache.Store that keeps one file per key.
+type store struct {
+	dir string
+	now func() time.Time
+}
+
+// NewStore returns a cache.Store that persists values under dir as
+// <key>.json, so cached responses survive restarts and can be shared by the
+// processes of a CI job. The directory is created if it does not exist.
+// Expired files are removed when they are read.
+func NewStore(dir string) (cache.Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &store{dir: dir, now: time.Now}, nil
+}
+
+// path returns the file of key, rejecting keys that would escape dir.
+func (s *store) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("file: invalid cache key %q", key)
+	}
+	return filepath.Join(s.dir, key+".json"), nil
+}
+
+func (s *store) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false, fmt.Errorf("file: decode cache entry %s: %w", key, err)
+	}
+	if !e.ExpiresAt.IsZero() && !s.now().Before(e.ExpiresAt) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, false, err
+		}
+		return nil, false, nil
+	}
+	return e.Value, true, nil
+}
+
+func (s *store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	e := entry{Value: value}
+	if ttl > 0 {
+		e.ExpiresAt = s.now().Add(ttl)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	// The entry is written to a temporary file first so readers never see a
+	// partial entry.
+	tmp, err := os.CreateTemp(s.dir, ".cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
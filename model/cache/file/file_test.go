@@ -0,0 +1,44 @@
+package file_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/blades/model/cache/file"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := file.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(ctx, "abc", []byte("cached"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(ctx, "short", []byte("gone"), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// A new store over the same directory sees the saved values.
+	store, err = file.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, ok, err := store.Get(ctx, "abc")
+	if err != nil || !ok || string(value) != "cached" {
+		t.Errorf("Get(abc) = %q, %v, %v", value, ok, err)
+	}
+	if _, ok, err := store.Get(ctx, "short"); ok || err != nil {
+		t.Errorf("Get(short) = %v, %v, want expired", ok, err)
+	}
+	if _, ok, err := store.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("Get(missing) = %v, %v", ok, err)
+	}
+	if err := store.Set(ctx, "../escape", []byte("x"), 0); err == nil {
+		t.Errorf("Set accepted a key escaping the directory")
+	}
+}
@@ -0,0 +1,181 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/blades"
+)
+
+const defaultName = "mock"
+
+// Turn is a scripted model turn.
+type Turn struct {
+	// Message is the completed message the turn answers with.
+	Message *blades.Message
+	// Chunks are streamed as incremental text before Message.
+	Chunks []string
+	// Err fails the turn.
+	Err error
+	// Handle, if set, computes the response from the request instead.
+	Handle func(context.Context, *blades.ModelRequest) (*blades.ModelResponse, error)
+}
+
+// Text returns a turn answering with an assistant text message.
+func Text(text string) Turn {
+	msg := blades.NewAssistantMessage(blades.StatusCompleted)
+	msg.Parts = append(msg.Parts, blades.TextPart{Text: text})
+	return Turn{Message: msg}
+}
+
+// StreamText returns a turn streaming the chunks one at a time and then
+// answering with their concatenation. Generate answers with the whole text.
+func StreamText(chunks ...string) Turn {
+	turn := Text(strings.Join(chunks, ""))
+	turn.Chunks = chunks
+	return turn
+}
+
+// ToolCall returns a turn calling the named tool with the JSON arguments.
+func ToolCall(name, arguments string) Turn {
+	return ToolCalls(blades.NewToolPart("call_"+name, name, arguments))
+}
+
+// ToolCalls returns a turn making several tool calls at once.
+func ToolCalls(calls ...blades.ToolPart) Turn {
+	msg := blades.NewAssistantMessage(blades.StatusCompleted)
+	msg.Role = blades.RoleTool
+	for _, call := range calls {
+		msg.Parts = append(msg.Parts, call)
+	}
+	return Turn{Message: msg}
+}
+
+// Error returns a turn failing with err.
+func Error(err error) Turn {
+	return Turn{Err: err}
+}
+
+// Func returns a turn computed by fn from the request.
+func Func(fn func(context.Context, *blades.ModelRequest) (*blades.ModelResponse, error)) Turn {
+	return Turn{Handle: fn}
+}
+
+// Option configures a mock Provider.
+type Option func(*Provider)
+
+// WithName sets the model name of the provider. Default is "mock".
+func WithName(name string) Option {
+	return func(p *Provider) {
+		p.name = name
+	}
+}
+
+// WithTurns appends turns to the script of the provider.
+func WithTurns(turns ...Turn) Option {
+	return func(p *Provider) {
+		p.turns = append(p.turns, turns...)
+	}
+}
+
+// Provider is a ModelProvider answering requests with a script of turns, one
+// turn per request, and recording the requests it receives.
+type Provider struct {
+	name     string
+	mu       sync.Mutex
+	turns    []Turn
+	requests []*blades.ModelRequest
+}
+
+// NewModelProvider returns a Provider answering with the scripted turns.
+// Requests beyond the end of the script fail.
+func NewModelProvider(opts ...Option) *Provider {
+	p := &Provider{name: defaultName}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Name returns the model name.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// Requests returns the requests received so far.
+func (p *Provider) Requests() []*blades.ModelRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.requests)
+}
+
+// Remaining returns the number of turns not played yet.
+func (p *Provider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.turns) - len(p.requests)
+}
+
+// next records the request and returns its turn. Agents reuse the request
+// across turns, so a copy of it is recorded.
+func (p *Provider) next(req *blades.ModelRequest) (Turn, error) {
+	recorded := *req
+	recorded.Messages = slices.Clone(req.Messages)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, &recorded)
+	if len(p.requests) > len(p.turns) {
+		return Turn{}, fmt.Errorf("mock: script exhausted after %d turns", len(p.turns))
+	}
+	return p.turns[len(p.requests)-1], nil
+}
+
+// Generate answers the request with the next turn.
+func (p *Provider) Generate(ctx context.Context, req *blades.ModelRequest) (*blades.ModelResponse, error) {
+	turn, err := p.next(req)
+	if err != nil {
+		return nil, err
+	}
+	return turn.response(ctx, req)
+}
+
+// NewStreaming streams the chunks of the next turn followed by its message.
+func (p *Provider) NewStreaming(ctx context.Context, req *blades.ModelRequest) blades.Generator[*blades.ModelResponse, error] {
+	return func(yield func(*blades.ModelResponse, error) bool) {
+		turn, err := p.next(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, chunk := range turn.Chunks {
+			msg := blades.NewAssistantMessage(blades.StatusIncomplete)
+			msg.Parts = append(msg.Parts, blades.TextPart{Text: chunk})
+			if !yield(&blades.ModelResponse{Message: msg}, nil) {
+				return
+			}
+		}
+		yield(turn.response(ctx, req))
+	}
+}
+
+// response returns the completed response of the turn. Every call returns a
+// new message, since agents modify the messages they receive.
+func (t Turn) response(ctx context.Context, req *blades.ModelRequest) (*blades.ModelResponse, error) {
+	if t.Handle != nil {
+		return t.Handle(ctx, req)
+	}
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	if t.Message == nil {
+		return nil, errors.New("mock: turn has no message")
+	}
+	msg := *t.Message
+	msg.ID = blades.NewMessageID()
+	msg.Parts = slices.Clone(t.Message.Parts)
+	return &blades.ModelResponse{Message: &msg}, nil
+}
@@ -0,0 +1,66 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/go-kratos/blades"
+	"github.com/go-kratos/blades/model/mock"
+	"github.com/go-kratos/blades/tools"
+)
+
+func TestProviderRunsToolLoop(t *testing.T) {
+	model := mock.NewModelProvider(mock.WithTurns(
+		mock.ToolCall("weather", `{"city":"Paris"}`),
+		mock.Text("It is sunny in Paris."),
+	))
+	weather := tools.NewTool("weather", "get the weather", tools.HandleFunc(func(context.Context, string) (string, error) {
+		return "sunny", nil
+	}))
+	agent, err := blades.NewAgent("agent", blades.WithModel(model), blades.WithTools(weather))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := blades.NewRunner(agent).Run(context.Background(), blades.UserMessage("weather in Paris?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Text() != "It is sunny in Paris." {
+		t.Errorf("output = %q", output.Text())
+	}
+	requests := model.Requests()
+	if len(requests) != 2 || model.Remaining() != 0 {
+		t.Fatalf("requests = %d, remaining = %d", len(requests), model.Remaining())
+	}
+	last := requests[1].Messages[len(requests[1].Messages)-1]
+	if part, ok := last.Parts[0].(blades.ToolPart); !ok || part.Response != "sunny" {
+		t.Errorf("second request ends with %v, want the tool response", last.Parts)
+	}
+}
+
+func TestProviderStreamsChunks(t *testing.T) {
+	model := mock.NewModelProvider(mock.WithTurns(mock.StreamText("Hel", "lo")))
+	var texts []string
+	for resp, err := range model.NewStreaming(context.Background(), &blades.ModelRequest{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, resp.Message.Text())
+	}
+	if want := []string{"Hel", "lo", "Hello"}; !slices.Equal(texts, want) {
+		t.Errorf("stream = %v, want %v", texts, want)
+	}
+}
+
+func TestProviderErrors(t *testing.T) {
+	boom := errors.New("boom")
+	model := mock.NewModelProvider(mock.WithTurns(mock.Error(boom)))
+	if _, err := model.Generate(context.Background(), &blades.ModelRequest{}); !errors.Is(err, boom) {
+		t.Errorf("first turn err = %v, want boom", err)
+	}
+	if _, err := model.Generate(context.Background(), &blades.ModelRequest{}); err == nil {
+		t.Errorf("exhausted script did not fail")
+	}
+}
@@ -0,0 +1,355 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-kratos/blades"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ErrNoRecording is returned in ModeReplay when the cassette has no unused
+// interaction matching a request.
+var ErrNoRecording = errors.New("replay: no recorded interaction matches the request")
+
+// Mode selects whether requests are served from the cassette or the provider.
+type Mode int
+
+const (
+	// ModeReplay serves every request from the cassette and never calls the
+	// provider, which may be nil.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request to the provider and records it into a
+	// new cassette, replacing the existing file.
+	ModeRecord
+	// ModeReplayOrRecord serves the requests found in the cassette and
+	// records the others, appending them to the file.
+	ModeReplayOrRecord
+)
+
+// Tool is the recorded form of a tool declared in a request.
+type Tool struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	InputSchema *jsonschema.Schema `json:"inputSchema,omitempty"`
+}
+
+// Request is the recorded form of a blades.ModelRequest. Messages only keep
+// their role, parts and status, so generated IDs and authors do not affect
+// matching.
+type Request struct {
+	Stream           bool                     `json:"stream,omitempty"`
+	Instruction      *blades.Message          `json:"instruction,omitempty"`
+	Messages         []*blades.Message        `json:"messages"`
+	Tools            []Tool                   `json:"tools,omitempty"`
+	InputSchema      *jsonschema.Schema       `json:"inputSchema,omitempty"`
+	OutputSchema     *jsonschema.Schema       `json:"outputSchema,omitempty"`
+	GenerationConfig *blades.GenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// Interaction is a recorded request with the responses of the provider. A
+// streamed request records every chunk. Error is the message of the error the
+// provider failed with, if any.
+type Interaction struct {
+	Request   *Request                `json:"request"`
+	Responses []*blades.ModelResponse `json:"responses,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// cassette is the file format of the recorded interactions.
+type cassette struct {
+	Model        string         `json:"model"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Matcher reports whether a recorded request matches the request being served.
+type Matcher func(recorded, request *Request) bool
+
+// DefaultMatcher matches requests whose recorded forms are equal.
+func DefaultMatcher(recorded, request *Request) bool {
+	a, err := json.Marshal(recorded)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(request)
+	if err != nil {
+		return false
+	}
+	return string(a) == string(b)
+}
+
+// Option configures a replay ModelProvider.
+type Option func(*replayer)
+
+// WithMode sets whether requests are replayed or recorded. Default is ModeReplay.
+func WithMode(mode Mode) Option {
+	return func(r *replayer) {
+		r.mode = mode
+	}
+}
+
+// WithMatcher sets the Matcher of recorded requests. Default is DefaultMatcher.
+func WithMatcher(matcher Matcher) Option {
+	return func(r *replayer) {
+		r.matcher = matcher
+	}
+}
+
+type replayer struct {
+	path     string
+	provider blades.ModelProvider
+	mode     Mode
+	matcher  Matcher
+	mu       sync.Mutex
+	cassette cassette
+	used     []bool
+}
+
+// NewModelProvider returns a ModelProvider that records the interactions of
+// provider into the cassette file at path and replays them offline. Identical
+// requests are answered by their recordings in the order they were recorded,
+// so a cassette replays a whole agent run, tool loops included. Recorded
+// provider errors are replayed as errors with the same message.
+func NewModelProvider(path string, provider blades.ModelProvider, opts ...Option) (blades.ModelProvider, error) {
+	r := &replayer{path: path, provider: provider, matcher: DefaultMatcher}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.mode != ModeReplay && provider == nil {
+		return nil, blades.ErrModelProviderRequired
+	}
+	if r.mode == ModeRecord {
+		r.cassette.Model = provider.Name()
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && r.mode == ModeReplayOrRecord {
+		r.cassette.Model = provider.Name()
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("replay: decode cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Name returns the name of the provider, or the recorded one when replaying.
+func (r *replayer) Name() string {
+	if r.provider != nil {
+		return r.provider.Name()
+	}
+	return r.cassette.Model
+}
+
+// Generate answers the request from the cassette or the provider.
+func (r *replayer) Generate(ctx context.Context, req *blades.ModelRequest) (*blades.ModelResponse, error) {
+	request := newRequest(req, false)
+	if interaction, ok := r.match(request); ok {
+		if interaction.Error != "" {
+			return nil, errors.New(interaction.Error)
+		}
+		if len(interaction.Responses) == 0 {
+			return nil, errors.New("replay: recorded interaction has no response")
+		}
+		responses, err := replayResponses(interaction)
+		if err != nil {
+			return nil, err
+		}
+		return responses[0], nil
+	}
+	if r.mode == ModeReplay {
+		return nil, ErrNoRecording
+	}
+	resp, err := r.provider.Generate(ctx, req)
+	interaction := &Interaction{Request: request}
+	if err != nil {
+		interaction.Error = err.Error()
+	} else {
+		interaction.Responses = append(interaction.Responses, resp)
+	}
+	if saveErr := r.record(interaction); saveErr != nil {
+		return nil, saveErr
+	}
+	return resp, err
+}
+
+// NewStreaming streams the recorded chunks of the request, or the chunks of
+// the provider while recording them.
+func (r *replayer) NewStreaming(ctx context.Context, req *blades.ModelRequest) blades.Generator[*blades.ModelResponse, error] {
+	return func(yield func(*blades.ModelResponse, error) bool) {
+		request := newRequest(req, true)
+		if interaction, ok := r.match(request); ok {
+			responses, err := replayResponses(interaction)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, resp := range responses {
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			if interaction.Error != "" {
+				yield(nil, errors.New(interaction.Error))
+			}
+			return
+		}
+		if r.mode == ModeReplay {
+			yield(nil, ErrNoRecording)
+			return
+		}
+		var (
+			interaction = &Interaction{Request: request}
+			stopped     bool
+			streamErr   error
+		)
+		for resp, err := range r.provider.NewStreaming(ctx, req) {
+			if err != nil {
+				streamErr = err
+				interaction.Error = err.Error()
+				break
+			}
+			interaction.Responses = append(interaction.Responses, resp)
+			if !yield(resp, nil) {
+				stopped = true
+				break
+			}
+		}
+		// A stream abandoned by the consumer is incomplete and not recorded.
+		if stopped {
+			return
+		}
+		if err := r.record(interaction); err != nil {
+			yield(nil, err)
+			return
+		}
+		if streamErr != nil {
+			yield(nil, streamErr)
+		}
+	}
+}
+
+// match returns the first unused recorded interaction matching request and
+// marks it used.
+func (r *replayer) match(request *Request) (*Interaction, bool) {
+	if r.mode == ModeRecord {
+		return nil, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !r.matcher(interaction.Request, request) {
+			continue
+		}
+		r.used[i] = true
+		return interaction, true
+	}
+	return nil, false
+}
+
+// replayResponses returns copies of the recorded responses of interaction, so
+// that callers cannot change the cassette. Their messages get new IDs, shared
+// by the chunks of one message, as a replayed run must not repeat message IDs.
+func replayResponses(interaction *Interaction) ([]*blades.ModelResponse, error) {
+	data, err := json.Marshal(interaction.Responses)
+	if err != nil {
+		return nil, fmt.Errorf("replay: encode responses: %w", err)
+	}
+	var responses []*blades.ModelResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("replay: decode responses: %w", err)
+	}
+	ids := make(map[string]string)
+	for _, resp := range responses {
+		if resp == nil || resp.Message == nil {
+			continue
+		}
+		id, ok := ids[resp.Message.ID]
+		if !ok {
+			id = blades.NewMessageID()
+			ids[resp.Message.ID] = id
+		}
+		resp.Message.ID = id
+	}
+	return responses, nil
+}
+
+// record appends the interaction to the cassette and rewrites the file.
+func (r *replayer) record(interaction *Interaction) error {
+	// The agent keeps mutating the messages of the request and responses, so
+	// the cassette holds a snapshot of them.
+	snapshot, err := json.Marshal(interaction)
+	if err != nil {
+		return fmt.Errorf("replay: encode interaction: %w", err)
+	}
+	interaction = &Interaction{}
+	if err := json.Unmarshal(snapshot, interaction); err != nil {
+		return fmt.Errorf("replay: decode interaction: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.used = append(r.used, true)
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("replay: encode cassette: %w", err)
+	}
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".cassette-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// newRequest returns the recorded form of req.
+func newRequest(req *blades.ModelRequest, stream bool) *Request {
+	request := &Request{
+		Stream:           stream,
+		Instruction:      normalizeMessage(req.Instruction),
+		Messages:         make([]*blades.Message, 0, len(req.Messages)),
+		InputSchema:      req.InputSchema,
+		OutputSchema:     req.OutputSchema,
+		GenerationConfig: req.GenerationConfig,
+	}
+	for _, m := range req.Messages {
+		request.Messages = append(request.Messages, normalizeMessage(m))
+	}
+	for _, tool := range req.Tools {
+		request.Tools = append(request.Tools, Tool{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			InputSchema: tool.InputSchema(),
+		})
+	}
+	return request
+}
+
+// normalizeMessage keeps the parts of m that are sent to a model.
+func normalizeMessage(m *blades.Message) *blades.Message {
+	if m == nil {
+		return nil
+	}
+	return &blades.Message{Role: m.Role, Parts: m.Parts, Status: m.Status}
+}
@@ -0,0 +1,132 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/go-kratos/blades"
+	"github.com/go-kratos/blades/model/mock"
+	"github.com/go-kratos/blades/model/replay"
+	"github.com/go-kratos/blades/tools"
+)
+
+func runAgent(t *testing.T, model blades.ModelProvider) string {
+	t.Helper()
+	echo := tools.NewTool("echo", "echo the input", tools.HandleFunc(func(_ context.Context, input string) (string, error) {
+		return input, nil
+	}))
+	agent, err := blades.NewAgent("agent", blades.WithModel(model), blades.WithTools(echo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := blades.NewRunner(agent).Run(context.Background(), blades.UserMessage("echo hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output.Text()
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	live := mock.NewModelProvider(mock.WithName("live"), mock.WithTurns(
+		mock.ToolCall("echo", `{"text":"hi"}`),
+		mock.Text("done"),
+	))
+	recorder, err := replay.NewModelProvider(path, live, replay.WithMode(replay.ModeRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := runAgent(t, recorder); got != "done" {
+		t.Fatalf("recorded run = %q, want done", got)
+	}
+
+	// Replay offline, without a provider.
+	player, err := replay.NewModelProvider(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Name() != "live" {
+		t.Errorf("name = %q, want the recorded model", player.Name())
+	}
+	if got := runAgent(t, player); got != "done" {
+		t.Fatalf("replayed run = %q, want done", got)
+	}
+	if _, err := player.Generate(context.Background(), &blades.ModelRequest{Messages: []*blades.Message{blades.UserMessage("other")}}); !errors.Is(err, replay.ErrNoRecording) {
+		t.Fatalf("unmatched request err = %v, want ErrNoRecording", err)
+	}
+}
+
+func TestRecordAndReplayStreaming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	live := mock.NewModelProvider(mock.WithTurns(mock.StreamText("Hel", "lo")))
+	recorder, err := replay.NewModelProvider(path, live, replay.WithMode(replay.ModeReplayOrRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &blades.ModelRequest{Messages: []*blades.Message{blades.UserMessage("greet")}}
+	stream := func(model blades.ModelProvider) []string {
+		var texts []string
+		for resp, err := range model.NewStreaming(context.Background(), req) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			texts = append(texts, resp.Message.Text())
+		}
+		return texts
+	}
+	recorded := stream(recorder)
+
+	// The live script is exhausted, so the second stream must come from the cassette.
+	player, err := replay.NewModelProvider(path, live, replay.WithMode(replay.ModeReplayOrRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed := stream(player); !slices.Equal(replayed, recorded) {
+		t.Fatalf("replayed chunks = %v, want %v", replayed, recorded)
+	}
+	if _, err := player.Generate(context.Background(), req); err == nil {
+		t.Fatal("a streamed recording answered a non-streaming request")
+	}
+}
+
+func TestReplayReturnsCopiesWithNewIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	live := mock.NewModelProvider(mock.WithTurns(mock.Text("hi"), mock.Text("other")))
+	recorder, err := replay.NewModelProvider(path, live, replay.WithMode(replay.ModeRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &blades.ModelRequest{Messages: []*blades.Message{blades.UserMessage("greet")}}
+	recorded, err := recorder.Generate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	player, err := replay.NewModelProvider(path, live, replay.WithMode(replay.ModeReplayOrRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := player.Generate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message.ID == recorded.Message.ID {
+		t.Fatalf("replayed message reuses the recorded ID %s", resp.Message.ID)
+	}
+	// Changing the replayed message must not change the cassette, which is
+	// rewritten when the next interaction is recorded.
+	resp.Message.Parts = []blades.Part{blades.TextPart{Text: "changed"}}
+	if _, err := player.Generate(context.Background(), &blades.ModelRequest{Messages: []*blades.Message{blades.UserMessage("other")}}); err != nil {
+		t.Fatal(err)
+	}
+	player, err = replay.NewModelProvider(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := player.Generate(context.Background(), req); err != nil || resp.Message.Text() != "hi" {
+		t.Fatalf("replayed = %v, %v, want the recorded hi", resp, err)
+	}
+}
@@ -0,0 +1,376 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/blades"
+)
+
+const (
+	defaultName            = "router"
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// Strategy selects the order in which the providers of a router are tried.
+type Strategy int
+
+const (
+	// Fallback tries the providers in the given order, so the first healthy
+	// provider serves every request.
+	Fallback Strategy = iota
+	// RoundRobin spreads requests over the providers by weight. A failed
+	// request falls back to the other providers in the given order.
+	RoundRobin
+)
+
+// ErrorClass classifies a failed provider call.
+type ErrorClass int
+
+const (
+	// ErrorUnknown is an error that could not be classified.
+	ErrorUnknown ErrorClass = iota
+	// ErrorRateLimit is a rate limit or quota error.
+	ErrorRateLimit
+	// ErrorTimeout is a call that timed out.
+	ErrorTimeout
+	// ErrorServer is a server-side failure of the provider.
+	ErrorServer
+	// ErrorInvalidRequest is a request the provider rejected as malformed.
+	// Other providers would reject it as well, so it is returned as is.
+	ErrorInvalidRequest
+)
+
+// String returns the name of the class.
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorRateLimit:
+		return "rate_limit"
+	case ErrorTimeout:
+		return "timeout"
+	case ErrorServer:
+		return "server"
+	case ErrorInvalidRequest:
+		return "invalid_request"
+	default:
+		return "unknown"
+	}
+}
+
+// Classifier decides the class of an error returned by a provider.
+type Classifier func(ctx context.Context, err error) ErrorClass
+
+// DefaultClassifier classifies errors by the status code of a
+// blades.ModelError, and network and context deadline errors as timeouts.
+// Status codes 400, 413 and 422 are invalid requests; other client errors,
+// such as an unknown model or a rejected key, are unknown so that the next
+// provider gets the request.
+func DefaultClassifier(ctx context.Context, err error) ErrorClass {
+	var modelErr *blades.ModelError
+	if errors.As(err, &modelErr) {
+		switch code := modelErr.StatusCode; {
+		case code == http.StatusTooManyRequests:
+			return ErrorRateLimit
+		case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+			return ErrorTimeout
+		case code >= http.StatusInternalServerError:
+			return ErrorServer
+		case code == http.StatusBadRequest || code == http.StatusRequestEntityTooLarge || code == http.StatusUnprocessableEntity:
+			return ErrorInvalidRequest
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorTimeout
+	}
+	return ErrorUnknown
+}
+
+// Target is a provider of a router.
+type Target struct {
+	Provider blades.ModelProvider
+	// Weight is the share of requests of the provider under RoundRobin.
+	// Values below 1 count as 1.
+	Weight int
+}
+
+// Option configures a router ModelProvider.
+type Option func(*router)
+
+// WithName sets the model name reported by the router. Default is "router".
+func WithName(name string) Option {
+	return func(r *router) {
+		r.name = name
+	}
+}
+
+// WithStrategy sets the order in which providers are tried. Default is Fallback.
+func WithStrategy(strategy Strategy) Option {
+	return func(r *router) {
+		r.strategy = strategy
+	}
+}
+
+// WithClassifier sets the Classifier of provider errors. Default is DefaultClassifier.
+func WithClassifier(classifier Classifier) Option {
+	return func(r *router) {
+		r.classifier = classifier
+	}
+}
+
+// WithCircuitBreaker skips a provider for cooldown after it failed failures
+// times in a row. Once the cooldown has passed, a single request probes the
+// provider: success closes the circuit and failure opens it again. Default
+// is 5 failures and 30 seconds; failures <= 0 disables circuit breaking.
+func WithCircuitBreaker(failures int, cooldown time.Duration) Option {
+	return func(r *router) {
+		r.breakerFailures = failures
+		r.breakerCooldown = cooldown
+	}
+}
+
+// target is a provider of the router with its load-balancing and circuit state.
+type target struct {
+	provider blades.ModelProvider
+	weight   int
+	current  int // smooth weighted round-robin counter, guarded by router.mu
+	breaker  breaker
+}
+
+type router struct {
+	name            string
+	strategy        Strategy
+	classifier      Classifier
+	breakerFailures int
+	breakerCooldown time.Duration
+	now             func() time.Time
+	mu              sync.Mutex
+	targets         []*target
+}
+
+// NewModelProvider returns a ModelProvider that serves requests from the
+// given providers, trying the next provider when one fails with anything but
+// an invalid request. Streams fall back only until their first response has
+// been yielded. When every provider fails or is circuit-broken, the error
+// wraps blades.ErrModelUnavailable and the last provider error.
+func NewModelProvider(targets []Target, opts ...Option) (blades.ModelProvider, error) {
+	r := &router{
+		name:            defaultName,
+		classifier:      DefaultClassifier,
+		breakerFailures: defaultBreakerFailures,
+		breakerCooldown: defaultBreakerCooldown,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if len(targets) == 0 {
+		return nil, blades.ErrModelProviderRequired
+	}
+	for _, t := range targets {
+		if t.Provider == nil {
+			return nil, blades.ErrModelProviderRequired
+		}
+		r.targets = append(r.targets, &target{provider: t.Provider, weight: max(t.Weight, 1)})
+	}
+	return r, nil
+}
+
+// Name returns the name of the router.
+func (r *router) Name() string {
+	return r.name
+}
+
+// Generate sends the request to the providers in turn until one succeeds.
+func (r *router) Generate(ctx context.Context, req *blades.ModelRequest) (*blades.ModelResponse, error) {
+	var lastErr error
+	for _, t := range r.order() {
+		if !t.breaker.allow(r.now()) {
+			continue
+		}
+		resp, err := t.provider.Generate(ctx, req)
+		if err == nil {
+			t.breaker.success()
+			servedBy(resp, t.provider)
+			return resp, nil
+		}
+		if r.fail(ctx, t, err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, unavailable(lastErr)
+}
+
+// NewStreaming streams the response of the first provider that does not fail
+// before its first response.
+func (r *router) NewStreaming(ctx context.Context, req *blades.ModelRequest) blades.Generator[*blades.ModelResponse, error] {
+	return func(yield func(*blades.ModelResponse, error) bool) {
+		var lastErr error
+		for _, t := range r.order() {
+			if !t.breaker.allow(r.now()) {
+				continue
+			}
+			var (
+				started   bool
+				streamErr error
+			)
+			for resp, err := range t.provider.NewStreaming(ctx, req) {
+				if err != nil {
+					streamErr = err
+					break
+				}
+				started = true
+				servedBy(resp, t.provider)
+				if !yield(resp, nil) {
+					t.breaker.success()
+					return
+				}
+			}
+			if streamErr == nil {
+				t.breaker.success()
+				return
+			}
+			// Responses already yielded cannot be taken back, so a stream
+			// that failed midway is not retried on another provider.
+			if r.fail(ctx, t, streamErr) || started {
+				yield(nil, streamErr)
+				return
+			}
+			lastErr = streamErr
+		}
+		yield(nil, unavailable(lastErr))
+	}
+}
+
+// order returns the targets in the order they are tried for a request.
+func (r *router) order() []*target {
+	if r.strategy != RoundRobin || len(r.targets) == 1 {
+		return r.targets
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var (
+		total int
+		best  int
+	)
+	for i, t := range r.targets {
+		t.current += t.weight
+		total += t.weight
+		if t.current > r.targets[best].current {
+			best = i
+		}
+	}
+	r.targets[best].current -= total
+	order := make([]*target, 0, len(r.targets))
+	order = append(order, r.targets[best])
+	for i, t := range r.targets {
+		if i != best {
+			order = append(order, t)
+		}
+	}
+	return order
+}
+
+// fail records a failed call of t and reports whether err must be returned
+// to the caller instead of trying the next provider.
+func (r *router) fail(ctx context.Context, t *target, err error) bool {
+	if ctx.Err() != nil {
+		t.breaker.release()
+		return true
+	}
+	class := r.classifier(ctx, err)
+	if class == ErrorInvalidRequest {
+		// The provider answered, so it counts as healthy.
+		t.breaker.success()
+		return true
+	}
+	if r.breakerFailures > 0 {
+		t.breaker.failure(r.now(), r.breakerFailures, r.breakerCooldown)
+	}
+	blades.EmitEvent(ctx, &blades.Event{
+		Type: blades.EventModelFallback,
+		Err:  err,
+		Metadata: map[string]any{
+			"model": t.provider.Name(),
+			"class": class.String(),
+		},
+	})
+	return false
+}
+
+// servedBy records provider as the model of the response message, unless a
+// nested router already recorded the provider it picked.
+func servedBy(resp *blades.ModelResponse, provider blades.ModelProvider) {
+	if resp != nil && resp.Message != nil && resp.Message.Model == "" {
+		resp.Message.Model = provider.Name()
+	}
+}
+
+// unavailable returns the error of a request no provider could serve.
+func unavailable(lastErr error) error {
+	if lastErr == nil {
+		return blades.ErrModelUnavailable
+	}
+	return fmt.Errorf("%w: %w", blades.ErrModelUnavailable, lastErr)
+}
+
+// breaker is the circuit breaker of a provider. The circuit is open while
+// openUntil is set; after it has passed, one probe request is let through.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a request may be sent to the provider at now.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// success closes the circuit.
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+	b.probing = false
+}
+
+// failure counts a failed request and opens the circuit for cooldown once
+// threshold failures happened in a row or the probe request failed.
+func (b *breaker) failure(now time.Time, threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.probing || b.failures >= threshold {
+		b.openUntil = now.Add(cooldown)
+		b.probing = false
+	}
+}
+
+// release ends a probe whose outcome is unknown, letting the next request probe.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
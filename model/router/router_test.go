@@ -0,0 +1,222 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/blades"
+)
+
+// fakeProvider answers with its name, or fails with the queued errors first.
+type fakeProvider struct {
+	name  string
+	errs  []error
+	calls int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) next() error {
+	p.calls++
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func (p *fakeProvider) Generate(context.Context, *blades.ModelRequest) (*blades.ModelResponse, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return &blades.ModelResponse{Message: blades.AssistantMessage(p.name)}, nil
+}
+
+func (p *fakeProvider) NewStreaming(context.Context, *blades.ModelRequest) blades.Generator[*blades.ModelResponse, error] {
+	return func(yield func(*blades.ModelResponse, error) bool) {
+		if err := p.next(); err != nil {
+			yield(nil, err)
+			return
+		}
+		yield(&blades.ModelResponse{Message: blades.AssistantMessage(p.name)}, nil)
+	}
+}
+
+func statusError(code int) error {
+	return &blades.ModelError{StatusCode: code, Err: errors.New(http.StatusText(code))}
+}
+
+func generate(t *testing.T, provider blades.ModelProvider) (string, error) {
+	t.Helper()
+	resp, err := provider.Generate(context.Background(), &blades.ModelRequest{})
+	if err != nil {
+		return "", err
+	}
+	return resp.Message.Text(), nil
+}
+
+func TestFallbackOnRetryableErrors(t *testing.T) {
+	primary := &fakeProvider{name: "primary", errs: []error{statusError(http.StatusTooManyRequests)}}
+	secondary := &fakeProvider{name: "secondary"}
+	provider, err := NewModelProvider([]Target{{Provider: primary}, {Provider: secondary}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := generate(t, provider); err != nil || got != "secondary" {
+		t.Fatalf("first request = %q, %v, want secondary", got, err)
+	}
+	if got, err := generate(t, provider); err != nil || got != "primary" {
+		t.Fatalf("second request = %q, %v, want primary", got, err)
+	}
+}
+
+func TestInvalidRequestIsNotRetried(t *testing.T) {
+	primary := &fakeProvider{name: "primary", errs: []error{statusError(http.StatusBadRequest)}}
+	secondary := &fakeProvider{name: "secondary"}
+	provider, err := NewModelProvider([]Target{{Provider: primary}, {Provider: secondary}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var modelErr *blades.ModelError
+	if _, err := generate(t, provider); !errors.As(err, &modelErr) || modelErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("err = %v, want the 400 error", err)
+	}
+	if secondary.calls != 0 {
+		t.Fatalf("secondary called %d times, want 0", secondary.calls)
+	}
+}
+
+func TestAllProvidersFailing(t *testing.T) {
+	primary := &fakeProvider{name: "primary", errs: []error{statusError(http.StatusInternalServerError)}}
+	secondary := &fakeProvider{name: "secondary", errs: []error{context.DeadlineExceeded}}
+	provider, err := NewModelProvider([]Target{{Provider: primary}, {Provider: secondary}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = generate(t, provider)
+	if !errors.Is(err, blades.ErrModelUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrModelUnavailable wrapping the last error", err)
+	}
+}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	a := &fakeProvider{name: "a"}
+	b := &fakeProvider{name: "b"}
+	provider, err := NewModelProvider([]Target{{Provider: a, Weight: 2}, {Provider: b, Weight: 1}}, WithStrategy(RoundRobin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 6 {
+		if _, err := generate(t, provider); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.calls != 4 || b.calls != 2 {
+		t.Fatalf("calls = a:%d b:%d, want a:4 b:2", a.calls, b.calls)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	failure := statusError(http.StatusServiceUnavailable)
+	primary := &fakeProvider{name: "primary", errs: []error{failure, failure, failure}}
+	secondary := &fakeProvider{name: "secondary"}
+	provider, err := NewModelProvider(
+		[]Target{{Provider: primary}, {Provider: secondary}},
+		WithCircuitBreaker(2, time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	provider.(*router).now = func() time.Time { return now }
+
+	for range 3 {
+		if got, err := generate(t, provider); err != nil || got != "secondary" {
+			t.Fatalf("request = %q, %v, want secondary", got, err)
+		}
+	}
+	if primary.calls != 2 {
+		t.Fatalf("primary called %d times while open, want 2", primary.calls)
+	}
+
+	// The probe after the cooldown fails and opens the circuit again.
+	now = now.Add(time.Minute)
+	if got, _ := generate(t, provider); got != "secondary" || primary.calls != 3 {
+		t.Fatalf("probe = %q with %d primary calls", got, primary.calls)
+	}
+	if _, _ = generate(t, provider); primary.calls != 3 {
+		t.Fatalf("primary called during the second cooldown")
+	}
+
+	// A successful probe closes the circuit.
+	now = now.Add(time.Minute)
+	for range 2 {
+		if got, err := generate(t, provider); err != nil || got != "primary" {
+			t.Fatalf("request = %q, %v, want primary", got, err)
+		}
+	}
+}
+
+func TestStreamingFallsBackBeforeFirstResponse(t *testing.T) {
+	primary := &fakeProvider{name: "primary", errs: []error{statusError(http.StatusBadGateway)}}
+	secondary := &fakeProvider{name: "secondary"}
+	provider, err := NewModelProvider([]Target{{Provider: primary}, {Provider: secondary}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for resp, err := range provider.NewStreaming(context.Background(), &blades.ModelRequest{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, resp.Message.Text())
+	}
+	if len(texts) != 1 || texts[0] != "secondary" {
+		t.Fatalf("stream = %v, want [secondary]", texts)
+	}
+}
+
+func TestResponseRecordsServingModel(t *testing.T) {
+	primary := &fakeProvider{name: "primary", errs: []error{statusError(http.StatusServiceUnavailable)}}
+	inner, err := NewModelProvider([]Target{{Provider: primary}, {Provider: &fakeProvider{name: "secondary"}}}, WithName("inner"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider, err := NewModelProvider([]Target{{Provider: inner}}, WithCircuitBreaker(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := provider.Generate(context.Background(), &blades.ModelRequest{})
+	if err != nil || resp.Message.Model != "secondary" {
+		t.Fatalf("generate model = %v, %v, want secondary", resp, err)
+	}
+	for resp, err := range provider.NewStreaming(context.Background(), &blades.ModelRequest{}) {
+		if err != nil || resp.Message.Model != "primary" {
+			t.Fatalf("stream model = %v, %v, want primary", resp, err)
+		}
+	}
+}
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{statusError(http.StatusTooManyRequests), ErrorRateLimit},
+		{statusError(http.StatusGatewayTimeout), ErrorTimeout},
+		{statusError(http.StatusInternalServerError), ErrorServer},
+		{statusError(http.StatusUnprocessableEntity), ErrorInvalidRequest},
+		{statusError(http.StatusUnauthorized), ErrorUnknown},
+		{context.DeadlineExceeded, ErrorTimeout},
+		{errors.New("boom"), ErrorUnknown},
+	}
+	for _, tt := range tests {
+		if got := DefaultClassifier(context.Background(), tt.err); got != tt.want {
+			t.Errorf("DefaultClassifier(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package blades
 
 import (
 	"context"
+	"errors"
+	"runtime/debug"
+	"slices"
 )
 
 // RunOption defines options for configuring the Runner.
@@ -14,6 +17,14 @@ func WithSession(session Session) RunOption {
 	}
 }
 
+// WithSessionID loads the session with the given ID from the Runner's
+// SessionStore, creating it if it does not exist yet.
+func WithSessionID(id string) RunOption {
+	return func(r *RunOptions) {
+		r.SessionID = id
+	}
+}
+
 // WithResume indicates whether to resume from the last session state.
 func WithResume(resume bool) RunOption {
 	return func(r *RunOptions) {
@@ -31,22 +42,108 @@ func WithInvocationID(invocationID string) RunOption {
 // RunOptions holds configuration options for running the agent.
 type RunOptions struct {
 	Session      Session
+	SessionID    string
 	Resume       bool
 	InvocationID string
+	// GenerationConfig overrides the generation parameters of every agent.
+	GenerationConfig *GenerationConfig
+	// UserID selects the "user:" state loaded into the session.
+	UserID string
+	// MaxRepairs bounds the re-prompts of RunTyped; nil uses the default.
+	MaxRepairs *int
 }
 
 // RunnerOption configures a Runner at construction time.
 type RunnerOption func(*Runner)
 
+// WithSessionStore sets the SessionStore used to load sessions by ID and to
+// persist them after every run.
+func WithSessionStore(store SessionStore) RunnerOption {
+	return func(r *Runner) {
+		r.sessionStore = store
+	}
+}
+
+// WithSessionOptions sets the options applied to every session the Runner
+// creates or loads, e.g. WithContextCompressor.
+func WithSessionOptions(opts ...SessionOption) RunnerOption {
+	return func(r *Runner) {
+		r.sessionOptions = opts
+	}
+}
+
+// WithInvocationIDGenerator sets the function generating invocation IDs for
+// runs without WithInvocationID. By default, NewInvocationID is used.
+func WithInvocationIDGenerator(generator func() string) RunnerOption {
+	return func(r *Runner) {
+		r.invocationID = generator
+	}
+}
+
+// WithGlobalMiddleware sets middlewares applied to every agent in the tree,
+// outside the agent's own middlewares.
+func WithGlobalMiddleware(ms ...Middleware) RunnerOption {
+	return func(r *Runner) {
+		r.middlewares = ms
+	}
+}
+
+// BeforeRunHook is called before the root agent runs. Returning an error aborts the run.
+type BeforeRunHook func(ctx context.Context, invocation *Invocation) error
+
+// AfterRunHook is called after a run with its last message and error, once the
+// session has been saved.
+type AfterRunHook func(ctx context.Context, invocation *Invocation, output *Message, err error)
+
+// WithBeforeRun adds a hook called before every run.
+func WithBeforeRun(hook BeforeRunHook) RunnerOption {
+	return func(r *Runner) {
+		r.beforeRun = append(r.beforeRun, hook)
+	}
+}
+
+// WithAfterRun adds a hook called after every run.
+func WithAfterRun(hook AfterRunHook) RunnerOption {
+	return func(r *Runner) {
+		r.afterRun = append(r.afterRun, hook)
+	}
+}
+
+// WithPanicRecovery makes the Runner recover panics raised by agents, model
+// providers and tools, including those raised in the goroutines that run tool
+// calls and parallel sub-agents. A recovered panic fails the run (or, in a
+// tool, the tool call) with a *PanicError.
+func WithPanicRecovery() RunnerOption {
+	return func(r *Runner) {
+		r.recoverPanics = true
+	}
+}
+
 // Runner is responsible for executing a Runnable agent within a session context.
 type Runner struct {
-	rootAgent Agent
+	rootAgent      Agent
+	sessionStore   SessionStore
+	sessionOptions []SessionOption
+	invocationID   func() string
+	middlewares    []Middleware
+	beforeRun      []BeforeRunHook
+	afterRun       []AfterRunHook
+	recoverPanics  bool
+	eventHandlers  []EventHandler
+	budget         *Budget
+	stateStore     StateStore
+	// artifactStore holds artifacts; artifactThreshold is the DataPart size
+	// above which agent outputs are moved to it.
+	artifactStore     ArtifactStore
+	artifactThreshold int
 }
 
 // NewRunner creates a new Runner with the given agent and options.
 func NewRunner(rootAgent Agent, opts ...RunnerOption) *Runner {
 	r := &Runner{
-		rootAgent: rootAgent,
+		rootAgent:    rootAgent,
+		invocationID: NewInvocationID,
+		stateStore:   NewInMemoryStateStore(),
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -54,37 +151,129 @@ func NewRunner(rootAgent Agent, opts ...RunnerOption) *Runner {
 	return r
 }
 
+// runOptions applies opts on top of the default run options.
+func (r *Runner) runOptions(opts ...RunOption) *RunOptions {
+	o := &RunOptions{
+		InvocationID: r.invocationID(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// loadSession resolves the session for a run. An explicit WithSession value
+// wins; otherwise the session is loaded from (or created in) the SessionStore,
+// falling back to a fresh in-memory session when no store is configured.
+func (r *Runner) loadSession(ctx context.Context, o *RunOptions) error {
+	if o.Session != nil {
+		return nil
+	}
+	if r.sessionStore == nil {
+		if o.SessionID != "" {
+			return ErrSessionStoreRequired
+		}
+		o.Session = NewSession(r.sessionOptions...)
+		return nil
+	}
+	if o.SessionID == "" {
+		session, err := r.sessionStore.Create(ctx, "", r.sessionOptions...)
+		if err != nil {
+			return err
+		}
+		o.Session = session
+		return nil
+	}
+	session, err := r.sessionStore.Get(ctx, o.SessionID, r.sessionOptions...)
+	if errors.Is(err, ErrSessionNotFound) {
+		session, err = r.sessionStore.Create(ctx, o.SessionID, r.sessionOptions...)
+	}
+	if err != nil {
+		return err
+	}
+	o.Session = session
+	return nil
+}
+
+// saveSession persists the session when a SessionStore is configured. It runs
+// even if the caller's context was cancelled so that partial progress survives.
+func (r *Runner) saveSession(ctx context.Context, session Session) error {
+	if r.sessionStore == nil {
+		return nil
+	}
+	return r.sessionStore.Save(context.WithoutCancel(ctx), session)
+}
+
+// runContext returns the context an invocation runs in, carrying its session,
+// event handlers, usage tracker and the runner-wide agent settings and stores.
+func (r *Runner) runContext(ctx context.Context, session Session, invocationID string, handlers []EventHandler) context.Context {
+	ctx = NewSessionContext(ctx, session)
+	ctx = newEventContext(ctx, invocationID, handlers...)
+	ctx = newUsageContext(ctx, session, r.budget)
+	if len(r.middlewares) > 0 || r.recoverPanics || r.artifactStore != nil {
+		ctx = context.WithValue(ctx, ctxRunnerKey{}, r)
+	}
+	return ctx
+}
+
+type ctxRunnerKey struct{}
+
+// runnerFromContext returns the Runner executing the invocation in ctx.
+func runnerFromContext(ctx context.Context) (*Runner, bool) {
+	r, ok := ctx.Value(ctxRunnerKey{}).(*Runner)
+	return r, ok
+}
+
+// globalMiddlewares returns the middlewares the Runner applies to every agent.
+func globalMiddlewares(ctx context.Context) []Middleware {
+	if r, ok := runnerFromContext(ctx); ok {
+		return r.middlewares
+	}
+	return nil
+}
+
+// recoverPanics reports whether the Runner in ctx recovers panics.
+func recoverPanics(ctx context.Context) bool {
+	r, ok := runnerFromContext(ctx)
+	return ok && r.recoverPanics
+}
+
+// RecoverPanic turns a panic into a *PanicError stored in *err when the Runner
+// in ctx recovers panics, and re-panics otherwise. Agents that start their own
+// goroutines defer it at the top of each one:
+//
+//	defer blades.RecoverPanic(ctx, &err)
+func RecoverPanic(ctx context.Context, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if !recoverPanics(ctx) {
+		panic(v)
+	}
+	*err = &PanicError{Value: v, Stack: debug.Stack()}
+}
+
 // buildInvocation constructs an Invocation object for the given message and options.
 func (r *Runner) buildInvocation(message *Message, stream bool, o *RunOptions) *Invocation {
 	return &Invocation{
-		ID:      o.InvocationID,
-		Session: o.Session,
-		Resume:  o.Resume,
-		Stream:  stream,
-		Message: message,
+		ID:               o.InvocationID,
+		Session:          o.Session,
+		Resume:           o.Resume,
+		Stream:           stream,
+		Message:          message,
+		GenerationConfig: o.GenerationConfig,
 	}
 }
 
 // Run executes the agent with the provided prompt and options within the session context.
 func (r *Runner) Run(ctx context.Context, message *Message, opts ...RunOption) (*Message, error) {
-	o := &RunOptions{
-		Session:      NewSession(),
-		InvocationID: NewInvocationID(),
-	}
-	for _, opt := range opts {
-		opt(o)
-	}
-	var (
-		err    error
-		output *Message
-	)
-	invocation := r.buildInvocation(message, false, o)
-	runCtx := NewSessionContext(ctx, o.Session)
-	iter := r.rootAgent.Run(runCtx, invocation)
-	for output, err = range iter {
+	var output *Message
+	for m, err := range r.run(ctx, message, false, r.eventHandlers, r.runOptions(opts...)) {
 		if err != nil {
 			return nil, err
 		}
+		output = m
 	}
 	if output == nil {
 		return nil, ErrNoFinalResponse
@@ -94,25 +283,139 @@ func (r *Runner) Run(ctx context.Context, message *Message, opts ...RunOption) (
 
 // RunStream executes the agent in a streaming manner, yielding messages as they are produced.
 func (r *Runner) RunStream(ctx context.Context, message *Message, opts ...RunOption) Generator[*Message, error] {
-	o := &RunOptions{
-		Session:      NewSession(),
-		InvocationID: NewInvocationID(),
-	}
-	for _, opt := range opts {
-		opt(o)
+	return r.runStream(ctx, message, r.eventHandlers, opts...)
+}
+
+// RunEvents executes the agent in a streaming manner, yielding the structured
+// lifecycle events of the run instead of bare messages. Handlers registered
+// with WithEventHandler still receive every event.
+func (r *Runner) RunEvents(ctx context.Context, message *Message, opts ...RunOption) Generator[*Event, error] {
+	return func(yield func(*Event, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var (
+			events = make(chan *Event)
+			done   = make(chan error, 1)
+		)
+		forward := func(_ context.Context, event *Event) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+		}
+		handlers := append(slices.Clone(r.eventHandlers), forward)
+		go func() {
+			defer close(events)
+			var runErr error
+			for _, err := range r.runStream(ctx, message, handlers, opts...) {
+				if err != nil {
+					runErr = err
+					break
+				}
+			}
+			done <- runErr
+		}()
+		for event := range events {
+			if !yield(event, nil) {
+				cancel()
+				for range events {
+				}
+				return
+			}
+		}
+		if err := <-done; err != nil {
+			yield(nil, err)
+		}
 	}
-	invocation := r.buildInvocation(message, true, o)
-	runCtx := NewSessionContext(ctx, o.Session)
+}
+
+// runStream executes the agent in a streaming manner, delivering lifecycle
+// events to the given handlers.
+func (r *Runner) runStream(ctx context.Context, message *Message, handlers []EventHandler, opts ...RunOption) Generator[*Message, error] {
+	options := r.runOptions(opts...)
 	return func(yield func(*Message, error) bool) {
-		iter := r.rootAgent.Run(runCtx, invocation)
-		for output, err := range iter {
-			if err != nil {
-				yield(nil, err)
+		o := *options
+		for m, err := range r.run(ctx, message, true, handlers, &o) {
+			if !yield(m, err) {
 				return
 			}
-			if !yield(output, nil) {
-				return
+		}
+	}
+}
+
+// errRunStopped reports that the consumer of a run stopped iterating early.
+var errRunStopped = errors.New("run stopped by consumer")
+
+// run executes one invocation of the root agent: it loads the session and its
+// scoped state, runs the before hooks and the agent, saves the scoped state and
+// the session, and runs the after hooks.
+func (r *Runner) run(ctx context.Context, message *Message, stream bool, handlers []EventHandler, o *RunOptions) Generator[*Message, error] {
+	return func(yield func(*Message, error) bool) {
+		if err := r.loadSession(ctx, o); err != nil {
+			yield(nil, err)
+			return
+		}
+		loaded, err := r.loadScopedState(ctx, o.Session, o.UserID)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		invocation := r.buildInvocation(message, stream, o)
+		runCtx := r.runContext(ctx, o.Session, invocation.ID, handlers)
+		output, err := r.execute(runCtx, invocation, yield)
+		saveUsage(runCtx)
+		if stateErr := r.saveScopedState(ctx, o.Session, o.UserID, loaded); err == nil {
+			err = stateErr
+		}
+		if saveErr := r.saveSession(ctx, o.Session); err == nil {
+			err = saveErr
+		}
+		hookErr := err
+		if errors.Is(hookErr, errRunStopped) {
+			hookErr = nil
+		}
+		for _, hook := range r.afterRun {
+			hook(runCtx, invocation, output, hookErr)
+		}
+		if hookErr != nil {
+			yield(nil, hookErr)
+		}
+	}
+}
+
+// execute runs the before hooks and the root agent, yielding its messages. It
+// returns the last message, and errRunStopped if the consumer stopped early.
+func (r *Runner) execute(ctx context.Context, invocation *Invocation, yield func(*Message, error) bool) (output *Message, err error) {
+	if r.recoverPanics {
+		yielding := false
+		defer func() {
+			if v := recover(); v != nil {
+				if yielding {
+					panic(v) // the consumer panicked, not the agent
+				}
+				err = &PanicError{Value: v, Stack: debug.Stack()}
 			}
+		}()
+		next := yield
+		yield = func(m *Message, err error) bool {
+			yielding = true
+			defer func() { yielding = false }()
+			return next(m, err)
 		}
 	}
+	for _, hook := range r.beforeRun {
+		if err := hook(ctx, invocation); err != nil {
+			return nil, err
+		}
+	}
+	for m, err := range r.rootAgent.Run(ctx, invocation) {
+		if err != nil {
+			return output, err
+		}
+		output = m
+		if !yield(m, nil) {
+			return output, errRunStopped
+		}
+	}
+	return output, nil
 }
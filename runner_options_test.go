@@ -0,0 +1,174 @@
+package blades
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
+)
+
+func TestRunnerSessionOptionsApplyToStoredSessions(t *testing.T) {
+	t.Parallel()
+
+	model := &captureMessagesModel{}
+	agent, err := NewAgent("agent", WithModel(model))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	runner := NewRunner(agent,
+		WithSessionStore(NewInMemorySessionStore()),
+		WithSessionOptions(WithContextCompressor(&limitCompressor{max: 1})),
+	)
+	for _, text := range []string{"turn1", "turn2"} {
+		if _, err := runner.Run(context.Background(), UserMessage(text), WithSessionID("s1")); err != nil {
+			t.Fatalf("run %s: %v", text, err)
+		}
+	}
+	if got, want := len(model.captured[1]), 1; got != want {
+		t.Fatalf("second call messages = %d, want %d (compressed)", got, want)
+	}
+	if got, want := model.captured[1][0].Text(), "turn2"; got != want {
+		t.Fatalf("second call message = %q, want %q", got, want)
+	}
+}
+
+func TestRunnerHooksAndInvocationIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	agent, err := NewAgent("agent", WithModel(&captureMessagesModel{}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	var (
+		before []string
+		after  []string
+	)
+	runner := NewRunner(agent,
+		WithInvocationIDGenerator(func() string { return "inv-fixed" }),
+		WithBeforeRun(func(_ context.Context, invocation *Invocation) error {
+			before = append(before, invocation.ID)
+			return nil
+		}),
+		WithAfterRun(func(_ context.Context, invocation *Invocation, output *Message, err error) {
+			after = append(after, invocation.ID+":"+output.Text())
+		}),
+	)
+	if _, err := runner.Run(context.Background(), UserMessage("hi")); err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+	if got, want := before, []string{"inv-fixed"}; !slices.Equal(got, want) {
+		t.Fatalf("before hooks = %v, want %v", got, want)
+	}
+	if got, want := after, []string{"inv-fixed:reply"}; !slices.Equal(got, want) {
+		t.Fatalf("after hooks = %v, want %v", got, want)
+	}
+
+	errDenied := errors.New("denied")
+	denying := NewRunner(agent, WithBeforeRun(func(context.Context, *Invocation) error { return errDenied }))
+	if _, err := denying.Run(context.Background(), UserMessage("hi")); !errors.Is(err, errDenied) {
+		t.Fatalf("run error = %v, want %v", err, errDenied)
+	}
+}
+
+func TestRunnerGlobalMiddlewareAppliesToSubAgents(t *testing.T) {
+	t.Parallel()
+
+	sub, err := NewAgent("sub", WithModel(&usageModel{name: "small"}))
+	if err != nil {
+		t.Fatalf("new sub agent: %v", err)
+	}
+	root, err := NewAgent("root", WithModel(&usageModel{name: "large", tool: "sub"}), WithTools(NewAgentTool(sub)))
+	if err != nil {
+		t.Fatalf("new root agent: %v", err)
+	}
+	var (
+		mu     sync.Mutex
+		agents []string
+	)
+	record := func(next Handler) Handler {
+		return HandleFunc(func(ctx context.Context, invocation *Invocation) Generator[*Message, error] {
+			if agent, ok := FromAgentContext(ctx); ok {
+				mu.Lock()
+				agents = append(agents, agent.Name())
+				mu.Unlock()
+			}
+			return next.Handle(ctx, invocation)
+		})
+	}
+	runner := NewRunner(root, WithGlobalMiddleware(record))
+	if _, err := runner.Run(context.Background(), UserMessage("hi")); err != nil {
+		t.Fatalf("runner run: %v", err)
+	}
+	if got, want := agents, []string{"root", "sub"}; !slices.Equal(got, want) {
+		t.Fatalf("middleware saw agents %v, want %v", got, want)
+	}
+}
+
+type panicModel struct{}
+
+func (panicModel) Name() string { return "panic" }
+
+func (panicModel) Generate(context.Context, *ModelRequest) (*ModelResponse, error) {
+	panic("model exploded")
+}
+
+func (panicModel) NewStreaming(context.Context, *ModelRequest) Generator[*ModelResponse, error] {
+	panic("model exploded")
+}
+
+func TestRunnerPanicRecovery(t *testing.T) {
+	t.Parallel()
+
+	agent, err := NewAgent("agent", WithModel(panicModel{}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	runner := NewRunner(agent, WithPanicRecovery())
+	_, err = runner.Run(context.Background(), UserMessage("hi"))
+	var panicErr *PanicError
+	if !errors.Is(err, ErrPanic) || !errors.As(err, &panicErr) {
+		t.Fatalf("run error = %v, want a PanicError", err)
+	}
+	if panicErr.Value != "model exploded" || len(panicErr.Stack) == 0 || strings.Contains(err.Error(), "goroutine") {
+		t.Fatalf("panic error = %q, value %v, stack %d bytes", err, panicErr.Value, len(panicErr.Stack))
+	}
+	for _, err := range runner.RunStream(context.Background(), UserMessage("hi")) {
+		if !errors.Is(err, ErrPanic) {
+			t.Fatalf("stream error = %v, want ErrPanic", err)
+		}
+	}
+
+	// A panicking tool fails only its call, which the tool error policy reports to the model.
+	tool := bladestools.NewTool("echo", "echo", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		panic("tool exploded")
+	}))
+	model := &toolLoopSessionModel{}
+	toolAgent, err := NewAgent("tool-agent", WithModel(model), WithTools(tool), WithToolErrorPolicy(nil))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	output, err := NewRunner(toolAgent, WithPanicRecovery()).Run(context.Background(), UserMessage("hi"))
+	if err != nil {
+		t.Fatalf("run with panicking tool: %v", err)
+	}
+	if got, want := output.Text(), "done"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+
+	// A panic in the tool call goroutine, outside the tool itself, fails the run.
+	failing := bladestools.NewTool("echo", "echo", bladestools.HandleFunc(func(context.Context, string) (string, error) {
+		return "", errors.New("tool failed")
+	}))
+	classifierAgent, err := NewAgent("classifier-agent", WithModel(&toolLoopSessionModel{}), WithTools(failing),
+		WithToolErrorPolicy(func(context.Context, ToolPart, error) ToolErrorKind { panic("classifier exploded") }))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	if _, err := NewRunner(classifierAgent, WithPanicRecovery()).Run(context.Background(), UserMessage("hi")); !errors.As(err, &panicErr) || panicErr.Value != "classifier exploded" {
+		t.Fatalf("run error = %v, want the classifier panic", err)
+	}
+}
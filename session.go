@@ -2,6 +2,8 @@ package blades
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/go-kratos/kit/container/maps"
 	"github.com/go-kratos/kit/container/slices"
@@ -42,6 +44,115 @@ func NewSession(opts ...SessionOption) Session {
 	return session
 }
 
+// RestoreSession rebuilds an in-memory Session from a snapshot, typically one
+// loaded by a SessionStore. The snapshot ID is preserved; an empty ID gets a new UUID.
+// The session holds copies of the snapshot messages.
+func RestoreSession(snapshot *SessionSnapshot, opts ...SessionOption) Session {
+	session := &sessionInMemory{
+		id:              snapshot.ID,
+		parentID:        snapshot.ParentID,
+		parentMessageID: snapshot.ParentMessageID,
+	}
+	if session.id == "" {
+		session.id = uuid.NewString()
+	}
+	for key, value := range snapshot.State {
+		if value != nil {
+			session.state.Store(key, value)
+		}
+	}
+	for _, message := range copyMessages(snapshot.History) {
+		session.history.Append(message)
+	}
+	for _, opt := range opts {
+		opt(session)
+	}
+	return session
+}
+
+// SnapshotSession captures the ID, state and raw (uncompressed) history of a
+// session so it can be persisted by a SessionStore.
+func SnapshotSession(session Session) (*SessionSnapshot, error) {
+	s, ok := session.(interface {
+		Snapshot() *SessionSnapshot
+	})
+	if !ok {
+		return nil, fmt.Errorf("blades: session %T does not support snapshots", session)
+	}
+	return s.Snapshot(), nil
+}
+
+// ForkSession creates a session with the given ID (a generated one if empty)
+// that branches off session at the message with messageID: its history is a
+// copy of session's up to and including that message, its state is a copy of
+// session's state, and it records session as its parent. An empty messageID
+// forks at the end of the history. Persist the fork with SessionStore.Save.
+func ForkSession(session Session, messageID, id string, opts ...SessionOption) (Session, error) {
+	snapshot, err := SnapshotSession(session)
+	if err != nil {
+		return nil, err
+	}
+	history := snapshot.History
+	if messageID != "" {
+		i := indexMessage(history, messageID)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrMessageNotFound, messageID)
+		}
+		history = history[:i+1]
+	} else if len(history) > 0 {
+		messageID = history[len(history)-1].ID
+	}
+	return RestoreSession(&SessionSnapshot{
+		ID:              id,
+		State:           snapshot.State.Clone(),
+		History:         history,
+		ParentID:        snapshot.ID,
+		ParentMessageID: messageID,
+	}, opts...), nil
+}
+
+// RewindSession removes every message after the message with messageID from
+// the session history, e.g. to regenerate the answer to that message.
+func RewindSession(session Session, messageID string) error {
+	return rewindSession(session, messageID, false)
+}
+
+// TruncateSession removes the message with messageID and every message after
+// it from the session history, e.g. to replace an earlier user turn.
+func TruncateSession(session Session, messageID string) error {
+	return rewindSession(session, messageID, true)
+}
+
+func rewindSession(session Session, messageID string, inclusive bool) error {
+	s, ok := session.(interface {
+		Rewind(messageID string, inclusive bool) error
+	})
+	if !ok {
+		return fmt.Errorf("blades: session %T does not support rewinding", session)
+	}
+	return s.Rewind(messageID, inclusive)
+}
+
+// SessionParent returns the ID of the session that session was forked from and
+// the ID of the message it was forked at. Both are empty for root sessions.
+func SessionParent(session Session) (sessionID, messageID string) {
+	snapshot, err := SnapshotSession(session)
+	if err != nil {
+		return "", ""
+	}
+	return snapshot.ParentID, snapshot.ParentMessageID
+}
+
+// indexMessage returns the index of the message with the given ID, or -1.
+func indexMessage(messages []*Message, id string) int {
+	for i, m := range messages {
+		if m.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
 type ctxSessionKey struct{}
 
 // NewSessionContext returns a new Context that carries the session value.
@@ -66,29 +177,113 @@ func EnsureSession(ctx context.Context) Session {
 
 // sessionInMemory is an in-memory implementation of the Session interface.
 type sessionInMemory struct {
-	id         string
-	state      maps.Map[string, any]
-	history    slices.Slice[*Message]
-	compressor ContextCompressor
+	id              string
+	parentID        string
+	parentMessageID string
+	state           maps.Map[string, any]
+	deltaMu         sync.Mutex
+	delta           State
+	history         slices.Slice[*Message]
+	compressor      ContextCompressor
 }
 
 func (s *sessionInMemory) ID() string {
 	return s.id
 }
 func (s *sessionInMemory) State() State {
-	return s.state.ToMap()
+	state := State{}
+	s.state.Range(func(key string, value any) bool {
+		if value != nil {
+			state[key] = value
+		}
+		return true
+	})
+	return state
 }
 func (s *sessionInMemory) History(ctx context.Context) ([]*Message, error) {
 	messages := s.history.ToSlice()
 	if s.compressor == nil {
 		return messages, nil
 	}
-	return s.compressor.Compress(ctx, messages)
+	compressed, err := s.compressor.Compress(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) < len(messages) {
+		EmitEvent(ctx, &Event{
+			Type:     EventContextCompressed,
+			Metadata: map[string]any{"before": len(messages), "after": len(compressed)},
+		})
+	}
+	return compressed, nil
 }
+
+// SetState sets key to value. A nil value deletes key, since the state map
+// cannot hold nil values.
 func (s *sessionInMemory) SetState(key string, value any) {
+	if value == nil {
+		s.DeleteState(key)
+		return
+	}
 	s.state.Store(key, value)
+	s.recordDelta(key, value)
+}
+
+// DeleteState removes key from the state.
+func (s *sessionInMemory) DeleteState(key string) {
+	s.state.Delete(key)
+	s.recordDelta(key, nil)
+}
+
+// TakeStateDelta returns the state changes since the last call and resets them.
+func (s *sessionInMemory) TakeStateDelta() State {
+	s.deltaMu.Lock()
+	defer s.deltaMu.Unlock()
+	delta := s.delta
+	s.delta = nil
+	return delta
+}
+
+func (s *sessionInMemory) recordDelta(key string, value any) {
+	s.deltaMu.Lock()
+	defer s.deltaMu.Unlock()
+	if s.delta == nil {
+		s.delta = State{}
+	}
+	s.delta[key] = value
 }
 func (s *sessionInMemory) Append(ctx context.Context, message *Message) error {
 	s.history.Append(message)
 	return nil
 }
+
+// Snapshot returns the ID, state and raw history of the session. The history
+// holds copies of the messages, so later changes to the session do not
+// affect the snapshot.
+func (s *sessionInMemory) Snapshot() *SessionSnapshot {
+	return &SessionSnapshot{
+		ID:              s.id,
+		State:           s.state.ToMap(),
+		History:         copyMessages(s.history.ToSlice()),
+		ParentID:        s.parentID,
+		ParentMessageID: s.parentMessageID,
+	}
+}
+
+// Rewind drops the messages after the message with messageID, and the message
+// itself when inclusive is true. It must not run concurrently with Append.
+func (s *sessionInMemory) Rewind(messageID string, inclusive bool) error {
+	messages := s.history.ToSlice()
+	i := indexMessage(messages, messageID)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrMessageNotFound, messageID)
+	}
+	if !inclusive {
+		i++
+	}
+	s.history = slices.Slice[*Message]{}
+	for _, message := range messages[:i] {
+		s.history.Append(message)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/blades"
+	"github.com/google/uuid"
+)
+
+const fileExt = ".json"
+
+// sessionStore is a SessionStore that keeps one JSON document per session.
+type sessionStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewSessionStore returns a SessionStore that persists every session as a JSON
+// file named <id>.json inside dir. The directory is created if it does not exist.
+// Writes are atomic (write to a temporary file, then rename), so a shared
+// volume can back several replicas; concurrent saves of the same session are
+// last-writer-wins.
+func NewSessionStore(dir string) (blades.SessionStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &sessionStore{dir: dir}, nil
+}
+
+// path returns the file path for a session ID, rejecting IDs that would escape dir.
+func (s *sessionStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("file: invalid session id %q", id)
+	}
+	return filepath.Join(s.dir, id+fileExt), nil
+}
+
+func (s *sessionStore) read(id string) (*blades.SessionSnapshot, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, blades.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshot blades.SessionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("file: decode session %s: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+func (s *sessionStore) write(snapshot *blades.SessionSnapshot) error {
+	path, err := s.path(snapshot.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *sessionStore) Create(ctx context.Context, id string, opts ...blades.SessionOption) (blades.Session, error) {
+	if id == "" {
+		id = uuid.NewString()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.read(id); err == nil {
+		return nil, blades.ErrSessionExists
+	} else if !errors.Is(err, blades.ErrSessionNotFound) {
+		return nil, err
+	}
+	snapshot := &blades.SessionSnapshot{ID: id}
+	if err := s.write(snapshot); err != nil {
+		return nil, err
+	}
+	return blades.RestoreSession(snapshot, opts...), nil
+}
+
+func (s *sessionStore) Get(ctx context.Context, id string, opts ...blades.SessionOption) (blades.Session, error) {
+	s.mu.Lock()
+	snapshot, err := s.read(id)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return blades.RestoreSession(snapshot, opts...), nil
+}
+
+func (s *sessionStore) Save(ctx context.Context, session blades.Session) error {
+	snapshot, err := blades.SnapshotSession(session)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(snapshot)
+}
+
+func (s *sessionStore) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, fileExt))
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (s *sessionStore) Delete(ctx context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(path); errors.Is(err, fs.ErrNotExist) {
+		return blades.ErrSessionNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
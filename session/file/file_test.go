@@ -0,0 +1,108 @@
+package file_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/blades"
+	"github.com/go-kratos/blades/session/file"
+)
+
+func TestSessionStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := store.Create(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.SetState("city", "Paris")
+	session.Append(ctx, blades.UserMessage("hello"))
+	reply := blades.AssistantMessage("hi")
+	reply.Parts = append(reply.Parts, blades.ToolPart{ID: "call_1", Name: "lookup", Request: `{}`, Response: `{"ok":true}`, Completed: true})
+	session.Append(ctx, reply)
+	if err := store.Save(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.State()["city"]; got != "Paris" {
+		t.Errorf("state[city] = %v, want Paris", got)
+	}
+	history, err := loaded.History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history len = %d, want 2", len(history))
+	}
+	if history[0].Text() != "hello" {
+		t.Errorf("history[0] = %q, want hello", history[0].Text())
+	}
+	tool, ok := history[1].Parts[1].(blades.ToolPart)
+	if !ok {
+		t.Fatalf("history[1].Parts[1] = %T, want ToolPart", history[1].Parts[1])
+	}
+	if tool.Response != `{"ok":true}` || !tool.Completed {
+		t.Errorf("tool part = %+v", tool)
+	}
+}
+
+func TestSessionStore_CreateExisting(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create(ctx, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create(ctx, "s1"); !errors.Is(err, blades.ErrSessionExists) {
+		t.Errorf("err = %v, want ErrSessionExists", err)
+	}
+}
+
+func TestSessionStore_ListDelete(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"b", "a"} {
+		if _, err := store.Create(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Errorf("ids = %v, want [a b]", ids)
+	}
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, blades.ErrSessionNotFound) {
+		t.Errorf("err = %v, want ErrSessionNotFound", err)
+	}
+	if err := store.Delete(ctx, "a"); !errors.Is(err, blades.ErrSessionNotFound) {
+		t.Errorf("err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSessionStore_InvalidID(t *testing.T) {
+	store, err := file.NewSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create(context.Background(), "../escape"); err == nil {
+		t.Error("expected error for session id containing a path separator")
+	}
+}
@@ -0,0 +1,121 @@
+package blades
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// SessionSnapshot is the persisted form of a Session: its ID, state and raw
+// message history. State values are stored as-is, so backends that serialize
+// to JSON return numbers as float64 and structs as maps after a round trip.
+type SessionSnapshot struct {
+	ID      string     `json:"id"`
+	State   State      `json:"state,omitempty"`
+	History []*Message `json:"history,omitempty"`
+	// ParentID and ParentMessageID record the session and message a forked
+	// session was created from; both are empty for root sessions.
+	ParentID        string `json:"parentId,omitempty"`
+	ParentMessageID string `json:"parentMessageId,omitempty"`
+}
+
+// copyMessages returns copies of messages, so that changing the parts,
+// actions, metadata or state delta of a copy leaves the original unchanged.
+func copyMessages(messages []*Message) []*Message {
+	copies := make([]*Message, len(messages))
+	for i, m := range messages {
+		if m == nil {
+			continue
+		}
+		c := *m
+		c.Parts = slices.Clone(m.Parts)
+		c.Actions = maps.Clone(m.Actions)
+		c.Metadata = maps.Clone(m.Metadata)
+		c.StateDelta = maps.Clone(m.StateDelta)
+		copies[i] = &c
+	}
+	return copies
+}
+
+// SessionStore persists sessions so that conversations survive restarts and
+// can be shared across replicas. Sessions returned by Create and Get are
+// detached copies; changes become durable once they are passed to Save.
+type SessionStore interface {
+	// Create creates an empty session with the given ID. An empty ID is replaced
+	// with a generated one. It returns ErrSessionExists if the ID is taken.
+	Create(ctx context.Context, id string, opts ...SessionOption) (Session, error)
+	// Get loads the session with the given ID, or returns ErrSessionNotFound.
+	Get(ctx context.Context, id string, opts ...SessionOption) (Session, error)
+	// Save persists the current state and history of the session.
+	Save(ctx context.Context, session Session) error
+	// List returns the IDs of all stored sessions.
+	List(ctx context.Context) ([]string, error)
+	// Delete removes the session with the given ID, or returns ErrSessionNotFound.
+	Delete(ctx context.Context, id string) error
+}
+
+// inMemorySessionStore is a SessionStore that keeps snapshots in process memory.
+type inMemorySessionStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]*SessionSnapshot
+}
+
+// NewInMemorySessionStore creates a SessionStore backed by process memory.
+// It is mainly useful for tests and single-process deployments.
+func NewInMemorySessionStore() SessionStore {
+	return &inMemorySessionStore{snapshots: make(map[string]*SessionSnapshot)}
+}
+
+func (s *inMemorySessionStore) Create(ctx context.Context, id string, opts ...SessionOption) (Session, error) {
+	if id == "" {
+		id = uuid.NewString()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.snapshots[id]; ok {
+		return nil, ErrSessionExists
+	}
+	snapshot := &SessionSnapshot{ID: id}
+	s.snapshots[id] = snapshot
+	return RestoreSession(snapshot, opts...), nil
+}
+
+func (s *inMemorySessionStore) Get(ctx context.Context, id string, opts ...SessionOption) (Session, error) {
+	s.mu.RLock()
+	snapshot, ok := s.snapshots[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return RestoreSession(snapshot, opts...), nil
+}
+
+func (s *inMemorySessionStore) Save(ctx context.Context, session Session) error {
+	snapshot, err := SnapshotSession(session)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.snapshots[snapshot.ID] = snapshot
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *inMemorySessionStore) List(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.snapshots)), nil
+}
+
+func (s *inMemorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.snapshots[id]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(s.snapshots, id)
+	return nil
+}
@@ -0,0 +1,130 @@
+package blades
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInMemorySessionStore_DetachedUntilSave(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySessionStore()
+	session, err := store.Create(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.SetState("key", "value")
+
+	loaded, err := store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.State()["key"]; ok {
+		t.Fatal("unsaved state should not be visible")
+	}
+	if err := store.Save(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.State()["key"]; got != "value" {
+		t.Errorf("state[key] = %v, want value", got)
+	}
+}
+
+func TestInMemorySessionStore_DetachedMessages(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemorySessionStore()
+	session, err := store.Create(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := UserMessage("hello")
+	if err := session.Append(ctx, message); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	message.Parts[0] = TextPart{Text: "changed"}
+
+	loaded, err := store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := loaded.History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history[0].Parts[0] = TextPart{Text: "changed again"}
+
+	loaded, err = store.Get(ctx, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err = loaded.History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := history[0].Text(); got != "hello" {
+		t.Errorf("saved message = %q, want hello", got)
+	}
+}
+
+func TestInMemorySessionStore_NotFound(t *testing.T) {
+	store := NewInMemorySessionStore()
+	if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestRunnerRun_LoadsSessionByID(t *testing.T) {
+	t.Parallel()
+
+	model := &countingSessionModel{}
+	agent, err := NewAgent("store-agent", WithModel(model))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	store := NewInMemorySessionStore()
+	runner := NewRunner(agent, WithSessionStore(store))
+
+	if _, err := runner.Run(context.Background(), UserMessage("hi"), WithSessionID("conversation")); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	for _, err := range runner.RunStream(context.Background(), UserMessage("again"), WithSessionID("conversation")) {
+		if err != nil {
+			t.Fatalf("second run: %v", err)
+		}
+	}
+
+	session, err := store.Get(context.Background(), "conversation")
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("session history: %v", err)
+	}
+	if got, want := len(history), 4; got != want {
+		t.Fatalf("session history len = %d, want %d", got, want)
+	}
+	if got, want := history[2].Text(), "again"; got != want {
+		t.Fatalf("history[2] text = %q, want %q", got, want)
+	}
+}
+
+func TestRunnerRun_SessionIDWithoutStore(t *testing.T) {
+	t.Parallel()
+
+	agent, err := NewAgent("no-store-agent", WithModel(&countingSessionModel{}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	_, err = NewRunner(agent).Run(context.Background(), UserMessage("hi"), WithSessionID("conversation"))
+	if !errors.Is(err, ErrSessionStoreRequired) {
+		t.Fatalf("err = %v, want ErrSessionStoreRequired", err)
+	}
+}
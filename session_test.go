@@ -2,6 +2,7 @@ package blades
 
 import (
 	"context"
+	"errors"
 	"testing"
 )
 
@@ -68,3 +69,74 @@ func (l *limitCompressor) Compress(_ context.Context, messages []*Message) ([]*M
 	}
 	return messages[len(messages)-l.max:], nil
 }
+
+func TestForkSession(t *testing.T) {
+	ctx := context.Background()
+	parent := NewSession()
+	parent.SetState("topic", "go")
+	question := UserMessage("question")
+	answer := AssistantMessage("answer")
+	for _, m := range []*Message{question, answer, UserMessage("follow-up")} {
+		parent.Append(ctx, m)
+	}
+
+	fork, err := ForkSession(parent, answer.ID, "fork-1")
+	if err != nil {
+		t.Fatalf("fork: %v", err)
+	}
+	if fork.ID() != "fork-1" {
+		t.Errorf("fork ID = %q, want %q", fork.ID(), "fork-1")
+	}
+	history, _ := fork.History(ctx)
+	if len(history) != 2 || history[1].ID != answer.ID {
+		t.Fatalf("fork history = %d messages, want 2 ending at the answer", len(history))
+	}
+	if fork.State()["topic"] != "go" {
+		t.Errorf("fork state[topic] = %v, want go", fork.State()["topic"])
+	}
+	parentID, messageID := SessionParent(fork)
+	if parentID != parent.ID() || messageID != answer.ID {
+		t.Errorf("SessionParent = (%q, %q), want (%q, %q)", parentID, messageID, parent.ID(), answer.ID)
+	}
+
+	// The branches evolve independently.
+	fork.Append(ctx, UserMessage("alternative"))
+	fork.SetState("topic", "rust")
+	if history, _ := parent.History(ctx); len(history) != 3 {
+		t.Errorf("parent history len = %d, want 3", len(history))
+	}
+	if parent.State()["topic"] != "go" {
+		t.Errorf("parent state changed by fork")
+	}
+
+	if _, err := ForkSession(parent, "missing", ""); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("fork at unknown message error = %v, want ErrMessageNotFound", err)
+	}
+}
+
+func TestRewindAndTruncateSession(t *testing.T) {
+	ctx := context.Background()
+	session := NewSession()
+	first := UserMessage("first")
+	reply := AssistantMessage("reply")
+	second := UserMessage("second")
+	for _, m := range []*Message{first, reply, second, AssistantMessage("reply 2")} {
+		session.Append(ctx, m)
+	}
+
+	if err := RewindSession(session, second.ID); err != nil {
+		t.Fatalf("rewind: %v", err)
+	}
+	if history, _ := session.History(ctx); len(history) != 3 || history[2].ID != second.ID {
+		t.Fatalf("history after rewind = %d messages, want 3 ending at second", len(history))
+	}
+	if err := TruncateSession(session, reply.ID); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	if history, _ := session.History(ctx); len(history) != 1 || history[0].ID != first.ID {
+		t.Fatalf("history after truncate = %d messages, want only first", len(history))
+	}
+	if err := RewindSession(session, reply.ID); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("rewind to removed message error = %v, want ErrMessageNotFound", err)
+	}
+}
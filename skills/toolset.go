@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"iter"
 	"os"
 	"os/exec"
 	"path"
@@ -440,6 +441,47 @@ func (t *runSkillScriptTool) InputSchema() *jsonschema.Schema {
 func (t *runSkillScriptTool) OutputSchema() *jsonschema.Schema { return nil }
 
 func (t *runSkillScriptTool) Handle(ctx context.Context, input string) (string, error) {
+	return t.run(ctx, input, nil), nil
+}
+
+// HandleStream runs the script like Handle, reporting its standard output
+// as it is written.
+func (t *runSkillScriptTool) HandleStream(ctx context.Context, input string) iter.Seq2[*tools.Update, error] {
+	return func(yield func(*tools.Update, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var (
+			updates = make(chan string)
+			done    = make(chan string, 1)
+		)
+		go func() {
+			done <- t.run(ctx, input, func(stdout string) {
+				select {
+				case updates <- stdout:
+				case <-ctx.Done():
+				}
+			})
+		}()
+		for {
+			select {
+			case stdout := <-updates:
+				if !yield(&tools.Update{Output: stdout}, nil) {
+					cancel()
+					<-done
+					return
+				}
+			case output := <-done:
+				yield(&tools.Update{Output: output, Progress: 1}, nil)
+				return
+			}
+		}
+	}
+}
+
+// run runs the script requested by input and returns the tool response. The
+// standard output written so far is passed to report, if set, after every
+// write.
+func (t *runSkillScriptTool) run(ctx context.Context, input string, report func(stdout string)) string {
 	var req struct {
 		SkillName      string            `json:"skill_name"`
 		ScriptPath     string            `json:"script_path"`
@@ -448,37 +490,37 @@ func (t *runSkillScriptTool) Handle(ctx context.Context, input string) (string,
 		TimeoutSeconds int               `json:"timeout_seconds"`
 	}
 	if err := json.Unmarshal([]byte(input), &req); err != nil {
-		return invalidArgs(fmt.Sprintf("Invalid tool arguments: %v", err)), nil
+		return invalidArgs(fmt.Sprintf("Invalid tool arguments: %v", err))
 	}
 	if req.SkillName == "" {
 		return mustJSON(map[string]any{
 			"error":      "Skill name is required.",
 			"error_code": "MISSING_SKILL_NAME",
-		}), nil
+		})
 	}
 	if req.ScriptPath == "" {
 		return mustJSON(map[string]any{
 			"error":      "Script path is required.",
 			"error_code": "MISSING_SCRIPT_PATH",
-		}), nil
+		})
 	}
 	skill, ok := t.toolset.skillByName[req.SkillName]
 	if !ok {
-		return skillNotFound(req.SkillName), nil
+		return skillNotFound(req.SkillName)
 	}
 	scriptName, fullScriptPath, err := normalizeScriptPath(req.ScriptPath)
 	if err != nil {
 		return mustJSON(map[string]any{
 			"error":      err.Error(),
 			"error_code": "INVALID_SCRIPT_PATH",
-		}), nil
+		})
 	}
 	resources := skill.resources
 	if _, found := resources.GetScript(scriptName); !found {
 		return mustJSON(map[string]any{
 			"error":      fmt.Sprintf("Script %q not found in skill %q.", fullScriptPath, req.SkillName),
 			"error_code": "SCRIPT_NOT_FOUND",
-		}), nil
+		})
 	}
 
 	timeoutSeconds := req.TimeoutSeconds
@@ -489,7 +531,7 @@ func (t *runSkillScriptTool) Handle(ctx context.Context, input string) (string,
 		return mustJSON(map[string]any{
 			"error":      fmt.Sprintf("timeout_seconds must be between 1 and %d.", maxScriptTimeoutSeconds),
 			"error_code": "INVALID_TIMEOUT",
-		}), nil
+		})
 	}
 	for key, value := range req.Env {
 		if key == "" ||
@@ -499,7 +541,7 @@ func (t *runSkillScriptTool) Handle(ctx context.Context, input string) (string,
 			return mustJSON(map[string]any{
 				"error":      "Environment variable names must be non-empty, must not contain '=', and keys/values must not contain NUL.",
 				"error_code": "INVALID_ENV",
-			}), nil
+			})
 		}
 	}
 
@@ -508,7 +550,7 @@ func (t *runSkillScriptTool) Handle(ctx context.Context, input string) (string,
 		return mustJSON(map[string]any{
 			"error":      fmt.Sprintf("Failed to prepare skill workspace: %v", err),
 			"error_code": "WORKSPACE_ERROR",
-		}), nil
+		})
 	}
 	defer os.RemoveAll(tmpRoot)
 
@@ -516,10 +558,10 @@ func (t *runSkillScriptTool) Handle(ctx context.Context, input string) (string,
 		return mustJSON(map[string]any{
 			"error":      fmt.Sprintf("Failed to materialize skill workspace: %v", err),
 			"error_code": "WORKSPACE_ERROR",
-		}), nil
+		})
 	}
 
-	return executeSkillScript(ctx, tmpRoot, req.SkillName, fullScriptPath, req.Args, req.Env, timeoutSeconds), nil
+	return executeSkillScript(ctx, tmpRoot, req.SkillName, fullScriptPath, req.Args, req.Env, timeoutSeconds, report)
 }
 
 func normalizeResourcePath(resourcePath string) (resourceType string, resourceName string, err error) {
@@ -673,6 +715,7 @@ func executeSkillScript(
 	args []string,
 	env map[string]string,
 	timeoutSeconds int,
+	report func(stdout string),
 ) string {
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
 	defer cancel()
@@ -693,6 +736,9 @@ func executeSkillScript(
 	cmd.Env = mergeCommandEnv(os.Environ(), env)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
+	if report != nil {
+		cmd.Stdout = &reportWriter{buffer: &stdout, report: report}
+	}
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
@@ -727,3 +773,15 @@ func executeSkillScript(
 		"status":      status,
 	})
 }
+
+// reportWriter writes to buffer and reports all of its content after every write.
+type reportWriter struct {
+	buffer *bytes.Buffer
+	report func(string)
+}
+
+func (w *reportWriter) Write(p []byte) (int, error) {
+	n, err := w.buffer.Write(p)
+	w.report(w.buffer.String())
+	return n, err
+}
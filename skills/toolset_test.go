@@ -591,6 +591,59 @@ func TestRunSkillScriptToolExecutesScript(t *testing.T) {
 	}
 }
 
+func TestRunSkillScriptToolStreamsStdout(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script execution is not supported on windows in this test")
+	}
+
+	skill := &staticSkill{
+		frontmatter: Frontmatter{Name: "skill1", Description: "Skill 1"},
+		instruction: "",
+		resources: Resources{
+			Scripts: map[string]string{
+				"run.sh": "#!/bin/sh\necho first\nsleep 0.1\necho second\n",
+			},
+		},
+	}
+	toolset, err := NewToolset([]Skill{skill})
+	if err != nil {
+		t.Fatalf("new toolset: %v", err)
+	}
+	tool, ok := toolset.Tools()[3].(bladestools.StreamingTool)
+	if !ok {
+		t.Fatalf("run_skill_script does not implement StreamingTool")
+	}
+	var updates []*bladestools.Update
+	for update, err := range tool.HandleStream(context.Background(), `{"skill_name":"skill1","script_path":"scripts/run.sh"}`) {
+		if err != nil {
+			t.Fatalf("tool error: %v", err)
+		}
+		updates = append(updates, update)
+	}
+	if len(updates) < 2 {
+		t.Fatalf("expected partial stdout before the result, got %d updates", len(updates))
+	}
+	if !strings.Contains(updates[0].Output, "first") || strings.Contains(updates[0].Output, "second") {
+		t.Fatalf("unexpected first update: %q", updates[0].Output)
+	}
+	last := updates[len(updates)-1]
+	if last.Progress != 1 {
+		t.Fatalf("final progress = %v, want 1", last.Progress)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(last.Output), &obj); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if obj["status"] != "success" {
+		t.Fatalf("unexpected status: %v", obj["status"])
+	}
+	stdout, _ := obj["stdout"].(string)
+	if stdout != "first\nsecond\n" {
+		t.Fatalf("unexpected stdout: %q", stdout)
+	}
+}
+
 func TestRunSkillScriptToolAnyExecutable(t *testing.T) {
 	t.Parallel()
 	if runtime.GOOS == "windows" {
@@ -1,7 +1,23 @@
 package blades
 
 import (
+	"context"
 	"maps"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// State key prefixes select the scope of a session state value.
+const (
+	// AppStatePrefix marks state shared by every session of the Runner.
+	AppStatePrefix = "app:"
+	// UserStatePrefix marks state shared by every session of the same user,
+	// as set by WithUserID.
+	UserStatePrefix = "user:"
+	// TempStatePrefix marks state that only lives for one invocation; it is
+	// cleared after every run.
+	TempStatePrefix = "temp:"
 )
 
 // State holds arbitrary key-value pairs representing the state.
@@ -14,3 +30,212 @@ func (s State) Clone() State {
 	}
 	return State(maps.Clone(map[string]any(s)))
 }
+
+// Scope returns the values whose keys start with prefix, keyed as in s.
+func (s State) Scope(prefix string) State {
+	scoped := State{}
+	for key, value := range s {
+		if strings.HasPrefix(key, prefix) {
+			scoped[key] = value
+		}
+	}
+	return scoped
+}
+
+// StateStore persists the app- and user-scoped state that a Runner shares
+// across sessions. The scope is "app" for app state and "user:<id>" for the
+// state of a user.
+type StateStore interface {
+	Load(ctx context.Context, scope string) (State, error)
+	Save(ctx context.Context, scope string, state State) error
+}
+
+// NewInMemoryStateStore creates a StateStore that keeps scoped state in memory.
+func NewInMemoryStateStore() StateStore {
+	return &stateStoreInMemory{scopes: make(map[string]State)}
+}
+
+type stateStoreInMemory struct {
+	mu     sync.RWMutex
+	scopes map[string]State
+}
+
+func (s *stateStoreInMemory) Load(ctx context.Context, scope string) (State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.scopes[scope].Clone(), nil
+}
+
+func (s *stateStoreInMemory) Save(ctx context.Context, scope string, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scopes[scope] = state.Clone()
+	return nil
+}
+
+// WithStateStore sets the StateStore holding app- and user-scoped state. By
+// default, the Runner keeps it in memory.
+func WithStateStore(store StateStore) RunnerOption {
+	return func(r *Runner) {
+		r.stateStore = store
+	}
+}
+
+// WithUserID sets the user whose "user:" state is loaded into the session.
+func WithUserID(id string) RunOption {
+	return func(r *RunOptions) {
+		r.UserID = id
+	}
+}
+
+// stateScopes returns the StateStore scopes of a run with their key prefixes.
+func stateScopes(userID string) map[string]string {
+	scopes := map[string]string{"app": AppStatePrefix}
+	if userID != "" {
+		scopes["user:"+userID] = UserStatePrefix
+	}
+	return scopes
+}
+
+// loadScopedState copies the app and user state from the StateStore into
+// session, replacing the scoped values it holds, and returns the loaded state.
+func (r *Runner) loadScopedState(ctx context.Context, session Session, userID string) (State, error) {
+	loaded := State{}
+	current := session.State()
+	for scope, prefix := range stateScopes(userID) {
+		state, err := r.stateStore.Load(ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+		state = state.Scope(prefix)
+		for key := range current.Scope(prefix) {
+			if _, ok := state[key]; !ok {
+				deleteState(session, key)
+			}
+		}
+		for key, value := range state {
+			session.SetState(key, value)
+			loaded[key] = value
+		}
+	}
+	// The loaded values are not changes made by the invocation.
+	TakeStateDelta(session)
+	return loaded, nil
+}
+
+// saveScopedState writes the changes the run made to the app and user state
+// of session back to the StateStore and clears its temp state. Only the keys
+// changed since loaded are written, so concurrent runs do not overwrite each
+// other's scoped state.
+func (r *Runner) saveScopedState(ctx context.Context, session Session, userID string, loaded State) error {
+	ctx = context.WithoutCancel(ctx)
+	state := session.State()
+	for key := range state.Scope(TempStatePrefix) {
+		deleteState(session, key)
+	}
+	for scope, prefix := range stateScopes(userID) {
+		delta := scopedDelta(loaded.Scope(prefix), state.Scope(prefix))
+		if len(delta) == 0 {
+			continue
+		}
+		stored, err := r.stateStore.Load(ctx, scope)
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			stored = State{}
+		}
+		for key, value := range delta {
+			if value == nil {
+				delete(stored, key)
+			} else {
+				stored[key] = value
+			}
+		}
+		if err := r.stateStore.Save(ctx, scope, stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scopedDelta returns the values of state that differ from loaded, with the
+// keys removed since loaded mapped to nil.
+func scopedDelta(loaded, state State) State {
+	delta := State{}
+	for key, value := range state {
+		if previous, ok := loaded[key]; !ok || !reflect.DeepEqual(previous, value) {
+			delta[key] = value
+		}
+	}
+	for key := range loaded {
+		if _, ok := state[key]; !ok {
+			delta[key] = nil
+		}
+	}
+	return delta
+}
+
+// deleteState removes key from the session state, or sets it to nil when the
+// session cannot delete keys.
+func deleteState(session Session, key string) {
+	if s, ok := session.(interface{ DeleteState(string) }); ok {
+		s.DeleteState(key)
+		return
+	}
+	session.SetState(key, nil)
+}
+
+// TakeStateDelta returns the state changes recorded by session since the last
+// call and resets them. A deleted key maps to nil. It returns nil for sessions
+// that do not record changes.
+func TakeStateDelta(session Session) State {
+	s, ok := session.(interface{ TakeStateDelta() State })
+	if !ok {
+		return nil
+	}
+	return s.TakeStateDelta()
+}
+
+// ReplayState rebuilds the session state from the state deltas of messages.
+// Temp state is skipped since it does not outlive its invocation.
+func ReplayState(messages []*Message) State {
+	state := State{}
+	for _, m := range messages {
+		for key, value := range m.StateDelta {
+			switch {
+			case strings.HasPrefix(key, TempStatePrefix):
+			case value == nil:
+				delete(state, key)
+			default:
+				state[key] = value
+			}
+		}
+	}
+	return state
+}
+
+// stateDeltas is a Middleware that attaches the session state changes made
+// since the previous completed message to each completed message.
+func stateDeltas(session Session) Middleware {
+	return func(next Handler) Handler {
+		return HandleFunc(func(ctx context.Context, invocation *Invocation) Generator[*Message, error] {
+			return func(yield func(*Message, error) bool) {
+				for m, err := range next.Handle(ctx, invocation) {
+					if err == nil && m != nil && m.Status == StatusCompleted {
+						if delta := TakeStateDelta(session); len(delta) > 0 {
+							if m.StateDelta == nil {
+								m.StateDelta = delta
+							} else {
+								maps.Copy(m.StateDelta, delta)
+							}
+						}
+					}
+					if !yield(m, err) {
+						return
+					}
+				}
+			}
+		})
+	}
+}
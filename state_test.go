@@ -0,0 +1,163 @@
+package blades
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// setStateMiddleware sets the given state before the agent handles the invocation.
+func setStateMiddleware(state State) Middleware {
+	return func(next Handler) Handler {
+		return HandleFunc(func(ctx context.Context, invocation *Invocation) Generator[*Message, error] {
+			for key, value := range state {
+				invocation.Session.SetState(key, value)
+			}
+			return next.Handle(ctx, invocation)
+		})
+	}
+}
+
+func TestRunnerScopedState(t *testing.T) {
+	t.Parallel()
+
+	writer, err := NewAgent("writer", WithModel(&usageModel{name: "m"}), WithMiddleware(setStateMiddleware(State{
+		"app:theme":  "dark",
+		"user:name":  "alice",
+		"temp:draft": "scratch",
+	})))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	store := NewInMemoryStateStore()
+	if _, err := NewRunner(writer, WithStateStore(store)).Run(context.Background(), UserMessage("hi"), WithUserID("u1")); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	reader, err := NewAgent("reader", WithModel(&usageModel{name: "m"}))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	runner := NewRunner(reader, WithStateStore(store))
+	for _, tc := range []struct {
+		user string
+		want State
+	}{
+		{user: "u1", want: State{"app:theme": "dark", "user:name": "alice"}},
+		{user: "u2", want: State{"app:theme": "dark"}},
+	} {
+		session := NewSession()
+		if _, err := runner.Run(context.Background(), UserMessage("hi"), WithSession(session), WithUserID(tc.user)); err != nil {
+			t.Fatalf("run %s: %v", tc.user, err)
+		}
+		state := session.State()
+		for key, want := range tc.want {
+			if got := state[key]; got != want {
+				t.Fatalf("user %s: state[%q] = %v, want %v", tc.user, key, got, want)
+			}
+		}
+		if got, want := len(state.Scope(UserStatePrefix)), len(tc.want.Scope(UserStatePrefix)); got != want {
+			t.Fatalf("user %s: user state = %v, want %v", tc.user, state.Scope(UserStatePrefix), tc.want)
+		}
+		if _, ok := state["temp:draft"]; ok {
+			t.Fatalf("user %s: temp state survived the run", tc.user)
+		}
+	}
+}
+
+func TestScopedStateSavesOnlyChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewInMemoryStateStore()
+	if err := store.Save(ctx, "app", State{"app:keep": 1, "app:drop": 2}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// A concurrent run saves app:other while this run is in progress.
+	concurrent := func(next Handler) Handler {
+		return HandleFunc(func(ctx context.Context, invocation *Invocation) Generator[*Message, error] {
+			if err := store.Save(ctx, "app", State{"app:keep": 1, "app:drop": 2, "app:other": 3}); err != nil {
+				t.Errorf("save: %v", err)
+			}
+			deleteState(invocation.Session, "app:drop")
+			invocation.Session.SetState("app:new", 4)
+			return next.Handle(ctx, invocation)
+		})
+	}
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m"}), WithMiddleware(concurrent))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	if _, err := NewRunner(agent, WithStateStore(store)).Run(ctx, UserMessage("hi")); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	got, err := store.Load(ctx, "app")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := State{"app:keep": 1, "app:other": 3, "app:new": 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("app state = %v, want %v", got, want)
+	}
+}
+
+func TestTempStateClearedAfterRun(t *testing.T) {
+	t.Parallel()
+
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m"}), WithMiddleware(setStateMiddleware(State{"temp:step": 1})))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	session := NewSession()
+	var seen any
+	runner := NewRunner(agent, WithAfterRun(func(context.Context, *Invocation, *Message, error) {
+		seen = session.State()["temp:step"]
+	}))
+	if _, err := runner.Run(context.Background(), UserMessage("hi"), WithSession(session)); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if seen != nil {
+		t.Fatalf("temp state = %v after run, want cleared", seen)
+	}
+}
+
+func TestMessageStateDelta(t *testing.T) {
+	t.Parallel()
+
+	agent, err := NewAgent("agent",
+		WithModel(&usageModel{name: "m"}),
+		WithOutputKey("answer"),
+		WithMiddleware(setStateMiddleware(State{"temp:step": 1})),
+	)
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	session := NewSession()
+	session.SetState("before", "run")
+	output, err := NewRunner(agent).Run(context.Background(), UserMessage("hi"), WithSession(session))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	delta := output.StateDelta
+	if got := delta["answer"]; got != "done" {
+		t.Fatalf("delta[answer] = %v, want done", got)
+	}
+	if got := delta["temp:step"]; got != 1 {
+		t.Fatalf("delta[temp:step] = %v, want 1", got)
+	}
+	if _, ok := delta["before"]; ok {
+		t.Fatalf("delta records a change made before the run: %v", delta)
+	}
+
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	state := ReplayState(history)
+	if got := state["answer"]; got != "done" {
+		t.Fatalf("replayed answer = %v, want done", got)
+	}
+	if _, ok := state["temp:step"]; ok {
+		t.Fatalf("replayed state keeps temp state: %v", state)
+	}
+}
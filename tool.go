@@ -2,6 +2,8 @@ package blades
 
 import (
 	"context"
+	"iter"
+	"strings"
 
 	"github.com/go-kratos/blades/tools"
 	"github.com/google/jsonschema-go/jsonschema"
@@ -39,20 +41,17 @@ func (a *agentTool) OutputSchema() *jsonschema.Schema {
 
 // Handle runs the underlying Agent with the given input and returns the output.
 // The sub-agent runs in an isolated session so its internal conversation history
-// does not pollute the calling agent's session.
+// does not pollute the calling agent's session. It inherits the GenerationConfig
+// of the calling invocation except for the ToolChoice, which refers to the
+// caller's tools.
 func (a *agentTool) Handle(ctx context.Context, input string) (string, error) {
-	msg := UserMessage(input)
-	// Attribute the delegation message to the calling agent rather than "user".
-	if caller, ok := FromAgentContext(ctx); ok {
-		msg.Author = caller.Name()
-	}
 	subCtx := NewSessionContext(ctx, NewSession())
-	iter := a.Agent.Run(subCtx, &Invocation{Message: msg})
+	stream := a.Agent.Run(subCtx, a.delegation(ctx, input, false))
 	var (
 		err   error
 		final *Message
 	)
-	for final, err = range iter {
+	for final, err = range stream {
 		if err != nil {
 			return "", err
 		}
@@ -62,3 +61,57 @@ func (a *agentTool) Handle(ctx context.Context, input string) (string, error) {
 	}
 	return "", ErrNoFinalResponse
 }
+
+// HandleStream runs the underlying Agent in streaming mode, reporting the text
+// it has produced so far as the tool output.
+func (a *agentTool) HandleStream(ctx context.Context, input string) iter.Seq2[*tools.Update, error] {
+	return func(yield func(*tools.Update, error) bool) {
+		subCtx := NewSessionContext(ctx, NewSession())
+		stream := a.Agent.Run(subCtx, a.delegation(ctx, input, true))
+		var (
+			text  strings.Builder
+			final *Message
+		)
+		for m, err := range stream {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if m.Role != RoleAssistant {
+				continue
+			}
+			if m.Status == StatusCompleted {
+				final = m
+				text.Reset()
+				continue
+			}
+			text.WriteString(m.Text())
+			if !yield(&tools.Update{Output: text.String()}, nil) {
+				return
+			}
+		}
+		if final == nil {
+			yield(nil, ErrNoFinalResponse)
+			return
+		}
+		yield(&tools.Update{Output: final.Text(), Progress: 1}, nil)
+	}
+}
+
+// delegation builds the invocation the underlying Agent is run with. Its user
+// message is attributed to the calling agent rather than "user".
+func (a *agentTool) delegation(ctx context.Context, input string, stream bool) *Invocation {
+	msg := UserMessage(input)
+	if caller, ok := FromAgentContext(ctx); ok {
+		msg.Author = caller.Name()
+	}
+	invocation := &Invocation{Message: msg, Stream: stream}
+	if tc, ok := tools.FromContext(ctx); ok {
+		if t, ok := tc.(*toolContext); ok && t.generationConfig != nil {
+			config := *t.generationConfig
+			config.ToolChoice = nil
+			invocation.GenerationConfig = &config
+		}
+	}
+	return invocation
+}
@@ -0,0 +1,201 @@
+package blades
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// pendingToolCallsKeyPrefix prefixes the session state key under which an agent
+// records the tool calls that are waiting for a human decision.
+const pendingToolCallsKeyPrefix = "blades:pending_tool_calls:"
+
+// ToolApprovalFunc reports whether a tool call must be approved by a human before it runs.
+type ToolApprovalFunc func(ctx context.Context, part ToolPart) (bool, error)
+
+// RequireToolApproval returns a ToolApprovalFunc that requires approval for the
+// named tools, or for every tool when no names are given.
+func RequireToolApproval(names ...string) ToolApprovalFunc {
+	return func(ctx context.Context, part ToolPart) (bool, error) {
+		return len(names) == 0 || slices.Contains(names, part.Name), nil
+	}
+}
+
+// ToolDecisionAction is the action a human takes on a pending tool call.
+type ToolDecisionAction string
+
+const (
+	// ToolApprove runs the tool call as requested by the model.
+	ToolApprove ToolDecisionAction = "approve"
+	// ToolEdit runs the tool call with the edited request arguments.
+	ToolEdit ToolDecisionAction = "edit"
+	// ToolReject skips the tool call and tells the model it was rejected.
+	ToolReject ToolDecisionAction = "reject"
+)
+
+// ToolDecision is a human decision on a pending tool call.
+type ToolDecision struct {
+	Action ToolDecisionAction `json:"action"`
+	// Request replaces the tool call arguments when Action is ToolEdit.
+	Request string `json:"request,omitempty"`
+	// Reason is reported to the model when Action is ToolReject.
+	Reason string `json:"reason,omitempty"`
+}
+
+// PendingToolCall is a tool call waiting for a human decision.
+type PendingToolCall struct {
+	Agent    string        `json:"agent"`
+	ID       string        `json:"id"`
+	Name     string        `json:"name"`
+	Request  string        `json:"request"`
+	Decision *ToolDecision `json:"decision,omitempty"`
+}
+
+// pendingToolCalls is the session state recorded when an agent pauses for approval.
+type pendingToolCalls struct {
+	// Message is the model's tool-call message, replayed on resume.
+	Message *Message          `json:"message"`
+	Calls   []PendingToolCall `json:"calls"`
+}
+
+// PendingToolCalls returns the tool calls recorded in the session that are
+// waiting for a human decision, ordered by agent name.
+func PendingToolCalls(session Session) []PendingToolCall {
+	var (
+		calls []PendingToolCall
+		state = session.State()
+		keys  = make([]string, 0, len(state))
+	)
+	for key := range state {
+		if strings.HasPrefix(key, pendingToolCallsKeyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if pending, ok := decodePendingToolCalls(state[key]); ok {
+			calls = append(calls, pending.Calls...)
+		}
+	}
+	return calls
+}
+
+// ResolveToolCall records a decision for the pending tool call with the given ID.
+// Once every pending call has a decision, run the agent again with WithResume(true).
+func ResolveToolCall(session Session, id string, decision ToolDecision) error {
+	switch decision.Action {
+	case ToolApprove, ToolEdit, ToolReject:
+	default:
+		return fmt.Errorf("blades: invalid tool decision action %q", decision.Action)
+	}
+	for key, value := range session.State() {
+		if !strings.HasPrefix(key, pendingToolCallsKeyPrefix) {
+			continue
+		}
+		pending, ok := decodePendingToolCalls(value)
+		if !ok {
+			continue
+		}
+		for i := range pending.Calls {
+			if pending.Calls[i].ID == id {
+				pending.Calls[i].Decision = &decision
+				session.SetState(key, pending)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrToolCallNotPending, id)
+}
+
+// decodePendingToolCalls reads the pending state, which is a JSON-shaped value
+// when the session was loaded by a persistent SessionStore.
+func decodePendingToolCalls(value any) (*pendingToolCalls, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case *pendingToolCalls:
+		return v, true
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		var pending pendingToolCalls
+		if err := json.Unmarshal(b, &pending); err != nil || pending.Message == nil {
+			return nil, false
+		}
+		return &pending, true
+	}
+}
+
+// requestToolApprovals checks the tool calls of message against the approval
+// policy. If any call needs approval, the message is recorded in the session
+// and true is returned so the caller interrupts the run.
+func (a *agent) requestToolApprovals(ctx context.Context, session Session, message *Message) (bool, error) {
+	var calls []PendingToolCall
+	for _, part := range message.Parts {
+		v, ok := part.(ToolPart)
+		if !ok || v.Completed {
+			continue
+		}
+		required, err := a.toolApproval(ctx, v)
+		if err != nil {
+			return false, err
+		}
+		if required {
+			calls = append(calls, PendingToolCall{Agent: a.name, ID: v.ID, Name: v.Name, Request: v.Request})
+		}
+	}
+	if len(calls) == 0 {
+		return false, nil
+	}
+	session.SetState(pendingToolCallsKeyPrefix+a.name, &pendingToolCalls{Message: message, Calls: calls})
+	return true, nil
+}
+
+// resumeToolApprovals returns the recorded tool-call message with the human
+// decisions applied, or nil if the agent has no pending tool calls. It returns
+// ErrInterrupted while some calls are still undecided.
+func (a *agent) resumeToolApprovals(session Session) (*Message, error) {
+	key := pendingToolCallsKeyPrefix + a.name
+	pending, ok := decodePendingToolCalls(session.State()[key])
+	if !ok {
+		return nil, nil
+	}
+	decisions := make(map[string]ToolDecision, len(pending.Calls))
+	for _, call := range pending.Calls {
+		if call.Decision == nil {
+			return nil, fmt.Errorf("%w: tool call %s is awaiting approval", ErrInterrupted, call.ID)
+		}
+		decisions[call.ID] = *call.Decision
+	}
+	message := pending.Message
+	for i, part := range message.Parts {
+		v, ok := part.(ToolPart)
+		if !ok {
+			continue
+		}
+		decision, ok := decisions[v.ID]
+		if !ok {
+			continue
+		}
+		switch decision.Action {
+		case ToolEdit:
+			v.Request = decision.Request
+		case ToolReject:
+			reason := "tool call rejected by user"
+			if decision.Reason != "" {
+				reason += ": " + decision.Reason
+			}
+			v.Response = toolErrorResponse(errors.New(reason))
+			v.Completed = true
+		}
+		message.Parts[i] = v
+	}
+	deleteState(session, key)
+	return message, nil
+}
@@ -0,0 +1,54 @@
+package blades
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ToolErrorKind classifies a failed tool call.
+type ToolErrorKind int
+
+const (
+	// ToolErrorRetryable reports the error to the model as the tool response,
+	// giving it the chance to fix its arguments or pick another tool.
+	ToolErrorRetryable ToolErrorKind = iota
+	// ToolErrorFatal aborts the agent run with the error.
+	ToolErrorFatal
+	// ToolErrorInterrupt aborts the agent run with an error wrapping ErrInterrupted,
+	// so the invocation can be resumed later.
+	ToolErrorInterrupt
+)
+
+// String returns the name of the kind.
+func (k ToolErrorKind) String() string {
+	switch k {
+	case ToolErrorRetryable:
+		return "retryable"
+	case ToolErrorFatal:
+		return "fatal"
+	case ToolErrorInterrupt:
+		return "interrupt"
+	default:
+		return "unknown"
+	}
+}
+
+// ToolErrorClassifier decides how a failed tool call is handled.
+type ToolErrorClassifier func(ctx context.Context, part ToolPart, err error) ToolErrorKind
+
+// DefaultToolErrorClassifier treats every tool error as retryable, except when the
+// context is done, in which case the run is aborted.
+func DefaultToolErrorClassifier(ctx context.Context, part ToolPart, err error) ToolErrorKind {
+	if ctx.Err() != nil {
+		return ToolErrorFatal
+	}
+	return ToolErrorRetryable
+}
+
+// toolErrorResponse renders err as the JSON payload sent back to the model.
+func toolErrorResponse(err error) string {
+	b, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+	return string(b)
+}
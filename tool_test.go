@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"testing"
+
+	bladestools "github.com/go-kratos/blades/tools"
 )
 
 type scriptedAgent struct {
@@ -172,3 +174,36 @@ func TestAgentToolHandleDefaultsAuthorToUser(t *testing.T) {
 		t.Fatalf("expected Author %q, got %q", "user", got)
 	}
 }
+
+func TestAddToolContent(t *testing.T) {
+	t.Parallel()
+
+	tool := bladestools.NewTool("screenshot", "take a screenshot", bladestools.HandleFunc(func(ctx context.Context, _ string) (string, error) {
+		if err := AddToolContent(ctx, DataPart{Bytes: []byte("png"), MIMEType: MIMEImagePNG}); err != nil {
+			return "", err
+		}
+		return "captured", nil
+	}))
+	agent, err := NewAgent("agent", WithModel(&usageModel{name: "m", tool: "screenshot"}), WithTools(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	session := NewSession()
+	if _, err := NewRunner(agent).Run(context.Background(), UserMessage("look"), WithSession(session)); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	part := history[1].Parts[0].(ToolPart)
+	if part.Response != "captured" || len(part.Content) != 1 {
+		t.Fatalf("tool part = %+v", part)
+	}
+	if data, ok := part.Content[0].(DataPart); !ok || string(data.Bytes) != "png" {
+		t.Fatalf("content = %#v", part.Content[0])
+	}
+	if err := AddToolContent(context.Background()); !errors.Is(err, ErrNoToolContext) {
+		t.Fatalf("AddToolContent outside a tool err = %v, want ErrNoToolContext", err)
+	}
+}
@@ -2,6 +2,8 @@ package tools
 
 import (
 	"context"
+	"iter"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 )
@@ -30,6 +32,30 @@ func WithOutputSchema(schema *jsonschema.Schema) Option {
 	}
 }
 
+// WithSerial marks the tool as one that must not run concurrently with other
+// tool calls of the same model turn.
+func WithSerial() Option {
+	return func(t *baseTool) {
+		t.serial = true
+	}
+}
+
+// WithTimeout bounds each call of the tool with the given timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(t *baseTool) {
+		t.timeout = timeout
+	}
+}
+
+// WithStream makes the tool a StreamingTool whose HandleStream reports the
+// updates of stream. The middlewares of the tool wrap streaming calls too, so
+// they see the same input and response as with Handle.
+func WithStream(stream func(context.Context, string) iter.Seq2[*Update, error]) Option {
+	return func(t *baseTool) {
+		t.stream = stream
+	}
+}
+
 // baseTool represents a tool with a name, description, input schema, and a tool handler.
 type baseTool struct {
 	name         string
@@ -37,7 +63,10 @@ type baseTool struct {
 	inputSchema  *jsonschema.Schema
 	outputSchema *jsonschema.Schema
 	handler      Handler
+	stream       func(context.Context, string) iter.Seq2[*Update, error]
 	middlewares  []Middleware
+	serial       bool
+	timeout      time.Duration
 }
 
 func (t *baseTool) Name() string {
@@ -56,10 +85,72 @@ func (t *baseTool) OutputSchema() *jsonschema.Schema {
 	return t.outputSchema
 }
 
+func (t *baseTool) Serial() bool {
+	return t.serial
+}
+
+func (t *baseTool) Timeout() time.Duration {
+	return t.timeout
+}
+
 func (t *baseTool) Handle(ctx context.Context, input string) (string, error) {
-	handler := t.handler
+	return t.wrap(t.handler).Handle(ctx, input)
+}
+
+// wrap applies the middlewares of the tool to handler.
+func (t *baseTool) wrap(handler Handler) Handler {
 	if len(t.middlewares) > 0 {
-		handler = ChainMiddlewares(t.middlewares...)(t.handler)
+		return ChainMiddlewares(t.middlewares...)(handler)
+	}
+	return handler
+}
+
+// streamingTool is a baseTool created with WithStream.
+type streamingTool struct {
+	*baseTool
+}
+
+// HandleStream reports the updates of the stream of the tool, which runs
+// inside its middlewares. When the middlewares change the response, a last
+// update carries the changed response.
+func (t *streamingTool) HandleStream(ctx context.Context, input string) iter.Seq2[*Update, error] {
+	return func(yield func(*Update, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var (
+			last    *Update
+			stopped bool
+		)
+		handler := HandleFunc(func(ctx context.Context, input string) (string, error) {
+			for update, err := range t.stream(ctx, input) {
+				if err != nil {
+					return "", err
+				}
+				if update == nil {
+					continue
+				}
+				if !yield(update, nil) {
+					stopped = true
+					cancel()
+					return "", ctx.Err()
+				}
+				last = update
+			}
+			if last == nil {
+				return "", nil
+			}
+			return last.Output, nil
+		})
+		output, err := t.wrap(handler).Handle(ctx, input)
+		if stopped {
+			return
+		}
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if last == nil || last.Output != output {
+			yield(&Update{Output: output, Progress: 1}, nil)
+		}
 	}
-	return handler.Handle(ctx, input)
 }
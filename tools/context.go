@@ -15,6 +15,18 @@ type ToolContext interface {
 	SetAction(key string, value any)
 }
 
+// ArtifactContext is an optional interface of a ToolContext that gives tools
+// access to the artifacts of the session. Check for it with a type assertion.
+type ArtifactContext interface {
+	// SaveArtifact stores data as a new version of the named artifact of the
+	// session and returns that version. It fails when the Runner has no
+	// artifact store.
+	SaveArtifact(ctx context.Context, name string, data []byte, mimeType string) (int, error)
+	// LoadArtifact returns the data and MIME type of a version of the named
+	// artifact of the session; version 0 loads the latest.
+	LoadArtifact(ctx context.Context, name string, version int) ([]byte, string, error)
+}
+
 type ctxToolKey struct{}
 
 // NewContext returns a child context that carries the given ToolContext.
@@ -2,6 +2,8 @@ package tools
 
 import (
 	"context"
+	"iter"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 )
@@ -15,6 +17,42 @@ type Tool interface {
 	Handler
 }
 
+// SerialTool is an optional interface for tools that must not run concurrently
+// with other tool calls, e.g. because their side effects depend on call order.
+type SerialTool interface {
+	Serial() bool
+}
+
+// TimeoutTool is an optional interface for tools that bound each call with their
+// own timeout, overriding the agent-wide tool timeout.
+type TimeoutTool interface {
+	Timeout() time.Duration
+}
+
+// Update is a progress update or partial result reported by a StreamingTool.
+type Update struct {
+	// Output is the result produced so far. The Output of the last update is
+	// the response returned to the model.
+	Output string
+	// Progress is the completed fraction of the work in [0, 1], or 0 if unknown.
+	Progress float64
+	// Message describes the current step, e.g. "fetching page 2 of 5".
+	Message string
+}
+
+// StreamingTool is an optional interface for long-running tools that report
+// progress and partial results while they run. In streaming runs the agent
+// calls HandleStream instead of Handle and forwards every update.
+type StreamingTool interface {
+	HandleStream(ctx context.Context, input string) iter.Seq2[*Update, error]
+}
+
+// IsSerial reports whether the tool must be executed serially.
+func IsSerial(t Tool) bool {
+	s, ok := t.(SerialTool)
+	return ok && s.Serial()
+}
+
 // NewTool creates a new Tool with the given name, description, and handler.
 func NewTool(name string, description string, handler Handler, opts ...Option) Tool {
 	t := &baseTool{
@@ -25,6 +63,9 @@ func NewTool(name string, description string, handler Handler, opts ...Option) T
 	for _, opt := range opts {
 		opt(t)
 	}
+	if t.stream != nil {
+		return &streamingTool{baseTool: t}
+	}
 	return t
 }
 
@@ -48,5 +89,8 @@ func NewFunc[I, O any](name string, description string, handler func(context.Con
 	for _, opt := range opts {
 		opt(t)
 	}
+	if t.stream != nil {
+		return &streamingTool{baseTool: t}, nil
+	}
 	return t, nil
 }
@@ -3,6 +3,8 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"iter"
+	"strings"
 	"testing"
 
 	"github.com/google/jsonschema-go/jsonschema"
@@ -143,3 +145,53 @@ func TestJSONAdapter(t *testing.T) {
 		t.Fatalf("unexpected greet: %s", got.Greet)
 	}
 }
+
+func TestStreamingToolRunsMiddlewares(t *testing.T) {
+	var inputs []string
+	upper := func(next Handler) Handler {
+		return HandleFunc(func(ctx context.Context, input string) (string, error) {
+			inputs = append(inputs, input)
+			output, err := next.Handle(ctx, input)
+			return strings.ToUpper(output), err
+		})
+	}
+	stream := func(ctx context.Context, input string) iter.Seq2[*Update, error] {
+		return func(yield func(*Update, error) bool) {
+			for _, update := range []*Update{{Progress: 0.5}, nil, {Output: "done: " + input, Progress: 1}} {
+				if !yield(update, nil) {
+					return
+				}
+			}
+		}
+	}
+	tool := NewTool("stream_tool", "A streaming tool", HandleFunc(func(ctx context.Context, input string) (string, error) {
+		return "", nil
+	}), WithStream(stream), WithMiddleware(upper))
+
+	streaming, ok := tool.(StreamingTool)
+	if !ok {
+		t.Fatalf("expected a StreamingTool, got %T", tool)
+	}
+	var updates []*Update
+	for update, err := range streaming.HandleStream(context.Background(), "job") {
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if update == nil {
+			t.Fatal("expected no nil updates")
+		}
+		updates = append(updates, update)
+	}
+	if len(inputs) != 1 || inputs[0] != "job" {
+		t.Fatalf("expected the middleware to see one call, got %v", inputs)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("expected 3 updates, got %d", len(updates))
+	}
+	if updates[1].Output != "done: job" {
+		t.Fatalf("unexpected streamed output: %s", updates[1].Output)
+	}
+	if last := updates[2]; last.Output != "DONE: JOB" || last.Progress != 1 {
+		t.Fatalf("unexpected last update: %+v", last)
+	}
+}
@@ -0,0 +1,179 @@
+package blades
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// defaultMaxRepairs is the number of times RunTyped re-prompts the model after
+// an output that does not match the schema.
+const defaultMaxRepairs = 2
+
+// WithMaxRepairs sets how many times RunTyped re-prompts the model with the
+// validation errors of an invalid output. By default, it is set to 2.
+func WithMaxRepairs(n int) RunOption {
+	return func(r *RunOptions) {
+		r.MaxRepairs = &n
+	}
+}
+
+// TypedAgent is an Agent whose final answer is a JSON value of type T.
+type TypedAgent[T any] struct {
+	Agent
+	schema *jsonschema.Schema
+}
+
+// NewTypedAgent creates an Agent whose output schema is derived from T.
+// Use RunTyped to run it and decode the validated output.
+func NewTypedAgent[T any](name string, opts ...AgentOption) (*TypedAgent[T], error) {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := NewAgent(name, append(opts, WithOutputSchema(schema))...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedAgent[T]{Agent: agent, schema: schema}, nil
+}
+
+// OutputSchema returns the JSON schema derived from T.
+func (a *TypedAgent[T]) OutputSchema() *jsonschema.Schema {
+	return a.schema
+}
+
+// RunTyped runs the runner's agent and decodes its final answer into T. The
+// answer is validated against the JSON schema for T; when it does not match,
+// the model is re-prompted up to the limit set by WithMaxRepairs. A repair
+// resends the original message together with the invalid answer and its
+// validation errors, in a temporary fork of the session taken before the
+// original message. Repairs are therefore never written to the session, which
+// keeps the first answer, and without WithSession or WithSessionID that session
+// is temporary too. Temporary sessions are not kept in the SessionStore. It
+// returns an error wrapping ErrInvalidOutput when no valid answer is produced.
+func RunTyped[T any](ctx context.Context, runner *Runner, message *Message, opts ...RunOption) (T, error) {
+	var zero T
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		return zero, err
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return zero, err
+	}
+	o := runner.runOptions(opts...)
+	maxRepairs := defaultMaxRepairs
+	if o.MaxRepairs != nil {
+		maxRepairs = *o.MaxRepairs
+	}
+	// The Runner saves the session after every attempt, but temporary sessions
+	// only exist for this call.
+	var temporary []string
+	if runner.sessionStore != nil {
+		defer func() {
+			for _, id := range temporary {
+				runner.sessionStore.Delete(context.WithoutCancel(ctx), id)
+			}
+		}()
+	}
+	if o.Session == nil && o.SessionID == "" {
+		o.Session = NewSession(runner.sessionOptions...)
+		temporary = append(temporary, o.Session.ID())
+	} else if err := runner.loadSession(ctx, o); err != nil {
+		return zero, err
+	}
+	session := o.Session
+	request := message
+	for attempt := 0; ; attempt++ {
+		output, err := runner.Run(ctx, request, append(opts, WithSession(session))...)
+		if err != nil {
+			return zero, err
+		}
+		value, err := decodeOutput[T](resolved, output)
+		if err == nil {
+			return value, nil
+		}
+		if attempt >= maxRepairs {
+			return zero, err
+		}
+		if session, err = forkBefore(o.Session, message, runner.sessionOptions...); err != nil {
+			return zero, err
+		}
+		temporary = append(temporary, session.ID())
+		request = repairMessage(message, repairPrompt(schema, output.Text(), err))
+	}
+}
+
+// forkBefore returns a copy of session whose history ends before message.
+func forkBefore(session Session, message *Message, opts ...SessionOption) (Session, error) {
+	fork, err := ForkSession(session, "", "", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if message.ID == "" {
+		return fork, nil
+	}
+	if err := TruncateSession(fork, message.ID); err != nil && !errors.Is(err, ErrMessageNotFound) {
+		return nil, err
+	}
+	return fork, nil
+}
+
+// repairMessage returns a user message holding the parts of the original
+// message followed by the repair prompt.
+func repairMessage(message *Message, prompt string) *Message {
+	repair := UserMessage()
+	repair.Parts = append(slices.Clone(message.Parts), TextPart{Text: prompt})
+	return repair
+}
+
+// decodeOutput validates the text of message against the resolved schema and decodes it into T.
+func decodeOutput[T any](resolved *jsonschema.Resolved, message *Message) (T, error) {
+	var (
+		value    T
+		instance any
+		text     = trimCodeFence(message.Text())
+	)
+	if err := json.Unmarshal([]byte(text), &instance); err != nil {
+		return value, fmt.Errorf("%w: %w", ErrInvalidOutput, err)
+	}
+	if err := resolved.Validate(instance); err != nil {
+		return value, fmt.Errorf("%w: %w", ErrInvalidOutput, err)
+	}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return value, fmt.Errorf("%w: %w", ErrInvalidOutput, err)
+	}
+	return value, nil
+}
+
+// trimCodeFence strips a Markdown code fence that models often wrap JSON in.
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:] // drop the language tag
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// repairPrompt asks the model to fix an output that failed validation.
+func repairPrompt(schema *jsonschema.Schema, output string, err error) string {
+	var buf strings.Builder
+	buf.WriteString("Your previous response is not valid JSON for the required schema.\n")
+	fmt.Fprintf(&buf, "Response:\n%s\n", output)
+	fmt.Fprintf(&buf, "Error: %s\n", err)
+	if b, err := json.Marshal(schema); err == nil {
+		fmt.Fprintf(&buf, "Schema:\n%s\n", b)
+	}
+	buf.WriteString("Reply again with only the corrected JSON value.")
+	return buf.String()
+}
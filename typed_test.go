@@ -0,0 +1,175 @@
+package blades
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type typedAnswer struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// typedScriptModel answers with the scripted texts in order and records the last request.
+type typedScriptModel struct {
+	outputs []string
+	calls   int
+	lastReq *ModelRequest
+}
+
+func (m *typedScriptModel) Name() string { return "typed-script" }
+
+func (m *typedScriptModel) Generate(_ context.Context, req *ModelRequest) (*ModelResponse, error) {
+	text := m.outputs[min(m.calls, len(m.outputs)-1)]
+	m.calls++
+	m.lastReq = req
+	return &ModelResponse{Message: AssistantMessage(text)}, nil
+}
+
+func (m *typedScriptModel) NewStreaming(context.Context, *ModelRequest) Generator[*ModelResponse, error] {
+	return nil
+}
+
+func TestRunTypedRepairsInvalidOutput(t *testing.T) {
+	t.Parallel()
+
+	model := &typedScriptModel{outputs: []string{
+		`{"name": 42}`,
+		"```json\n{\"name\": \"blades\", \"score\": 7}\n```",
+	}}
+	agent, err := NewTypedAgent[typedAnswer]("typed", WithModel(model))
+	if err != nil {
+		t.Fatalf("new typed agent: %v", err)
+	}
+	if agent.OutputSchema() == nil {
+		t.Fatalf("expected derived output schema")
+	}
+
+	got, err := RunTyped[typedAnswer](context.Background(), NewRunner(agent), UserMessage("rate blades"))
+	if err != nil {
+		t.Fatalf("run typed: %v", err)
+	}
+	if want := (typedAnswer{Name: "blades", Score: 7}); got != want {
+		t.Fatalf("answer = %+v, want %+v", got, want)
+	}
+	if got, want := model.calls, 2; got != want {
+		t.Fatalf("model calls = %d, want %d", got, want)
+	}
+	if model.lastReq.OutputSchema == nil {
+		t.Fatalf("model request is missing the output schema")
+	}
+	// The repair replaces the original turn: it resends the original message
+	// with a prompt quoting the invalid answer.
+	messages := model.lastReq.Messages
+	if got, want := len(messages), 1; got != want {
+		t.Fatalf("repair request messages = %d, want %d", got, want)
+	}
+	repair := messages[0].Text()
+	if !strings.HasPrefix(repair, "rate blades") {
+		t.Fatalf("repair request does not resend the original message: %q", repair)
+	}
+	if !strings.Contains(repair, `{"name": 42}`) {
+		t.Fatalf("repair prompt does not quote the invalid output: %q", repair)
+	}
+}
+
+func TestRunTypedRepairsWithoutContext(t *testing.T) {
+	t.Parallel()
+
+	model := &typedScriptModel{outputs: []string{`{"name": 42}`, `{"name": "blades", "score": 7}`}}
+	agent, err := NewTypedAgent[typedAnswer]("typed", WithModel(model), WithContext(false))
+	if err != nil {
+		t.Fatalf("new typed agent: %v", err)
+	}
+	if _, err := RunTyped[typedAnswer](context.Background(), NewRunner(agent), UserMessage("rate blades")); err != nil {
+		t.Fatalf("run typed: %v", err)
+	}
+	messages := model.lastReq.Messages
+	if got, want := len(messages), 1; got != want {
+		t.Fatalf("repair request messages = %d, want %d", got, want)
+	}
+	if repair := messages[0].Text(); !strings.HasPrefix(repair, "rate blades") || !strings.Contains(repair, `{"name": 42}`) {
+		t.Fatalf("repair request misses the original message or the invalid output: %q", repair)
+	}
+}
+
+func TestRunTypedDoesNotWriteRepairsToSession(t *testing.T) {
+	t.Parallel()
+
+	model := &typedScriptModel{outputs: []string{`{"name": 42}`, `{"name": "blades", "score": 7}`}}
+	agent, err := NewTypedAgent[typedAnswer]("typed", WithModel(model))
+	if err != nil {
+		t.Fatalf("new typed agent: %v", err)
+	}
+	session := NewSession()
+	earlier := AssistantMessage("earlier answer")
+	if err := session.Append(context.Background(), earlier); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := RunTyped[typedAnswer](context.Background(), NewRunner(agent), UserMessage("rate blades"), WithSession(session)); err != nil {
+		t.Fatalf("run typed: %v", err)
+	}
+	// The repair sees the earlier history but not the replaced turn.
+	messages := model.lastReq.Messages
+	if got, want := len(messages), 2; got != want {
+		t.Fatalf("repair request messages = %d, want %d", got, want)
+	}
+	if messages[0].ID != earlier.ID {
+		t.Fatalf("repair request starts with %q, want the earlier history", messages[0].Text())
+	}
+	// The session keeps the original turn only.
+	history, err := session.History(context.Background())
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if got, want := len(history), 3; got != want {
+		t.Fatalf("session history = %d messages, want %d", got, want)
+	}
+	if got := history[1].Text(); got != "rate blades" {
+		t.Fatalf("session history[1] = %q, want the original message", got)
+	}
+	if got := history[2].Text(); got != `{"name": 42}` {
+		t.Fatalf("session history[2] = %q, want the first answer", got)
+	}
+}
+
+func TestRunTypedDoesNotKeepSessions(t *testing.T) {
+	t.Parallel()
+
+	model := &typedScriptModel{outputs: []string{`{"name": 42}`, `{"name": "blades", "score": 7}`}}
+	agent, err := NewTypedAgent[typedAnswer]("typed", WithModel(model))
+	if err != nil {
+		t.Fatalf("new typed agent: %v", err)
+	}
+	store := NewInMemorySessionStore()
+	if _, err := RunTyped[typedAnswer](context.Background(), NewRunner(agent, WithSessionStore(store)), UserMessage("rate blades")); err != nil {
+		t.Fatalf("run typed: %v", err)
+	}
+	ids, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("stored sessions = %v, want none", ids)
+	}
+}
+
+func TestRunTypedGivesUpAfterMaxRepairs(t *testing.T) {
+	t.Parallel()
+
+	model := &typedScriptModel{outputs: []string{"not json"}}
+	agent, err := NewTypedAgent[typedAnswer]("typed", WithModel(model))
+	if err != nil {
+		t.Fatalf("new typed agent: %v", err)
+	}
+
+	_, err = RunTyped[typedAnswer](context.Background(), NewRunner(agent), UserMessage("rate blades"), WithMaxRepairs(1))
+	if !errors.Is(err, ErrInvalidOutput) {
+		t.Fatalf("run typed error = %v, want ErrInvalidOutput", err)
+	}
+	if got, want := model.calls, 2; got != want {
+		t.Fatalf("model calls = %d, want %d", got, want)
+	}
+}